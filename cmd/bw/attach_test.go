@@ -115,12 +115,12 @@ func TestCmdAttachMissingFile(t *testing.T) {
 // diverged remote so `bw sync` falls back to conflict replay, and
 // verify the attachment bytes survive untouched at their original path.
 //
-// The conflict is forced by mutating the same issue JSON on both sides
-// (local adds an assignee; remote moves to in_progress). That mutation
-// makes MergeCommit return false, the local ref is Reset to the remote
-// tip, and Replay runs. During Replay the "attach" intent line must
-// recover the blob from the ODB via Store.SourceHash — the very path
-// exercised by this test.
+// The conflict is forced by setting the same field of the same issue
+// to different values on both sides (local and remote each assign it to
+// a different agent). That mutation makes MergeCommit return false, the
+// local ref is Reset to the remote tip, and Replay runs. During Replay the
+// "attach" intent line must recover the blob from the ODB via
+// Store.SourceHash — the very path exercised by this test.
 func TestAttachSurvivesSyncConflictReplay(t *testing.T) {
 	env := testutil.NewEnv(t)
 	defer env.Cleanup()
@@ -137,9 +137,9 @@ func TestAttachSurvivesSyncConflictReplay(t *testing.T) {
 	defer env2.Cleanup()
 
 	env2.SwitchTo()
-	statusIP := "in_progress"
-	env2.Store.Update(shared.ID, issue.UpdateOpts{Status: &statusIP})
-	env2.Repo.Commit("update " + shared.ID + " status=in_progress")
+	remoteAssignee := "agent-7"
+	env2.Store.Update(shared.ID, issue.UpdateOpts{Assignee: &remoteAssignee})
+	env2.Repo.Commit("update " + shared.ID + " assignee=agent-7")
 	env2.Repo.Sync(nil)
 
 	// Local side: same issue gets a different assignee, AND we attach
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/jallum/beadwork/internal/config"
+
+	"github.com/jallum/beadwork/internal/intent"
+	"github.com/jallum/beadwork/internal/issue"
+)
+
+// batchStdin is read by bw batch -; tests replace it.
+var batchStdin io.Reader = os.Stdin
+
+// batchCommands are the commands a batch script may run.
+var batchCommands = []string{"create", "update", "close", "reopen", "label", "comment", "dep", "defer", "undefer"}
+
+var batchRefRe = regexp.MustCompile(`^\$([A-Za-z_][A-Za-z0-9_-]*)$`)
+
+type BatchArgs struct {
+	File string // "-" reads stdin
+	JSON bool
+}
+
+func parseBatchArgs(raw []string) (BatchArgs, error) {
+	a, err := ParseArgs(raw, nil, []string{"--json"})
+	if err != nil {
+		return BatchArgs{}, err
+	}
+	pos := a.Pos()
+	if len(pos) != 1 {
+		return BatchArgs{}, fmt.Errorf("usage: bw batch <file|->")
+	}
+	return BatchArgs{File: pos[0], JSON: a.JSON()}, nil
+}
+
+// batchOp is one operation of a batch script.
+type batchOp struct {
+	Line int      // 1-based line in the script
+	As   string   // reference bound to the issue a create makes, without "$"
+	Args []string // command name first; "$name" arguments are references
+}
+
+// batchResult is what bw batch --json prints.
+type batchResult struct {
+	Refs    map[string]string `json:"refs"`
+	Intents []string          `json:"intents"`
+}
+
+func cmdBatch(store *issue.Store, args []string, w Writer, _ *config.Config) (*config.Config, error) {
+	ba, err := parseBatchArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	var data []byte
+	if ba.File == "-" {
+		data, err = io.ReadAll(batchStdin)
+	} else {
+		data, err = os.ReadFile(ba.File)
+	}
+	if err != nil {
+		return nil, err
+	}
+	ops, err := parseBatchScript(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(ops) == 0 {
+		return nil, fmt.Errorf("batch is empty")
+	}
+
+	var refs map[string]string
+	var intents []string
+	var out bytes.Buffer
+	err = commitWithRetry(store, commitMaxRetries, func() (string, error) {
+		out.Reset()
+		var berr error
+		refs, intents, berr = runBatch(store, ops, withStatusIcons(PlainWriter(&out), store.CurrentWorkflow()))
+		return strings.Join(intents, "\n"), berr
+	})
+	if err != nil {
+		// Drop whatever the operations before the failure staged.
+		store.Refresh()
+		return nil, err
+	}
+
+	if ba.JSON {
+		fprintJSON(w, batchResult{Refs: refs, Intents: intents})
+		return nil, nil
+	}
+	io.Copy(w, &out)
+	fmt.Fprintf(w, "batch: %d operations in one commit\n", len(ops))
+	return nil, nil
+}
+
+// runBatch runs ops in order with their commits collected, returning the
+// reference bindings and the intents to commit as one. The first failing
+// operation aborts the batch.
+func runBatch(store *issue.Store, ops []batchOp, w Writer) (map[string]string, []string, error) {
+	refs := make(map[string]string)
+	store.StartBatch()
+	defer store.EndBatch()
+	for _, op := range ops {
+		args := make([]string, len(op.Args))
+		for i, a := range op.Args {
+			m := batchRefRe.FindStringSubmatch(a)
+			if m == nil {
+				args[i] = a
+				continue
+			}
+			id, ok := refs[m[1]]
+			if !ok {
+				return nil, nil, fmt.Errorf("line %d: unknown reference %s", op.Line, a)
+			}
+			args[i] = id
+		}
+
+		before := len(store.Batched())
+		c := commandMap[args[0]]
+		if _, err := c.Run(store, args[1:], w, nil); err != nil {
+			return nil, nil, fmt.Errorf("line %d: %s: %w", op.Line, args[0], err)
+		}
+		if op.As != "" {
+			// The first intent line the create committed names its issue.
+			batched := store.Batched()
+			if len(batched) == before {
+				return nil, nil, fmt.Errorf("line %d: create made no issue", op.Line)
+			}
+			parts := intent.ParseIntent(strings.SplitN(batched[before], "\n", 2)[0])
+			refs[op.As] = parts[1]
+		}
+	}
+	var intents []string
+	for _, in := range store.Batched() {
+		if in != "" {
+			intents = append(intents, in)
+		}
+	}
+	return refs, intents, nil
+}
+
+// parseBatchScript reads a batch script. Each non-blank line that does
+// not start with # is one operation, either a command line
+//
+//	$epic = create "Search" --type epic
+//	dep add $epic blocks bw-a1b2
+//
+// or a JSON object with the arguments, command name first, and an
+// optional reference to bind:
+//
+//	{"as": "epic", "args": ["create", "Search", "--type", "epic"]}
+func parseBatchScript(data []byte) ([]batchOp, error) {
+	var ops []batchOp
+	bound := make(map[string]bool)
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		op := batchOp{Line: i + 1}
+		if strings.HasPrefix(line, "{") {
+			var j struct {
+				As   string   `json:"as"`
+				Args []string `json:"args"`
+			}
+			dec := json.NewDecoder(strings.NewReader(line))
+			dec.DisallowUnknownFields()
+			if err := dec.Decode(&j); err != nil {
+				return nil, fmt.Errorf("line %d: %w", op.Line, err)
+			}
+			op.As = strings.TrimPrefix(j.As, "$")
+			op.Args = j.Args
+		} else {
+			args, err := splitScriptLine(line)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", op.Line, err)
+			}
+			if len(args) >= 2 && args[1] == "=" {
+				m := batchRefRe.FindStringSubmatch(args[0])
+				if m == nil {
+					return nil, fmt.Errorf("line %d: invalid reference %q", op.Line, args[0])
+				}
+				op.As = m[1]
+				args = args[2:]
+			}
+			op.Args = args
+		}
+
+		if len(op.Args) == 0 {
+			return nil, fmt.Errorf("line %d: no command", op.Line)
+		}
+		if !slices.Contains(batchCommands, op.Args[0]) {
+			return nil, fmt.Errorf("line %d: bw %s cannot run in a batch (use %s)", op.Line, op.Args[0], strings.Join(batchCommands, ", "))
+		}
+		if op.As != "" {
+			if op.Args[0] != "create" {
+				return nil, fmt.Errorf("line %d: only create can bind a reference", op.Line)
+			}
+			if !batchRefRe.MatchString("$" + op.As) {
+				return nil, fmt.Errorf("line %d: invalid reference %q", op.Line, op.As)
+			}
+			if bound[op.As] {
+				return nil, fmt.Errorf("line %d: $%s is already bound", op.Line, op.As)
+			}
+			bound[op.As] = true
+		}
+		ops = append(ops, op)
+	}
+	return ops, nil
+}
+
+// splitScriptLine splits a command line into arguments the way a shell
+// would for simple cases: whitespace separates arguments, single quotes
+// are literal, and double quotes allow backslash escapes.
+func splitScriptLine(line string) ([]string, error) {
+	var args []string
+	var cur strings.Builder
+	inArg := false
+	for i := 0; i < len(line); i++ {
+		ch := line[i]
+		switch {
+		case ch == ' ' || ch == '\t':
+			if inArg {
+				args = append(args, cur.String())
+				cur.Reset()
+				inArg = false
+			}
+		case ch == '\'':
+			end := strings.IndexByte(line[i+1:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated quote")
+			}
+			cur.WriteString(line[i+1 : i+1+end])
+			i += end + 1
+			inArg = true
+		case ch == '"':
+			i++
+			for ; i < len(line) && line[i] != '"'; i++ {
+				if line[i] == '\\' && i+1 < len(line) {
+					i++
+				}
+				cur.WriteByte(line[i])
+			}
+			if i >= len(line) {
+				return nil, fmt.Errorf("unterminated quote")
+			}
+			inArg = true
+		case ch == '\\' && i+1 < len(line):
+			i++
+			cur.WriteByte(line[i])
+			inArg = true
+		default:
+			cur.WriteByte(ch)
+			inArg = true
+		}
+	}
+	if inArg {
+		args = append(args, cur.String())
+	}
+	return args, nil
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jallum/beadwork/internal/intent"
+	"github.com/jallum/beadwork/internal/issue"
+	"github.com/jallum/beadwork/internal/testutil"
+)
+
+func runTestBatch(t *testing.T, store *issue.Store, script string, extra ...string) (string, error) {
+	t.Helper()
+	orig := batchStdin
+	batchStdin = strings.NewReader(script)
+	t.Cleanup(func() { batchStdin = orig })
+	var buf bytes.Buffer
+	_, err := cmdBatch(store, append([]string{"-"}, extra...), PlainWriter(&buf), nil)
+	return buf.String(), err
+}
+
+func TestCmdBatch(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	existing, _ := env.Store.Create("Existing", issue.CreateOpts{})
+	env.Repo.Commit("create " + existing.ID)
+	before, _ := env.Repo.AllCommits()
+
+	script := `# plan
+$epic = create "Search" --type epic -p 1
+$index = create "Build index" --parent $epic
+{"as": "ui", "args": ["create", "Search box", "--parent", "$epic"]}
+
+dep add $index blocks $ui
+label $ui +frontend
+update ` + existing.ID + ` --parent $epic
+`
+	out, err := runTestBatch(t, env.Store, script, "--json")
+	if err != nil {
+		t.Fatalf("cmdBatch: %v", err)
+	}
+	var result batchResult
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("JSON parse: %v\n%s", err, out)
+	}
+	epic, index, ui := result.Refs["epic"], result.Refs["index"], result.Refs["ui"]
+	if epic == "" || index == "" || ui == "" {
+		t.Fatalf("refs = %v", result.Refs)
+	}
+
+	after, _ := env.Repo.AllCommits()
+	if len(after) != len(before)+1 {
+		t.Fatalf("batch made %d commits, want 1", len(after)-len(before))
+	}
+	msg := after[0].Message
+	if lines := strings.Split(msg, "\n"); len(lines) != 6 {
+		t.Errorf("intent has %d lines, want 6:\n%s", len(lines), msg)
+	}
+	if !reflect.DeepEqual(strings.Split(msg, "\n"), result.Intents) {
+		t.Errorf("intents = %q, commit = %q", result.Intents, msg)
+	}
+
+	got, _ := env.Store.Get(ui)
+	if got.Parent != epic || len(got.BlockedBy) != 1 || got.BlockedBy[0] != index || got.Labels[0] != "frontend" {
+		t.Errorf("ui = parent %s blocked_by %v labels %v", got.Parent, got.BlockedBy, got.Labels)
+	}
+	if got, _ := env.Store.Get(existing.ID); got.Parent != epic {
+		t.Errorf("existing parent = %q, want %s", got.Parent, epic)
+	}
+
+	// The commit replays line by line into the same state.
+	fresh := testutil.NewEnv(t)
+	defer fresh.Cleanup()
+	fresh.Store.Create("Existing", issue.CreateOpts{ID: existing.ID})
+	fresh.Repo.Commit("create " + existing.ID)
+	if errs := intent.Replay(fresh.Store, []string{msg}); len(errs) > 0 {
+		t.Fatalf("Replay: %v", errs)
+	}
+	replayed, _ := fresh.Store.Get(ui)
+	if replayed == nil || replayed.Parent != epic || len(replayed.BlockedBy) != 1 || len(replayed.Labels) != 1 {
+		t.Errorf("replayed ui = %+v", replayed)
+	}
+}
+
+func TestCmdBatchTextOutput(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	out, err := runTestBatch(t, env.Store, "$a = create 'First one'\ncomment $a \"noted\"\n")
+	if err != nil {
+		t.Fatalf("cmdBatch: %v", err)
+	}
+	if !strings.Contains(out, "created") || !strings.Contains(out, "First one") {
+		t.Errorf("output = %q, want each operation's output", out)
+	}
+	if !strings.Contains(out, "batch: 2 operations in one commit") {
+		t.Errorf("output = %q, want summary", out)
+	}
+}
+
+func TestCmdBatchAbortsOnFailure(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+	before, _ := env.Repo.AllCommits()
+
+	_, err := runTestBatch(t, env.Store, "$a = create \"Kept?\"\nupdate bw-nope --priority 1\n")
+	if err == nil || !strings.Contains(err.Error(), "line 2: update") {
+		t.Fatalf("err = %v, want line 2 failure", err)
+	}
+	after, _ := env.Repo.AllCommits()
+	if len(after) != len(before) {
+		t.Errorf("failed batch committed")
+	}
+	if issues, _ := env.Store.List(issue.Filter{}); len(issues) != 0 {
+		t.Errorf("failed batch left %d staged issues", len(issues))
+	}
+
+	// The store is still usable for the next command.
+	var buf bytes.Buffer
+	if _, err := cmdCreate(env.Store, []string{"After"}, PlainWriter(&buf), nil); err != nil {
+		t.Fatalf("create after failed batch: %v", err)
+	}
+	after, _ = env.Repo.AllCommits()
+	if !strings.HasPrefix(after[0].Message, "create ") || strings.Contains(after[0].Message, "Kept?") {
+		t.Errorf("next commit = %q", after[0].Message)
+	}
+}
+
+func TestParseBatchScriptErrors(t *testing.T) {
+	tests := []struct {
+		script string
+		want   string
+	}{
+		{"sync", "line 1: bw sync cannot run in a batch"},
+		{"create \"x", "line 1: unterminated quote"},
+		{"$a = update bw-1 -p 1", "only create can bind"},
+		{"$a = create x\n$a = create y", "line 2: $a is already bound"},
+		{"a = create x", `invalid reference "a"`},
+		{`{"args": ["create", "x"], "bogus": 1}`, "unknown field"},
+		{`{"as": "a"}`, "no command"},
+	}
+	for _, tt := range tests {
+		_, err := parseBatchScript([]byte(tt.script))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("parseBatchScript(%q): err = %v, want %q", tt.script, err, tt.want)
+		}
+	}
+}
+
+func TestCmdBatchUnknownReference(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	_, err := runTestBatch(t, env.Store, "create x --parent $missing\n")
+	if err == nil || !strings.Contains(err.Error(), "unknown reference $missing") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestSplitScriptLine(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{`create "Fix the bug" -p 1`, []string{"create", "Fix the bug", "-p", "1"}},
+		{`comment bw-1 'it''s "fine"'`, []string{"comment", "bw-1", `its "fine"`}},
+		{`comment bw-1 "say \"hi\""`, []string{"comment", "bw-1", `say "hi"`}},
+		{`update  bw-1   --title a\ b`, []string{"update", "bw-1", "--title", "a b"}},
+		{`update bw-1 --assignee ""`, []string{"update", "bw-1", "--assignee", ""}},
+	}
+	for _, tt := range tests {
+		got, err := splitScriptLine(tt.line)
+		if err != nil {
+			t.Errorf("splitScriptLine(%q): %v", tt.line, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitScriptLine(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
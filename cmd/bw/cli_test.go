@@ -7,7 +7,9 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"syscall"
 	"testing"
+	"time"
 
 	"github.com/jallum/beadwork/internal/issue"
 	"github.com/jallum/beadwork/internal/testutil"
@@ -810,6 +812,59 @@ func TestDryRunReadOnly(t *testing.T) {
 	assertContains(t, out, "Listed task")
 }
 
+// --- Point-in-time (--at) ---
+
+func TestAtListShowsPastBoard(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	iss, _ := env.Store.Create("Before", issue.CreateOpts{})
+	env.CommitIntent("create " + iss.ID)
+	before := env.Repo.TreeFS().RefHash().String()
+
+	env.Store.Close(iss.ID, "")
+	later, _ := env.Store.Create("After", issue.CreateOpts{})
+	env.CommitIntent("close " + iss.ID + "\ncreate " + later.ID)
+
+	out := bw(t, env.Dir, "list", "--at", before[:8])
+	assertContains(t, out, "Before")
+	assertNotContains(t, out, "After")
+
+	show := bw(t, env.Dir, "show", iss.ID, "--json", "--at", before)
+	assertContains(t, show, `"status": "open"`)
+
+	ready := bw(t, env.Dir, "ready", "--at", before)
+	assertContains(t, ready, "Before")
+
+	// Without --at the current board is unchanged.
+	now := bw(t, env.Dir, "list")
+	assertContains(t, now, "After")
+	assertNotContains(t, now, "Before")
+}
+
+func TestAtRefusesMutatingCommands(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	iss, _ := env.Store.Create("Frozen", issue.CreateOpts{})
+	env.CommitIntent("create " + iss.ID)
+	head := env.Repo.TreeFS().RefHash().String()
+
+	out := bwFail(t, env.Dir, "close", iss.ID, "--at", head)
+	assertContains(t, out, "cannot be used with --at")
+
+	show := bw(t, env.Dir, "show", iss.ID, "--json")
+	assertContains(t, show, `"status": "open"`)
+}
+
+func TestAtUnknownRevision(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	out := bwFail(t, env.Dir, "list", "--at", "someday")
+	assertContains(t, out, "--at someday")
+}
+
 // --- Close/Reopen ---
 
 func TestCloseWithReason(t *testing.T) {
@@ -1604,3 +1659,316 @@ func init() {
 	os.Setenv("GIT_COMMITTER_NAME", "Test")
 	os.Setenv("GIT_COMMITTER_EMAIL", "test@test.com")
 }
+
+func TestCustomFieldsEndToEnd(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	bw(t, env.Dir, "config", "set", "field.severity", "enum:low,medium,high")
+	bw(t, env.Dir, "config", "set", "field.estimate", "int")
+
+	id := strings.TrimSpace(bw(t, env.Dir, "create", "Crash on login", "--field", "severity=high", "--field", "estimate=03", "--silent"))
+	other := strings.TrimSpace(bw(t, env.Dir, "create", "Typo", "--field", "severity=low", "--silent"))
+
+	show := bw(t, env.Dir, "show", id)
+	assertContains(t, show, "Fields: estimate=3, severity=high")
+
+	out := bwFail(t, env.Dir, "update", id, "--field", "severity=urgent")
+	assertContains(t, out, `"urgent" is not one of low, medium, high`)
+
+	list := bw(t, env.Dir, "list", "--where", "severity=high")
+	assertContains(t, list, id)
+	assertNotContains(t, list, other)
+
+	bw(t, env.Dir, "update", id, "--field", "severity=medium")
+	hist := bw(t, env.Dir, "history", id)
+	assertContains(t, hist, `field.severity="medium"`)
+
+	bw(t, env.Dir, "undo")
+	show = bw(t, env.Dir, "show", id, "--json")
+	assertContains(t, show, `"severity": "high"`)
+}
+
+func TestDepDuplicatesCloseAndUndo(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	canon, _ := env.Store.Create("Login broken", issue.CreateOpts{})
+	dup, _ := env.Store.Create("Cannot log in", issue.CreateOpts{})
+	env.CommitIntent("setup")
+
+	out := bw(t, env.Dir, "dep", "add", dup.ID, "duplicates", canon.ID, "--close")
+	assertContains(t, out, "closed "+dup.ID)
+
+	show := bw(t, env.Dir, "show", canon.ID)
+	assertContains(t, show, "DUPLICATED BY")
+	assertContains(t, show, "Cannot log in")
+
+	bw(t, env.Dir, "undo")
+	show = bw(t, env.Dir, "show", dup.ID, "--json")
+	assertContains(t, show, `"status": "open"`)
+	assertNotContains(t, show, `"duplicates"`)
+}
+
+func TestQueryEndToEnd(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	mine := strings.TrimSpace(bw(t, env.Dir, "create", "Fix login", "--type", "bug", "-p", "1", "--labels", "auth", "--silent"))
+	other := strings.TrimSpace(bw(t, env.Dir, "create", "Write docs", "--type", "task", "--silent"))
+	bw(t, env.Dir, "start", mine) // assigns to the current git user
+
+	list := bw(t, env.Dir, "list", "-q", "assignee:me priority<=1 label:auth (type:bug OR type:task)")
+	assertContains(t, list, mine)
+	assertNotContains(t, list, other)
+
+	list = bw(t, env.Dir, "list", "-q", "-assignee:me")
+	assertContains(t, list, other)
+	assertNotContains(t, list, mine)
+
+	out := bwFail(t, env.Dir, "list", "-q", "type:bug OR (label:auth")
+	assertContains(t, out, "unclosed parenthesis at column 13")
+}
+
+func TestViewsEndToEnd(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	bug := strings.TrimSpace(bw(t, env.Dir, "create", "Crash", "--type", "bug", "--silent"))
+	bw(t, env.Dir, "create", "Chore", "--type", "task", "--silent")
+	bw(t, env.Dir, "view", "save", "bugs", "-q", "type:bug")
+
+	list := bw(t, env.Dir, "list", "@bugs")
+	assertContains(t, list, bug)
+	assertNotContains(t, list, "Chore")
+
+	hist := bw(t, env.Dir, "view", "list")
+	assertContains(t, hist, "@bugs")
+
+	bw(t, env.Dir, "undo")
+	out := bwFail(t, env.Dir, "view", "run", "bugs")
+	assertContains(t, out, "no such view: bugs")
+}
+
+func TestLocalIndexEndToEnd(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	a := strings.TrimSpace(bw(t, env.Dir, "create", "Alpha", "--silent"))
+	b := strings.TrimSpace(bw(t, env.Dir, "create", "Beta", "--silent"))
+	bw(t, env.Dir, "dep", "add", a, "blocks", b)
+	plain := bw(t, env.Dir, "ready", "--no-context")
+
+	cmd := exec.Command("git", "config", "beadwork.index", "true")
+	cmd.Dir = env.Dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git config: %s: %v", out, err)
+	}
+	if got := bw(t, env.Dir, "ready", "--no-context"); got != plain {
+		t.Errorf("ready with index = %q, want %q", got, plain)
+	}
+	index := filepath.Join(env.Dir, ".git", "beadwork", "index.json")
+	if _, err := os.Stat(index); err != nil {
+		t.Fatalf("index not written: %v", err)
+	}
+
+	bw(t, env.Dir, "close", a)
+	ready := bw(t, env.Dir, "ready", "--no-context")
+	assertContains(t, ready, b)
+	assertNotContains(t, ready, a)
+
+	os.WriteFile(index, []byte("{garbage"), 0644)
+	list := bw(t, env.Dir, "list", "--all")
+	assertContains(t, list, a)
+	assertContains(t, list, b)
+}
+
+func TestFsckRepairEndToEnd(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	a := strings.TrimSpace(bw(t, env.Dir, "create", "A", "--silent"))
+	b := strings.TrimSpace(bw(t, env.Dir, "create", "B", "--silent"))
+	tfs := env.Repo.TreeFS()
+	tfs.Refresh()
+	tfs.WriteFile("blocks/"+a+"/"+b, []byte{})
+	env.CommitIntent("manual marker")
+
+	out := bwFail(t, env.Dir, "fsck")
+	assertContains(t, out, "issues/"+b+".json: blocked_by is missing "+a)
+
+	out = bw(t, env.Dir, "fsck", "--repair")
+	assertContains(t, out, "repaired 2 problem(s)")
+	assertContains(t, bw(t, env.Dir, "blocked"), b)
+	assertContains(t, bw(t, env.Dir, "fsck"), "no problems found")
+	assertContains(t, bwFail(t, env.Dir, "undo"), "repair has no inverse")
+}
+
+func TestLeaseEndToEnd(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+	t.Setenv("CLAUDECODE", "1")
+	t.Setenv("BW_CLOCK", "2027-03-01T10:00:00Z")
+
+	id := strings.TrimSpace(bw(t, env.Dir, "create", "Leased work", "--silent"))
+	bw(t, env.Dir, "start", id, "--assignee", "alice", "--lease", "1h")
+	out := bw(t, env.Dir, "show", id)
+	assertContains(t, out, "Lease: alice (claude-code) until 2027-03-01 at 11:00 AM")
+	assertNotContains(t, bw(t, env.Dir, "ready"), id)
+
+	t.Setenv("BW_CLOCK", "2027-03-01T10:50:00Z")
+	assertContains(t, bw(t, env.Dir, "heartbeat", id, "--assignee", "alice"), "until 2027-03-01T11:50:00Z")
+
+	// The session dies: once the lease runs out the issue is ready again.
+	t.Setenv("BW_CLOCK", "2027-03-01T12:00:00Z")
+	out = bw(t, env.Dir, "ready")
+	assertContains(t, out, id)
+	assertContains(t, out, "reclaimable")
+
+	bw(t, env.Dir, "start", id, "--assignee", "bob", "--lease", "30m")
+	out = bwFail(t, env.Dir, "start", id, "--assignee", "carol")
+	assertContains(t, out, "claimed by bob")
+	assertContains(t, out, "--steal")
+	bw(t, env.Dir, "start", id, "--assignee", "carol", "--steal")
+
+	out = bw(t, env.Dir, "show", id, "--json")
+	assertContains(t, out, `"assignee": "carol"`)
+	assertNotContains(t, out, `"lease"`)
+}
+
+func TestServeEndToEnd(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+	sock := filepath.Join(t.TempDir(), "bw.sock")
+
+	daemon := exec.Command(bwBin, "serve", "--socket", sock)
+	daemon.Dir = env.Dir
+	daemon.Env = bwTestEnv(t, env.Dir)
+	if err := daemon.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer daemon.Process.Kill()
+	for i := 0; i < 100; i++ {
+		if _, err := os.Stat(sock); err == nil {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	// Written without the daemon, then read through it.
+	id := strings.TrimSpace(bw(t, env.Dir, "create", "Written directly", "--silent"))
+	t.Setenv("BW_SOCKET", sock)
+	assertContains(t, bw(t, env.Dir, "show", id), "Written directly")
+	other := strings.TrimSpace(bw(t, env.Dir, "create", "Written via daemon", "--silent"))
+	assertContains(t, bwFail(t, env.Dir, "show", "test-nope"), "test-nope")
+
+	t.Setenv("BW_SOCKET", "")
+	assertContains(t, bw(t, env.Dir, "list"), other)
+
+	daemon.Process.Signal(syscall.SIGTERM)
+	if err := daemon.Wait(); err != nil {
+		t.Errorf("daemon exit: %v", err)
+	}
+	if _, err := os.Stat(sock); !os.IsNotExist(err) {
+		t.Error("socket not removed on shutdown")
+	}
+}
+
+func TestWatchEndToEnd(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	watch := exec.Command(bwBin, "watch", "--json", "--interval", "20ms")
+	watch.Dir = env.Dir
+	watch.Env = bwTestEnv(t, env.Dir)
+	stdout, _ := watch.StdoutPipe()
+	if err := watch.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer watch.Process.Kill()
+	time.Sleep(200 * time.Millisecond) // let it record the starting ref
+
+	id := strings.TrimSpace(bw(t, env.Dir, "create", "Watched", "--silent"))
+	bw(t, env.Dir, "close", id)
+
+	dec := json.NewDecoder(stdout)
+	for _, want := range []string{"created", "closed"} {
+		var ev struct{ Type, ID string }
+		if err := dec.Decode(&ev); err != nil {
+			t.Fatalf("reading %s event: %v", want, err)
+		}
+		if ev.Type != want || ev.ID != id {
+			t.Errorf("event = %+v, want %s %s", ev, want, id)
+		}
+	}
+
+	watch.Process.Signal(syscall.SIGTERM)
+	if err := watch.Wait(); err != nil {
+		t.Errorf("watch exit: %v", err)
+	}
+}
+
+func TestHooksEndToEnd(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+	scripts := t.TempDir()
+	closed := filepath.Join(scripts, "closed")
+	write := func(name, body string) string {
+		path := filepath.Join(scripts, name)
+		os.WriteFile(path, []byte("#!/bin/sh\n"+body+"\n"), 0755)
+		return path
+	}
+	check := write("check", `
+issue=$(cat)
+case "$BW_INTENT" in create*) ;; *) exit 0 ;; esac
+if echo "$issue" | grep -q '"type": "bug"' && ! echo "$issue" | grep -q '"description": "[^"]'; then
+  echo "bugs must have a description" >&2
+  exit 1
+fi`)
+	notify := write("notify", `cat > `+closed)
+	for name, path := range map[string]string{"pre-commit-intent": check, "post-close": notify} {
+		if out, err := exec.Command("git", "-C", env.Dir, "config", "beadwork.hooks."+name, path).CombinedOutput(); err != nil {
+			t.Fatalf("git config: %s", out)
+		}
+	}
+
+	out := bwFail(t, env.Dir, "create", "Crash on save", "--type", "bug")
+	assertContains(t, out, "bugs must have a description")
+	assertNotContains(t, bw(t, env.Dir, "list"), "Crash on save")
+
+	id := strings.TrimSpace(bw(t, env.Dir, "create", "Crash on save", "--type", "bug", "-d", "Steps: save twice", "--silent"))
+	bw(t, env.Dir, "close", id)
+	data, _ := os.ReadFile(closed)
+	assertContains(t, string(data), `"status": "closed"`)
+	assertContains(t, string(data), id)
+}
+
+func TestHooksFireForEveryIntentLine(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+	scripts := t.TempDir()
+	log := filepath.Join(scripts, "log")
+	notify := filepath.Join(scripts, "notify")
+	os.WriteFile(notify, []byte("#!/bin/sh\necho \"$BW_HOOK $BW_ISSUE_ID\" >> "+log+"\n"), 0755)
+	if out, err := exec.Command("git", "-C", env.Dir, "config", "beadwork.hooks.post-close", notify).CombinedOutput(); err != nil {
+		t.Fatalf("git config: %s", out)
+	}
+
+	epic := strings.TrimSpace(bw(t, env.Dir, "create", "Epic", "--type", "epic", "--silent"))
+	child := strings.TrimSpace(bw(t, env.Dir, "create", "Child", "--parent", epic, "--silent"))
+	bw(t, env.Dir, "close", "-r", epic)
+	data, _ := os.ReadFile(log)
+	assertContains(t, string(data), "post-close "+child+"\n")
+	assertContains(t, string(data), "post-close "+epic+"\n")
+
+	os.Remove(log)
+	a := strings.TrimSpace(bw(t, env.Dir, "create", "A", "--silent"))
+	b := strings.TrimSpace(bw(t, env.Dir, "create", "B", "--silent"))
+	script := filepath.Join(scripts, "plan.bw")
+	os.WriteFile(script, []byte("close "+a+"\nclose "+b+"\n"), 0644)
+	bw(t, env.Dir, "batch", script)
+	data, _ = os.ReadFile(log)
+	if string(data) != "post-close "+a+"\npost-close "+b+"\n" {
+		t.Errorf("batch hook log = %q", data)
+	}
+}
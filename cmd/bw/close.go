@@ -44,7 +44,7 @@ func cmdClose(store *issue.Store, args []string, w Writer, _ *config.Config) (*c
 	}
 
 	var iss *issue.Issue
-	var unblocked []*issue.Issue
+	var unblocked, next []*issue.Issue
 
 	err = commitWithRetry(store, commitMaxRetries, func() (string, error) {
 		var cerr error
@@ -56,6 +56,11 @@ func cmdClose(store *issue.Store, args []string, w Writer, _ *config.Config) (*c
 		if cerr != nil {
 			return "", cerr
 		}
+		var recurLines []string
+		next, recurLines, cerr = recurClosed(store, []*issue.Issue{iss})
+		if cerr != nil {
+			return "", cerr
+		}
 		intent := fmt.Sprintf("close %s", iss.ID)
 		if ca.Reason != "" {
 			intent += fmt.Sprintf(" reason=%q", ca.Reason)
@@ -63,6 +68,9 @@ func cmdClose(store *issue.Store, args []string, w Writer, _ *config.Config) (*c
 		for _, u := range unblocked {
 			intent += fmt.Sprintf("\nunblocked %s", u.ID)
 		}
+		for _, line := range recurLines {
+			intent += "\n" + line
+		}
 		return intent, nil
 	})
 	if err != nil {
@@ -77,9 +85,13 @@ func cmdClose(store *issue.Store, args []string, w Writer, _ *config.Config) (*c
 		if result.Unblocked == nil {
 			result.Unblocked = []*issue.Issue{}
 		}
+		if len(next) > 0 {
+			result.Next = next[0]
+		}
 		fprintJSON(w, result)
 	} else {
 		fmt.Fprintf(w, "closed {id:%s}: ~~%s~~\n", iss.ID, md.Escape(iss.Title))
+		printRecurred(w, next)
 		if len(unblocked) > 0 {
 			w.Push(2)
 			for _, u := range unblocked {
@@ -124,6 +136,14 @@ func cmdCloseRecursive(store *issue.Store, ca CloseArgs, w Writer) (*config.Conf
 		for _, u := range result.Unblocked {
 			intent += fmt.Sprintf("\nunblocked %s", u.ID)
 		}
+		var recurLines []string
+		result.Next, recurLines, cerr = recurClosed(store, result.Closed)
+		if cerr != nil {
+			return "", cerr
+		}
+		for _, line := range recurLines {
+			intent += "\n" + line
+		}
 		return intent, nil
 	})
 	if err != nil {
@@ -150,6 +170,7 @@ func cmdCloseRecursive(store *issue.Store, ca CloseArgs, w Writer) (*config.Conf
 		fmt.Fprintf(w, "{id:%s}: ~~%s~~\n", iss.ID, md.Escape(iss.Title))
 	}
 	w.Pop()
+	printRecurred(w, result.Next)
 	if len(result.Skipped) > 0 {
 		fmt.Fprintf(w, "\n%d already closed, skipped.\n", len(result.Skipped))
 	}
@@ -170,6 +191,13 @@ func cmdCloseRecursive(store *issue.Store, ca CloseArgs, w Writer) (*config.Conf
 	return nil, nil
 }
 
+// printRecurred reports the next instances spawned for recurring issues.
+func printRecurred(w Writer, next []*issue.Issue) {
+	for _, n := range next {
+		fmt.Fprintf(w, "recurs as {id:%s}, deferred until %s\n", n.ID, n.DeferUntil)
+	}
+}
+
 type ReopenArgs struct {
 	ID   string
 	JSON bool
@@ -39,6 +39,8 @@ type Command struct {
 	Flags       []Flag
 	Examples    []Example
 	NeedsStore  bool // when true, main injects an initialized store
+	ReadOnly    bool // when true, the command may run against a --at snapshot
+	Local       bool // when true, the command never runs through a bw serve daemon
 	Run         func(store *issue.Store, args []string, w Writer, cfg *config.Config) (*config.Config, error)
 }
 
@@ -78,6 +80,9 @@ var commands = []Command{
 			{Long: "--defer", Value: "DATE", Help: "Defer until date/time (YYYY-MM-DD, RFC3339, or expression)"},
 			{Long: "--due", Value: "DATE", Help: "Due date/time (YYYY-MM-DD, RFC3339, or expression)"},
 			{Long: "--parent", Value: "ID", Help: "Parent issue ID"},
+			{Long: "--every", Value: "RULE", Help: "Recur on close (e.g. \"monday 9am\", 2w, monthly)"},
+			{Long: "--template", Value: "NAME", Help: "Start from a saved template (see bw template)"},
+			{Long: "--field", Value: "KEY=VALUE", Help: "Set a declared custom field (repeatable)"},
 			{Long: "--json", Help: "Output as JSON"},
 			{Long: "--silent", Help: "Output bare issue ID only"},
 		},
@@ -85,7 +90,10 @@ var commands = []Command{
 			{Cmd: `bw create "Fix login bug" --priority 1 --type bug`},
 			{Cmd: `bw create "Q3 planning" --defer 2027-07-01`},
 			{Cmd: `bw create "Ship v2" --due 2027-09-01`},
+			{Cmd: `bw create "Weekly report" --every "monday 9am"`, Help: "Closing it defers the next one to the following Monday"},
+			{Cmd: `bw create "Crash on save" --field severity=high --field estimate=3`},
 			{Cmd: `bw create "Fix bug" --silent`, Help: "Output bare ID for scripting"},
+			{Cmd: `bw create --template bug "Login fails"`, Help: "Type, priority, labels and description from the bug template"},
 		},
 		NeedsStore: true,
 		Run:        cmdCreate,
@@ -94,13 +102,13 @@ var commands = []Command{
 		Name:        "show",
 		Aliases:     []string{"view"},
 		Summary:     "Show issue details",
-		Description: "Display full details for an issue including status, priority, labels, and dependency context.\nBy default all sections are shown. Use --only to select specific sections.\n\nThe BLOCKED BY section shows actionable tips — the leaf issues that need work to unblock this one.\nThe UNBLOCKS section shows what completing this issue would immediately unblock.\n\nAlias: view",
+		Description: "Display full details for an issue including status, priority, labels, and dependency context.\nBy default all sections are shown. Use --only to select specific sections.\n\nThe BLOCKED BY section shows actionable tips — the leaf issues that need work to unblock this one.\nThe UNBLOCKS section shows what completing this issue would immediately unblock.\nThe COMMITS section lists code commits whose messages mention the issue (see bw log).\n\nAlias: view",
 		Positionals: []Positional{
 			{Name: "<id>", Required: true, Help: "Issue ID"},
 		},
 		Flags: []Flag{
 			{Long: "--json", Help: "Output as JSON object"},
-			{Long: "--only", Value: "SECTIONS", Help: "Show only named sections (comma-separated: summary, description, children, blockedby, unblocks, comments)"},
+			{Long: "--only", Value: "SECTIONS", Help: "Show only named sections (comma-separated: summary, description, children, blockedby, unblocks, related, comments, commits)"},
 		},
 		Examples: []Example{
 			{Cmd: "bw show bw-a3f8", Help: "Full details for one issue"},
@@ -110,6 +118,7 @@ var commands = []Command{
 			{Cmd: "bw show bw-a3f8 --json", Help: "Machine-readable output"},
 		},
 		NeedsStore: true,
+		ReadOnly:   true,
 		Run:        cmdShow,
 	},
 	{
@@ -125,10 +134,13 @@ var commands = []Command{
 			{Long: "--label", Value: "LABEL", Help: "Filter by label"},
 			{Long: "--grep", Short: "-g", Value: "TEXT", Help: "Search title and description"},
 			{Long: "--parent", Value: "ID", Help: "Filter by parent issue ID"},
+			{Long: "--where", Value: "KEY=VALUE", Help: "Filter by custom field (repeatable; empty value matches unset)"},
+			{Long: "--query", Short: "-q", Value: "EXPR", Help: "Filter by query expression (see docs/design.md)"},
 			{Long: "--limit", Value: "N", Help: "Max results (default 10)"},
 			{Long: "--all", Help: "Show all issues (no status/limit filter)"},
 			{Long: "--deferred", Help: "Show only deferred issues"},
 			{Long: "--overdue", Help: "Show only overdue issues"},
+			{Long: "--deleted", Help: "Show deleted issues that bw undelete can restore"},
 			{Long: "--json", Help: "Output as JSON"},
 		},
 		Examples: []Example{
@@ -138,8 +150,14 @@ var commands = []Command{
 			{Cmd: "bw list --parent bw-a3f8", Help: "Children of an epic"},
 			{Cmd: "bw list --deferred"},
 			{Cmd: "bw list --overdue"},
+			{Cmd: "bw list --deleted"},
+			{Cmd: "bw list --where severity=high"},
+			{Cmd: "bw list @triage", Help: "Run a saved view (see bw view)"},
+			{Cmd: "bw list -q 'priority<=1 label:auth -label:wontfix'"},
+			{Cmd: "bw list -q 'assignee:me due<7d (type:bug OR type:task)'"},
 		},
 		NeedsStore: true,
+		ReadOnly:   true,
 		Run:        cmdList,
 	},
 	{
@@ -155,10 +173,12 @@ var commands = []Command{
 			{Long: "--priority", Short: "-p", Value: "N", Help: "Priority (0-4 or P0-P4, 0=highest)"},
 			{Long: "--assignee", Short: "-a", Value: "WHO", Help: "New assignee"},
 			{Long: "--type", Short: "-t", Value: "TYPE", Help: "New type"},
-			{Long: "--status", Short: "-s", Value: "STATUS", Help: "New status"},
+			{Long: "--status", Short: "-s", Value: "STATUS", Help: "New status (subject to configured transitions)"},
 			{Long: "--defer", Value: "DATE", Help: "Defer until date/time (YYYY-MM-DD, RFC3339, or expression)"},
 			{Long: "--due", Value: "DATE", Help: "Due date/time (YYYY-MM-DD, RFC3339, expression, or empty to clear)"},
 			{Long: "--parent", Value: "ID", Help: "Parent issue ID (empty to clear)"},
+			{Long: "--every", Value: "RULE", Help: "Recurrence rule (empty to stop recurring)"},
+			{Long: "--field", Value: "KEY=VALUE", Help: "Set a declared custom field (repeatable; empty value to clear)"},
 			{Long: "--json", Help: "Output as JSON"},
 		},
 		Examples: []Example{
@@ -166,6 +186,8 @@ var commands = []Command{
 			{Cmd: "bw update bw-a3f8 --status in_progress"},
 			{Cmd: "bw update bw-a3f8 --defer 2027-06-01"},
 			{Cmd: "bw update bw-a3f8 --due 2027-09-01"},
+			{Cmd: "bw update bw-a3f8 --every 2w"},
+			{Cmd: "bw update bw-a3f8 --field severity=low"},
 		},
 		NeedsStore: true,
 		Run:        cmdUpdate,
@@ -173,7 +195,7 @@ var commands = []Command{
 	{
 		Name:        "close",
 		Summary:     "Close an issue",
-		Description: "Close an issue. Optionally provide a reason.\nWith --recursive, also close the issue's entire subtree (all descendants).",
+		Description: "Close an issue. Optionally provide a reason.\nWith --recursive, also close the issue's entire subtree (all descendants).\n\nClosing a recurring issue (see bw create --every) creates its next instance,\ndeferred until the rule next comes around.",
 		Positionals: []Positional{
 			{Name: "<id>", Required: true, Help: "Issue ID"},
 		},
@@ -193,18 +215,21 @@ var commands = []Command{
 	{
 		Name:        "start",
 		Summary:     "Start working on an issue",
-		Description: "Move an issue to in_progress and assign it. Refuses to start blocked issues.\nDefaults assignee to git user.name if not provided.",
+		Description: "Move an issue to in_progress and assign it. Refuses to start blocked issues.\nDefaults assignee to git user.name if not provided.\n\nWith --lease the claim expires unless renewed with bw heartbeat; an issue\nwhose lease has expired shows up in bw ready and can be started again.\nStarting an issue under a live lease requires --steal.",
 		NeedsStore:  true,
 		Positionals: []Positional{
 			{Name: "<id>", Required: true, Help: "Issue ID"},
 		},
 		Flags: []Flag{
 			{Long: "--assignee", Short: "-a", Value: "WHO", Help: "Assignee (default: git user.name)"},
+			{Long: "--lease", Value: "DURATION", Help: "Claim expires after DURATION (e.g. 30m, 2h)"},
+			{Long: "--steal", Help: "Take over an issue someone else is working on"},
 			{Long: "--json", Help: "Output as JSON"},
 		},
 		Examples: []Example{
 			{Cmd: "bw start bw-a3f8"},
 			{Cmd: "bw start bw-a3f8 --assignee alice"},
+			{Cmd: "bw start bw-a3f8 --lease 2h", Help: "Claim for two hours"},
 		},
 		Run: cmdStart,
 	},
@@ -243,6 +268,7 @@ var commands = []Command{
 			{Cmd: "bw attach bw-a3f8 /tmp/out.log --name logs/out.log"},
 		},
 		NeedsStore: true,
+		Local:      true,
 		Run:        cmdAttach,
 	},
 	{
@@ -297,28 +323,138 @@ var commands = []Command{
 	},
 	{
 		Name:        "dep",
-		Summary:     "Manage dependencies",
-		Description: "Add or remove dependency links between issues.\nSubcommands: add, remove.",
+		Summary:     "Manage dependencies and relations",
+		Description: "Add or remove links between issues.\nSubcommands: add, remove.\n\nKinds: blocks (affects readiness), relates-to, duplicates, supersedes.\nRelations are informational and shown as sections in bw show.",
 		Positionals: []Positional{
 			{Name: "add|remove", Required: true, Help: "Subcommand"},
-			{Name: "<id> blocks <id>", Required: true, Help: "Blocker and blocked issue IDs"},
+			{Name: "<from>", Required: true, Help: "Issue ID: the blocker, or the side the relation reads from"},
+			{Name: "<kind>", Required: true, Help: "blocks, relates-to, duplicates or supersedes"},
+			{Name: "<to>", Required: true, Help: "Issue ID: the blocked issue, or the relation's target"},
+		},
+		Flags: []Flag{
+			{Long: "--close", Help: "With add ... duplicates: close the duplicate, pointing at the canonical issue"},
 		},
 		Examples: []Example{
 			{Cmd: "bw dep add bw-1234 blocks bw-5678"},
 			{Cmd: "bw dep remove bw-1234 blocks bw-5678"},
+			{Cmd: "bw dep add bw-1234 relates-to bw-5678"},
+			{Cmd: "bw dep add bw-1234 duplicates bw-5678 --close", Help: "Close bw-1234 as a duplicate of bw-5678"},
+			{Cmd: "bw dep add bw-9abc supersedes bw-1234"},
 		},
 		NeedsStore: true,
 		Run:        cmdDep,
 	},
 	{
-		Name:    "ready",
-		Summary: "List unblocked issues",
+		Name:        "graph",
+		Summary:     "Export the dependency graph",
+		Description: "Print blocks and parent links as a Graphviz DOT graph, a Mermaid flowchart\nor JSON. Blocks are solid arrows, parent links dashed lines; nodes are\nfilled by status and outlined by priority.\n\nWith no ID every open issue is drawn. With an ID, the graph holds the issue,\nits subtree and everything linked to them by blocks, followed as far as\n--depth allows.",
+		Positionals: []Positional{
+			{Name: "<id>", Help: "Issue to centre the graph on"},
+		},
+		Flags: []Flag{
+			{Long: "--format", Value: "FMT", Help: "dot (default), mermaid or json"},
+			{Long: "--depth", Value: "N", Help: "With an ID, follow at most N links from it"},
+			{Long: "--include-closed", Help: "Include closed issues"},
+			{Long: "--json", Help: "Same as --format json"},
+		},
+		Examples: []Example{
+			{Cmd: "bw graph | dot -Tsvg > graph.svg", Help: "Render with Graphviz"},
+			{Cmd: "bw graph bw-1234 --format mermaid", Help: "Mermaid for a PR description"},
+			{Cmd: "bw graph bw-1234 --depth 2 --include-closed"},
+		},
+		NeedsStore: true,
+		ReadOnly:   true,
+		Run:        cmdGraph,
+	},
+	{
+		Name:        "path",
+		Summary:     "Show the critical path to an issue",
+		Description: "Find the longest chain of open work standing between an issue and closing\nit. An issue waits on its open blockers and its open children. Each issue\ncounts as 1, or as its --estimate field value (an int field).\n\nAlso lists issues due before something they wait on, and the ready issue\nwhose closing shortens the path most.",
+		Positionals: []Positional{
+			{Name: "<id>", Required: true, Help: "Issue ID, typically an epic"},
+		},
 		Flags: []Flag{
+			{Long: "--estimate", Value: "FIELD", Help: "Weight issues by this int field (missing values count as 1)"},
 			{Long: "--json", Help: "Output as JSON"},
 		},
+		Examples: []Example{
+			{Cmd: "bw path bw-1234", Help: "What stands between bw-1234 and shipping"},
+			{Cmd: "bw path bw-1234 --estimate points"},
+		},
 		NeedsStore: true,
+		ReadOnly:   true,
+		Run:        cmdPath,
+	},
+	{
+		Name:        "ready",
+		Summary:     "List unblocked issues",
+		Description: "List open issues with no open blockers, grouped under their parents.",
+		Flags: []Flag{
+			{Long: "--query", Short: "-q", Value: "EXPR", Help: "Filter by query expression"},
+			{Long: "--json", Help: "Output as JSON"},
+		},
+		Examples: []Example{
+			{Cmd: "bw ready -q 'assignee:me OR assignee:none'"},
+		},
+		NeedsStore: true,
+		ReadOnly:   true,
 		Run:        cmdReady,
 	},
+	{
+		Name:        "view",
+		Summary:     "Save and run named list filters",
+		Description: "Manage saved views: named bw list arguments stored on the beadwork branch,\nso they sync to every clone. Subcommands: save, list, run, delete.\nA view can also be used as bw list @name; flags after it override the view's.",
+		Positionals: []Positional{
+			{Name: "save|list|run|delete", Required: true, Help: "Subcommand"},
+			{Name: "<name>", Help: "View name"},
+		},
+		Examples: []Example{
+			{Cmd: "bw view save mine -q 'assignee:me' --all", Help: "Save a view"},
+			{Cmd: "bw view run mine"},
+			{Cmd: "bw list @mine --type bug", Help: "Run a view with an extra filter"},
+			{Cmd: "bw view list"},
+			{Cmd: "bw view delete mine"},
+		},
+		NeedsStore: true,
+		Run:        cmdView,
+	},
+	{
+		Name:        "batch",
+		Summary:     "Apply a script of operations as one commit",
+		Description: "Run create, update, close, reopen, label, comment, dep, defer and undefer\noperations from a script and commit them together, one intent line per\noperation. If any operation fails, nothing is committed.\n\nEach line is a command without the leading bw, or a JSON object\n{\"as\": \"name\", \"args\": [\"create\", \"Title\", ...]}. Blank lines and lines\nstarting with # are skipped. $name = create ... binds the new issue's ID,\nand a later argument $name is replaced by it.",
+		Positionals: []Positional{
+			{Name: "<file>", Required: true, Help: "Script to run (- for stdin)"},
+		},
+		Flags: []Flag{
+			{Long: "--json", Help: "Output reference bindings and intents as JSON"},
+		},
+		Examples: []Example{
+			{Cmd: "bw batch plan.bw", Help: "Apply a script atomically"},
+			{Cmd: "bw batch - < plan.bw", Help: "Read the script from stdin"},
+		},
+		NeedsStore: true,
+		Local:      true,
+		Run:        cmdBatch,
+	},
+	{
+		Name:        "template",
+		Summary:     "Manage issue templates for bw create",
+		Description: "Manage templates: JSON issue shapes stored on the beadwork branch, so they\nsync to every clone. Subcommands: save, list, show, delete.\n\nA template sets type, priority, labels and description, and may list\nchildren (each with a title and the same keys, nested as deep as needed).\nTitles and descriptions are Go text/templates rendered with .Title (the\ntitle given to bw create), .Parent (the new issue's parent) and .Date.\nbw create --template <name> creates the issue and its children in one commit;\nflags given to bw create win over the template's values.",
+		Positionals: []Positional{
+			{Name: "save|list|show|delete", Required: true, Help: "Subcommand"},
+			{Name: "<name>", Help: "Template name"},
+			{Name: "<file>", Help: "JSON template to save (- for stdin)"},
+		},
+		Examples: []Example{
+			{Cmd: "bw template save bug bug.json", Help: "Save or replace a template"},
+			{Cmd: "bw template show bug > bug.json", Help: "Export for editing"},
+			{Cmd: "bw template list"},
+			{Cmd: "bw template delete bug"},
+		},
+		NeedsStore: true,
+		Local:      true,
+		Run:        cmdTemplate,
+	},
 	{
 		Name:    "blocked",
 		Summary: "List blocked issues",
@@ -326,6 +462,7 @@ var commands = []Command{
 			{Long: "--json", Help: "Output as JSON"},
 		},
 		NeedsStore: true,
+		ReadOnly:   true,
 		Run:        cmdBlocked,
 	},
 	{
@@ -366,29 +503,122 @@ var commands = []Command{
 		NeedsStore: true,
 		Run:        cmdUndefer,
 	},
+	{
+		Name:        "undelete",
+		Summary:     "Restore a deleted issue",
+		Description: "Restore an issue removed by bw delete, as it was just before the deletion:\nits fields, labels, comments and attachments. Blocks and relation links are\nremade to issues that still exist, and children the deletion orphaned are\nre-adopted. Use bw list --deleted to see what can be restored.",
+		Positionals: []Positional{
+			{Name: "<id>", Required: true, Help: "ID of the deleted issue"},
+		},
+		Flags: []Flag{
+			{Long: "--json", Help: "Output as JSON"},
+		},
+		Examples: []Example{
+			{Cmd: "bw undelete bw-a3f8"},
+		},
+		NeedsStore: true,
+		Run:        cmdUndelete,
+	},
+	{
+		Name:        "heartbeat",
+		Summary:     "Extend the lease on an issue you started",
+		Description: "Push back the expiry of a lease taken with bw start --lease. The lease is\nextended by its original duration unless --lease gives a new one.",
+		Positionals: []Positional{
+			{Name: "<id>", Required: true, Help: "Issue ID"},
+		},
+		Flags: []Flag{
+			{Long: "--assignee", Short: "-a", Value: "WHO", Help: "Lease holder (default: git user.name)"},
+			{Long: "--lease", Value: "DURATION", Help: "New lease length"},
+			{Long: "--json", Help: "Output as JSON"},
+		},
+		Examples: []Example{
+			{Cmd: "bw heartbeat bw-a3f8"},
+			{Cmd: "bw heartbeat bw-a3f8 --lease 30m"},
+		},
+		NeedsStore: true,
+		Run:        cmdHeartbeat,
+	},
 	{
 		Name:        "history",
 		Summary:     "Show issue history",
-		Description: "Show the git commit history for a specific issue.",
+		Description: "Show the git commit history for a specific issue.\n\nWith --diff, each commit is followed by the fields it changed, read from the\nissue before and after the commit: old → new values, labels and links\nadded or removed, comments added, and a unified diff of the description.",
 		Positionals: []Positional{
 			{Name: "<id>", Required: true, Help: "Issue ID"},
 		},
 		Flags: []Flag{
 			{Long: "--limit", Value: "N", Help: "Max entries to show"},
+			{Long: "--diff", Help: "Show the fields each commit changed"},
 			{Long: "--json", Help: "Output as JSON"},
 		},
 		Examples: []Example{
 			{Cmd: "bw history bw-a3f8"},
 			{Cmd: "bw history bw-a3f8 --limit 5"},
+			{Cmd: "bw history bw-a3f8 --diff"},
 		},
 		NeedsStore: true,
 		Run:        cmdHistory,
 	},
+	{
+		Name:        "log",
+		Summary:     "List code commits that mention an issue",
+		Description: "List commits on the code branches (the checked-out branch, main and master)\nwhose messages mention the issue, in the subject, body, or a Refs:/Closes:\ntrailer, newest first, with short diff stats. Commits with a Closes:, Fixes:\nor Resolves: trailer naming the issue are marked.\n\nScanning is incremental: a per-branch cursor under refs/beadwork/code-cursor\nrecords how far each branch has been read, and matches are cached locally.",
+		Positionals: []Positional{
+			{Name: "<id>", Required: true, Help: "Issue ID"},
+		},
+		Flags: []Flag{
+			{Long: "--limit", Value: "N", Help: "Max entries to show"},
+			{Long: "--json", Help: "Output as JSON"},
+		},
+		Examples: []Example{
+			{Cmd: "bw log bw-a3f8"},
+			{Cmd: "bw log bw-a3f8 --json"},
+		},
+		NeedsStore: true,
+		Run:        cmdLog,
+	},
+	{
+		Name:        "undo",
+		Summary:     "Undo recent changes",
+		Description: "Undo the last N commits (default 1), or a specific commit, by committing\nthe inverse intent (close becomes reopen, link becomes unlink, update restores\nprior field values, and so on).\n\nUndoing a specific commit is refused when a later commit touched the same issue.\nComments, deletes, and attachments cannot be undone.",
+		Positionals: []Positional{
+			{Name: "[N|<commit>]", Help: "Number of commits, or a commit hash (at least 4 characters)"},
+		},
+		Flags: []Flag{
+			{Long: "--json", Help: "Output as JSON"},
+		},
+		Examples: []Example{
+			{Cmd: "bw undo", Help: "Undo the most recent change"},
+			{Cmd: "bw undo 3", Help: "Undo the last three changes"},
+			{Cmd: "bw undo 4f2a9c1", Help: "Undo one specific commit"},
+		},
+		NeedsStore: true,
+		Run:        cmdUndo,
+	},
+	{
+		Name:        "revert",
+		Summary:     "Restore an issue's fields to an earlier revision",
+		Description: "Set an issue's fields back to what they were at a commit or point in time,\ncommitting ordinary update, label and link intents. Restores title,\ndescription, priority, type, due, parent, labels and deps (blocks links)\nunless --fields names a subset. Status is left alone; parents and links to\nissues that no longer exist are skipped.\n\n--to takes a commit hash (at least 4 characters), an RFC3339 time, a\nYYYY-MM-DD date, or a window token such as yesterday or 2d.",
+		Positionals: []Positional{
+			{Name: "<id>", Required: true, Help: "Issue ID"},
+		},
+		Flags: []Flag{
+			{Long: "--to", Value: "REV", Help: "Commit or time to restore from (required)"},
+			{Long: "--fields", Value: "F1,F2", Help: "Only restore these fields"},
+			{Long: "--json", Help: "Output as JSON"},
+		},
+		Examples: []Example{
+			{Cmd: "bw revert bw-a3f8 --to 4f2a9c1"},
+			{Cmd: "bw revert bw-a3f8 --to yesterday --fields title,description"},
+		},
+		NeedsStore: true,
+		Run:        cmdRevert,
+	},
 	{
 		Name:        "sync",
 		Summary:     "Fetch, rebase/replay, push",
 		Description: "Fetch from remote, rebase local commits, and push.\nUses intent replay to resolve conflicts automatically.",
 		NeedsStore:  true,
+		Local:       true,
 		Run:         cmdSync,
 	},
 	{
@@ -397,9 +627,11 @@ var commands = []Command{
 		Description: "Export issues as JSONL (one JSON object per line).",
 		Flags: []Flag{
 			{Long: "--status", Short: "-s", Value: "STATUS", Help: "Filter by status"},
+			{Long: "--query", Short: "-q", Value: "EXPR", Help: "Filter by query expression"},
 		},
 		Examples: []Example{
 			{Cmd: "bw export --status open"},
+			{Cmd: "bw export -q 'label:release type:bug'"},
 		},
 		NeedsStore: true,
 		Run:        cmdExport,
@@ -420,6 +652,7 @@ var commands = []Command{
 			{Cmd: "bw import - < issues.jsonl"},
 		},
 		NeedsStore: true,
+		Local:      true,
 		Run:        cmdImport,
 	},
 	{
@@ -451,6 +684,21 @@ var commands = []Command{
 		NeedsStore: true,
 		Run:        cmdConfig,
 	},
+	{
+		Name:        "fsck",
+		Summary:     "Check and repair marker consistency",
+		Description: "Report every disagreement between issues/<id>.json and the marker directories\n(status, labels, blocks, relations, parent, attachments), with its path.\n\n--repair fixes them in a single commit. The issue JSON is trusted for status,\nlabels and parent; marker files are trusted for blocks and relations.\nMarkers and attachments for missing issues are removed.",
+		Flags: []Flag{
+			{Long: "--repair", Help: "Fix repairable problems in one commit"},
+			{Long: "--json", Help: "Output as JSON"},
+		},
+		Examples: []Example{
+			{Cmd: "bw fsck"},
+			{Cmd: "bw fsck --repair"},
+		},
+		NeedsStore: true,
+		Run:        cmdFsck,
+	},
 	{
 		Name:        "upgrade",
 		Summary:     "Upgrade binary or repo schema",
@@ -471,6 +719,32 @@ var commands = []Command{
 		Summary: "Print agent instructions snippet",
 		Run:     wrapNoArgs(cmdOnboard),
 	},
+	{
+		Name:        "mcp",
+		Summary:     "Serve the issue store to agents over MCP",
+		Description: "Run a Model Context Protocol server on stdin/stdout. Exposes create, show,\nlist, ready, blocked, start, close, comment, dep and defer as tools whose\narguments mirror the command's own positionals and flags; results are the\ncommand's JSON output. Writes are committed exactly as from the CLI.",
+		Examples: []Example{
+			{Cmd: "bw mcp", Help: "Typically launched by an agent's MCP client configuration"},
+		},
+		NeedsStore: true,
+		Local:      true,
+		Run:        cmdMCP,
+	},
+	{
+		Name:        "serve",
+		Summary:     "Keep the repo open for faster commands",
+		Description: "Run a daemon that keeps this repository's issue store loaded and answers\nforwarded commands over a Unix socket (default .git/beadwork/bw.sock).\nWhile it runs, bw commands in the repo are sent to it transparently; set\nBW_SOCKET to point them at a daemon listening elsewhere. Commits made by\nother processes are picked up before each command. Stop it with Ctrl-C.",
+		Flags: []Flag{
+			{Long: "--socket", Value: "PATH", Help: "Socket path (default .git/beadwork/bw.sock)"},
+		},
+		Examples: []Example{
+			{Cmd: "bw serve &", Help: "Serve this repo on its default socket"},
+			{Cmd: "bw serve --socket /tmp/bw.sock", Help: "Clients then need BW_SOCKET=/tmp/bw.sock"},
+		},
+		NeedsStore: true,
+		Local:      true,
+		Run:        cmdServe,
+	},
 	{
 		Name:       "prime",
 		Summary:    "Print workflow context for agents",
@@ -502,6 +776,46 @@ var commands = []Command{
 		},
 		Run: cmdRecap,
 	},
+	{
+		Name:        "stats",
+		Summary:     "Show lead time, cycle time, throughput and WIP",
+		Description: "Replay the commit history to rebuild when each issue was created, started\nand closed, and report flow metrics for a window (default: last 4w).\n\nLead time runs from create to close, cycle time from first start to close;\nboth are given as median and 85th percentile in days. Throughput, new issues\nand work in progress are shown per week of the window. --burndown charts the\nunfinished issues in an epic's subtree.\n\nWindow tokens are those of bw recap: today, yesterday, week, or a duration\nsuch as 7d or 12w.",
+		Positionals: []Positional{
+			{Name: "[window]", Help: "Time window (default 4w)"},
+		},
+		Flags: []Flag{
+			{Long: "--since", Value: "DATE", Help: "Start time (RFC3339 or YYYY-MM-DD)"},
+			{Long: "--by", Value: "KEY", Help: "Break lead and cycle time down by type, label, assignee or parent"},
+			{Long: "--burndown", Value: "ID", Help: "Chart the open issues in this issue's subtree"},
+			{Long: "--json", Help: "Output as JSON"},
+		},
+		Examples: []Example{
+			{Cmd: "bw stats", Help: "The last four weeks"},
+			{Cmd: "bw stats 12w --by type"},
+			{Cmd: "bw stats --since 2027-01-01 --burndown bw-1234"},
+		},
+		NeedsStore: true,
+		ReadOnly:   true,
+		Run:        cmdStats,
+	},
+	{
+		Name:        "watch",
+		Summary:     "Stream changes as they are committed",
+		Description: "Print an event line for every new commit on the beadwork branch until interrupted:\ncreated, closed, started, updated, commented, unblocked, and so on. Commits made by\nother sessions and pulled in by sync show up as well.\n\nWith --query, only events on issues matching the expression are shown. With --json,\neach event is one JSON object per line.",
+		Flags: []Flag{
+			{Long: "--query", Short: "-q", Value: "EXPR", Help: "Only events on issues matching the expression"},
+			{Long: "--interval", Value: "DURATION", Help: "How often to check for commits (default 1s)"},
+			{Long: "--json", Help: "Output one JSON object per event"},
+		},
+		Examples: []Example{
+			{Cmd: "bw watch"},
+			{Cmd: "bw watch --query label:backend", Help: "Only backend issues"},
+			{Cmd: "bw watch --json | grep unblocked", Help: "React to blockers closing"},
+		},
+		NeedsStore: true,
+		Local:      true,
+		Run:        cmdWatch,
+	},
 	{
 		Name:        "registry",
 		Summary:     "Manage the repository registry",
@@ -544,12 +858,12 @@ var commandGroups = []struct {
 	name string
 	cmds []string
 }{
-	{"Working With Issues", []string{"create", "show", "list", "update", "start", "close", "reopen", "delete", "comment", "label", "defer", "undefer", "history", "attach"}},
-	{"Finding Work", []string{"ready", "blocked"}},
-	{"Dependencies", []string{"dep"}},
+	{"Working With Issues", []string{"create", "show", "list", "update", "start", "heartbeat", "close", "reopen", "delete", "undelete", "comment", "label", "defer", "undefer", "history", "log", "undo", "revert", "attach", "template", "batch"}},
+	{"Finding Work", []string{"ready", "blocked", "view"}},
+	{"Dependencies", []string{"dep", "graph", "path"}},
 	{"Sync & Data", []string{"sync", "export", "import"}},
-	{"Cross-Repo & Activity", []string{"recap", "registry"}},
-	{"Setup & Config", []string{"init", "config", "upgrade", "onboard", "prime"}},
+	{"Cross-Repo & Activity", []string{"recap", "stats", "watch", "registry"}},
+	{"Setup & Config", []string{"init", "config", "fsck", "upgrade", "onboard", "prime", "mcp", "serve"}},
 }
 
 func printUsage(w Writer) {
@@ -584,6 +898,7 @@ func printUsage(w Writer) {
 	w.Push(2)
 	fmt.Fprintf(w, "%-28s %s\n", "-C <dir>", "Run as if started in <dir>")
 	fmt.Fprintf(w, "%-28s %s\n", "--dry-run", "Run without committing changes")
+	fmt.Fprintf(w, "%-28s %s\n", "--at <rev|time>", "Read-only view as of a commit or time (list, show, ready, blocked)")
 	w.Pop()
 
 	fmt.Fprintln(w, "\nUse \"bw <command> --help\" for more information about a command.")
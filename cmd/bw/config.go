@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"sort"
+	"strings"
 
 	"github.com/jallum/beadwork/internal/config"
 
@@ -57,6 +58,23 @@ func cmdConfig(store *issue.Store, args []string, w Writer, _ *config.Config) (*
 		fmt.Fprintln(w, val)
 
 	case "set":
+		if strings.HasPrefix(ca.Key, "hooks.") {
+			return nil, fmt.Errorf("hooks are not shared through the beadwork branch; use git config beadwork.%s <path> or set %s in %s", ca.Key, ca.Key, config.DefaultPath())
+		}
+		if strings.HasPrefix(ca.Key, "status.") || strings.HasPrefix(ca.Key, "transitions.") {
+			cfg := r.ListConfig()
+			cfg[ca.Key] = ca.Value
+			if _, err := issue.ParseWorkflow(cfg); err != nil {
+				return nil, err
+			}
+		}
+		if strings.HasPrefix(ca.Key, "field.") {
+			cfg := r.ListConfig()
+			cfg[ca.Key] = ca.Value
+			if _, err := issue.ParseFields(cfg); err != nil {
+				return nil, err
+			}
+		}
 		if err := r.SetConfig(ca.Key, ca.Value); err != nil {
 			return nil, err
 		}
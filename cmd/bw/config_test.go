@@ -55,3 +55,48 @@ func TestCmdConfigGetMissing(t *testing.T) {
 		t.Error("expected error for missing key")
 	}
 }
+
+func TestCmdConfigSetRejectsInvalidWorkflow(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	var buf bytes.Buffer
+	if _, err := cmdConfig(env.Store, []string{"set", "status.review", "active"}, PlainWriter(&buf), nil); err != nil {
+		t.Fatalf("config set status.review: %v", err)
+	}
+	_, err := cmdConfig(env.Store, []string{"set", "transitions.review", "qa"}, PlainWriter(&buf), nil)
+	if err == nil || !strings.Contains(err.Error(), `unknown status "qa"`) {
+		t.Errorf("err = %v, want unknown status qa", err)
+	}
+	if _, ok := env.Repo.GetConfig("transitions.review"); ok {
+		t.Error("invalid transition should not be saved")
+	}
+}
+
+func TestCmdConfigSetRejectsInvalidField(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	var buf bytes.Buffer
+	_, err := cmdConfig(env.Store, []string{"set", "field.size", "float"}, PlainWriter(&buf), nil)
+	if err == nil || !strings.Contains(err.Error(), "type must be") {
+		t.Errorf("err = %v, want invalid type", err)
+	}
+	if _, ok := env.Repo.GetConfig("field.size"); ok {
+		t.Error("invalid field should not be saved")
+	}
+}
+
+func TestCmdConfigSetRejectsHooks(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	var buf bytes.Buffer
+	_, err := cmdConfig(env.Store, []string{"set", "hooks.post-close", "/bin/true"}, PlainWriter(&buf), nil)
+	if err == nil || !strings.Contains(err.Error(), "git config beadwork.hooks.post-close") {
+		t.Errorf("err = %v, want pointer to git config", err)
+	}
+	if _, ok := env.Repo.GetConfig("hooks.post-close"); ok {
+		t.Error("hook should not be saved to the beadwork branch")
+	}
+}
@@ -18,14 +18,17 @@ type CreateArgs struct {
 	Description string
 	DeferUntil  string
 	Due         string
+	Every       string
+	Template    string
 	Labels      []string
+	Fields      map[string]string
 	JSON        bool
 	Silent      bool
 }
 
 func parseCreateArgs(raw []string) (CreateArgs, error) {
 	a, err := ParseArgs(raw,
-		[]string{"--priority", "--type", "--description", "--defer", "--due", "--labels", "--parent", "--id"},
+		[]string{"--priority", "--type", "--description", "--defer", "--due", "--every", "--template", "--labels", "--parent", "--id", "--field"},
 		[]string{"--json", "--silent"},
 	)
 	if err != nil {
@@ -45,6 +48,8 @@ func parseCreateArgs(raw []string) (CreateArgs, error) {
 	ca.Description = a.String("--description")
 	ca.DeferUntil = a.String("--defer")
 	ca.Due = a.String("--due")
+	ca.Every = a.String("--every")
+	ca.Template = a.String("--template")
 	ca.Parent = a.String("--parent")
 	ca.JSON = a.JSON()
 	ca.Silent = a.Bool("--silent")
@@ -55,6 +60,10 @@ func parseCreateArgs(raw []string) (CreateArgs, error) {
 		}
 		ca.Priority = &p
 	}
+	ca.Fields, err = parseFieldArgs("--field", a.Strings("--field"))
+	if err != nil {
+		return ca, err
+	}
 	if a.Has("--labels") {
 		for _, l := range strings.Split(a.String("--labels"), ",") {
 			l = strings.TrimSpace(l)
@@ -87,6 +96,11 @@ func cmdCreate(store *issue.Store, args []string, w Writer, _ *config.Config) (*
 		}
 		ca.Due = resolved
 	}
+	if ca.Every != "" {
+		if _, err := nextOccurrence(ca.Every, now); err != nil {
+			return nil, err
+		}
+	}
 
 	opts := issue.CreateOpts{
 		ID:          ca.ID,
@@ -95,44 +109,84 @@ func cmdCreate(store *issue.Store, args []string, w Writer, _ *config.Config) (*
 		Description: ca.Description,
 		DeferUntil:  ca.DeferUntil,
 		Due:         ca.Due,
+		Every:       ca.Every,
 		Parent:      ca.Parent,
+		Fields:      ca.Fields,
 	}
 
-	var iss *issue.Issue
+	var tpl *issue.Template
+	if ca.Template != "" {
+		if tpl, err = store.GetTemplate(ca.Template); err != nil {
+			return nil, err
+		}
+	}
+
+	var created []*issue.Issue
 	err = commitWithRetry(store, commitMaxRetries, func() (string, error) {
 		var cerr error
-		iss, cerr = store.Create(ca.Title, opts)
+		if tpl != nil {
+			created, cerr = createFromTemplate(store, tpl, ca, opts)
+		} else {
+			var iss *issue.Issue
+			iss, cerr = createLabeled(store, ca.Title, opts, ca.Labels)
+			created = []*issue.Issue{iss}
+		}
 		if cerr != nil {
 			return "", cerr
 		}
-		if len(ca.Labels) > 0 {
-			iss, cerr = store.Label(iss.ID, ca.Labels, nil)
-			if cerr != nil {
-				return "", cerr
-			}
-		}
-		intent := fmt.Sprintf("create %s p%d %s %q", iss.ID, iss.Priority, iss.Type, iss.Title)
-		if iss.Description != "" {
-			intent += fmt.Sprintf(" description=%q", iss.Description)
-		}
-		if iss.Due != "" {
-			intent += " due=" + iss.Due
+		// One intent line per issue, so replay recreates each of them.
+		lines := make([]string, len(created))
+		for i, iss := range created {
+			lines[i] = createIntent(iss)
 		}
-		if iss.Parent != "" {
-			intent += " parent=" + iss.Parent
-		}
-		return intent, nil
+		return strings.Join(lines, "\n"), nil
 	})
 	if err != nil {
 		return nil, err
 	}
 
+	iss := created[0]
 	if ca.Silent {
 		fmt.Fprintln(w, iss.ID)
 	} else if ca.JSON {
 		fprintJSON(w, iss)
 	} else {
-		fmt.Fprintf(w, "created %s: %s\n", iss.ID, iss.Title)
+		for _, c := range created {
+			fmt.Fprintf(w, "created %s: %s\n", c.ID, c.Title)
+		}
 	}
 	return nil, nil
 }
+
+// createLabeled creates an issue and applies labels to it.
+func createLabeled(store *issue.Store, title string, opts issue.CreateOpts, labels []string) (*issue.Issue, error) {
+	iss, err := store.Create(title, opts)
+	if err != nil || len(labels) == 0 {
+		return iss, err
+	}
+	return store.Label(iss.ID, labels, nil)
+}
+
+// createIntent is the intent line that recreates iss as it was created.
+func createIntent(iss *issue.Issue) string {
+	intent := fmt.Sprintf("create %s p%d %s %q", iss.ID, iss.Priority, iss.Type, iss.Title)
+	if iss.Description != "" {
+		intent += fmt.Sprintf(" description=%q", iss.Description)
+	}
+	if iss.Due != "" {
+		intent += " due=" + iss.Due
+	}
+	if iss.Parent != "" {
+		intent += " parent=" + iss.Parent
+	}
+	if iss.Every != "" {
+		intent += fmt.Sprintf(" every=%q", iss.Every)
+	}
+	if len(iss.Labels) > 0 {
+		intent += " labels=" + strings.Join(iss.Labels, ",")
+	}
+	for _, name := range issue.SortedFieldNames(iss.Fields) {
+		intent += fmt.Sprintf(" field.%s=%q", name, iss.Fields[name])
+	}
+	return intent
+}
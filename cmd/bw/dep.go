@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/jallum/beadwork/internal/config"
@@ -8,16 +9,22 @@ import (
 	"github.com/jallum/beadwork/internal/issue"
 )
 
+const depAddUsage = "usage: bw dep add <id> blocks|relates-to|duplicates|supersedes <id> [--close]"
+const depRemoveUsage = "usage: bw dep remove <id> blocks|relates-to|duplicates|supersedes <id>"
+
 // DepArgs holds the parsed subcommand and IDs for "bw dep add|remove".
+// For relation kinds, BlockerID is the "from" side and BlockedID the "to".
 type DepArgs struct {
 	Subcmd    string // "add" or "remove"
 	BlockerID string
+	Kind      string // "blocks" or an issue.RelationKinds entry
 	BlockedID string
+	Close     bool
 }
 
 func parseDepArgs(raw []string) (DepArgs, error) {
 	if len(raw) == 0 {
-		return DepArgs{}, fmt.Errorf("usage: bw dep add|remove <id> blocks <id>")
+		return DepArgs{}, fmt.Errorf("usage: bw dep add|remove <id> <kind> <id>")
 	}
 	da := DepArgs{Subcmd: raw[0]}
 	switch da.Subcmd {
@@ -27,16 +34,19 @@ func parseDepArgs(raw []string) (DepArgs, error) {
 			return da, err
 		}
 		da.BlockerID = a.BlockerID
+		da.Kind = a.Kind
 		da.BlockedID = a.BlockedID
+		da.Close = a.Close
 	case "remove":
 		a, err := parseDepRemoveArgs(raw[1:])
 		if err != nil {
 			return da, err
 		}
 		da.BlockerID = a.BlockerID
+		da.Kind = a.Kind
 		da.BlockedID = a.BlockedID
 	default:
-		return da, fmt.Errorf("usage: bw dep add|remove <id> blocks <id>")
+		return da, fmt.Errorf("usage: bw dep add|remove <id> <kind> <id>")
 	}
 	return da, nil
 }
@@ -48,23 +58,43 @@ func cmdDep(store *issue.Store, args []string, w Writer, _ *config.Config) (*con
 	}
 	switch da.Subcmd {
 	case "add":
-		return cmdDepAdd(store, []string{da.BlockerID, "blocks", da.BlockedID}, w, nil)
+		add := []string{da.BlockerID, da.Kind, da.BlockedID}
+		if da.Close {
+			add = append(add, "--close")
+		}
+		return cmdDepAdd(store, add, w, nil)
 	case "remove":
-		return cmdDepRemove(store, []string{da.BlockerID, "blocks", da.BlockedID}, w, nil)
+		return cmdDepRemove(store, []string{da.BlockerID, da.Kind, da.BlockedID}, w, nil)
 	}
 	return nil, nil
 }
 
+// validDepKind reports whether kind names a link bw dep understands.
+func validDepKind(kind string) bool {
+	return kind == "blocks" || issue.IsRelationKind(kind)
+}
+
 type DepAddArgs struct {
 	BlockerID string
+	Kind      string
 	BlockedID string
+	Close     bool // duplicates only: close the duplicate
 }
 
 func parseDepAddArgs(raw []string) (DepAddArgs, error) {
-	if len(raw) < 3 || raw[1] != "blocks" {
-		return DepAddArgs{}, fmt.Errorf("usage: bw dep add <id> blocks <id>")
+	a, err := ParseArgs(raw, nil, []string{"--close"})
+	if err != nil {
+		return DepAddArgs{}, err
+	}
+	pos := a.Pos()
+	if len(pos) < 3 || !validDepKind(pos[1]) {
+		return DepAddArgs{}, errors.New(depAddUsage)
+	}
+	da := DepAddArgs{BlockerID: pos[0], Kind: pos[1], BlockedID: pos[2], Close: a.Bool("--close")}
+	if da.Close && da.Kind != issue.Duplicates {
+		return da, fmt.Errorf("--close only applies to duplicates")
 	}
-	return DepAddArgs{BlockerID: raw[0], BlockedID: raw[2]}, nil
+	return da, nil
 }
 
 func cmdDepAdd(store *issue.Store, args []string, w Writer, _ *config.Config) (*config.Config, error) {
@@ -73,34 +103,67 @@ func cmdDepAdd(store *issue.Store, args []string, w Writer, _ *config.Config) (*
 		return nil, err
 	}
 
-	var blocker, blocked *issue.Issue
+	var from, to *issue.Issue
+	var closed bool
 	err = commitWithRetry(store, commitMaxRetries, func() (string, error) {
-		if lerr := store.Link(la.BlockerID, la.BlockedID); lerr != nil {
+		var lerr error
+		if la.Kind == "blocks" {
+			lerr = store.Link(la.BlockerID, la.BlockedID)
+		} else {
+			lerr = store.Relate(la.Kind, la.BlockerID, la.BlockedID)
+		}
+		if lerr != nil {
 			return "", lerr
 		}
-		blocker, _ = store.Get(la.BlockerID)
-		blocked, _ = store.Get(la.BlockedID)
+		from, _ = store.Get(la.BlockerID)
+		to, _ = store.Get(la.BlockedID)
 		// Intent verb stays "link" for replay compatibility.
-		return fmt.Sprintf("link %s blocks %s", blocker.ID, blocked.ID), nil
+		intent := fmt.Sprintf("link %s %s %s", from.ID, la.Kind, to.ID)
+
+		closed = false
+		if la.Close && !store.IsClosed(from.ID) {
+			reason := "duplicate of " + to.ID
+			if _, cerr := store.Close(from.ID, reason); cerr != nil {
+				return "", cerr
+			}
+			unblocked, cerr := store.NewlyUnblocked(from.ID)
+			if cerr != nil {
+				return "", cerr
+			}
+			intent += fmt.Sprintf("\nclose %s reason=%q", from.ID, reason)
+			for _, u := range unblocked {
+				intent += fmt.Sprintf("\nunblocked %s", u.ID)
+			}
+			closed = true
+		}
+		return intent, nil
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	fmt.Fprintf(w, "added dep %s blocks %s\n", blocker.ID, blocked.ID)
+	if la.Kind == "blocks" {
+		fmt.Fprintf(w, "added dep %s blocks %s\n", from.ID, to.ID)
+	} else {
+		fmt.Fprintf(w, "added %s %s %s\n", from.ID, la.Kind, to.ID)
+	}
+	if closed {
+		fmt.Fprintf(w, "closed %s: duplicate of %s\n", from.ID, to.ID)
+	}
 	return nil, nil
 }
 
 type DepRemoveArgs struct {
 	BlockerID string
+	Kind      string
 	BlockedID string
 }
 
 func parseDepRemoveArgs(raw []string) (DepRemoveArgs, error) {
-	if len(raw) < 3 || raw[1] != "blocks" {
-		return DepRemoveArgs{}, fmt.Errorf("usage: bw dep remove <id> blocks <id>")
+	if len(raw) < 3 || !validDepKind(raw[1]) {
+		return DepRemoveArgs{}, errors.New(depRemoveUsage)
 	}
-	return DepRemoveArgs{BlockerID: raw[0], BlockedID: raw[2]}, nil
+	return DepRemoveArgs{BlockerID: raw[0], Kind: raw[1], BlockedID: raw[2]}, nil
 }
 
 func cmdDepRemove(store *issue.Store, args []string, w Writer, _ *config.Config) (*config.Config, error) {
@@ -109,23 +172,36 @@ func cmdDepRemove(store *issue.Store, args []string, w Writer, _ *config.Config)
 		return nil, err
 	}
 
-	var blocker, blocked *issue.Issue
+	var from, to *issue.Issue
 	err = commitWithRetry(store, commitMaxRetries, func() (string, error) {
-		if !store.DepExists(ua.BlockerID, ua.BlockedID) {
-			return "", fmt.Errorf("no dependency: %s does not block %s", ua.BlockerID, ua.BlockedID)
-		}
-		if uerr := store.Unlink(ua.BlockerID, ua.BlockedID); uerr != nil {
-			return "", uerr
+		if ua.Kind == "blocks" {
+			if !store.DepExists(ua.BlockerID, ua.BlockedID) {
+				return "", fmt.Errorf("no dependency: %s does not block %s", ua.BlockerID, ua.BlockedID)
+			}
+			if uerr := store.Unlink(ua.BlockerID, ua.BlockedID); uerr != nil {
+				return "", uerr
+			}
+		} else {
+			if !store.RelationExists(ua.Kind, ua.BlockerID, ua.BlockedID) {
+				return "", fmt.Errorf("no relation: %s %s %s is not recorded", ua.BlockerID, ua.Kind, ua.BlockedID)
+			}
+			if uerr := store.Unrelate(ua.Kind, ua.BlockerID, ua.BlockedID); uerr != nil {
+				return "", uerr
+			}
 		}
-		blocker, _ = store.Get(ua.BlockerID)
-		blocked, _ = store.Get(ua.BlockedID)
+		from, _ = store.Get(ua.BlockerID)
+		to, _ = store.Get(ua.BlockedID)
 		// Intent verb stays "unlink" for replay compatibility.
-		return fmt.Sprintf("unlink %s blocks %s", blocker.ID, blocked.ID), nil
+		return fmt.Sprintf("unlink %s %s %s", from.ID, ua.Kind, to.ID), nil
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	fmt.Fprintf(w, "removed dep %s blocks %s\n", blocker.ID, blocked.ID)
+	if ua.Kind == "blocks" {
+		fmt.Fprintf(w, "removed dep %s blocks %s\n", from.ID, to.ID)
+	} else {
+		fmt.Fprintf(w, "removed %s %s %s\n", from.ID, ua.Kind, to.ID)
+	}
 	return nil, nil
 }
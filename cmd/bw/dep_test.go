@@ -72,3 +72,53 @@ func TestCmdDepRemoveNotFound(t *testing.T) {
 		t.Error("expected error for nonexistent issues")
 	}
 }
+
+func TestCmdDepAddRelation(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	a, _ := env.Store.Create("A", issue.CreateOpts{})
+	b, _ := env.Store.Create("B", issue.CreateOpts{})
+	env.Repo.Commit("create issues")
+
+	var buf bytes.Buffer
+	if _, err := cmdDepAdd(env.Store, []string{a.ID, "relates-to", b.ID}, PlainWriter(&buf), nil); err != nil {
+		t.Fatalf("cmdDepAdd: %v", err)
+	}
+	if !strings.Contains(buf.String(), "added "+a.ID+" relates-to "+b.ID) {
+		t.Errorf("output = %q", buf.String())
+	}
+	got, _ := env.Store.Get(b.ID)
+	if len(got.RelatesTo) != 1 || len(got.BlockedBy) != 0 {
+		t.Errorf("RelatesTo = %v, BlockedBy = %v", got.RelatesTo, got.BlockedBy)
+	}
+
+	buf.Reset()
+	if _, err := cmdDepRemove(env.Store, []string{b.ID, "relates-to", a.ID}, PlainWriter(&buf), nil); err != nil {
+		t.Fatalf("cmdDepRemove: %v", err)
+	}
+	if _, err := cmdDepRemove(env.Store, []string{b.ID, "relates-to", a.ID}, PlainWriter(&buf), nil); err == nil {
+		t.Error("expected error removing a missing relation")
+	}
+}
+
+func TestCmdDepAddDuplicatesClose(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	dup, _ := env.Store.Create("Dup", issue.CreateOpts{})
+	canon, _ := env.Store.Create("Canonical", issue.CreateOpts{})
+	env.Repo.Commit("create issues")
+
+	var buf bytes.Buffer
+	if _, err := cmdDepAdd(env.Store, []string{dup.ID, "duplicates", canon.ID, "--close"}, PlainWriter(&buf), nil); err != nil {
+		t.Fatalf("cmdDepAdd: %v", err)
+	}
+	got, _ := env.Store.Get(dup.ID)
+	if got.Status != "closed" || got.CloseReason != "duplicate of "+canon.ID {
+		t.Errorf("status = %q, reason = %q", got.Status, got.CloseReason)
+	}
+	if !strings.Contains(buf.String(), "closed "+dup.ID) {
+		t.Errorf("output = %q", buf.String())
+	}
+}
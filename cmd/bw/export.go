@@ -33,40 +33,49 @@ type beadsDep struct {
 }
 
 type beadsRecord struct {
-	ID           string          `json:"id"`
-	Title        string          `json:"title"`
-	Description  string          `json:"description,omitempty"`
-	Status       string          `json:"status"`
-	Priority     int             `json:"priority"`
-	IssueType    string          `json:"issue_type"`
-	Owner        string          `json:"owner,omitempty"`
-	CreatedAt    string          `json:"created_at"`
-	UpdatedAt    string          `json:"updated_at,omitempty"`
-	ClosedAt     string          `json:"closed_at,omitempty"`
-	CloseReason  string          `json:"close_reason,omitempty"`
-	Labels       []string        `json:"labels,omitempty"`
-	DeferUntil   string          `json:"defer_until,omitempty"`
-	Due          string          `json:"due,omitempty"`
-	Blocks       []string        `json:"blocks,omitempty"`
-	BlockedBy    []string        `json:"blocked_by,omitempty"`
-	Dependencies []beadsDep      `json:"dependencies,omitempty"`
-	Comments     []exportComment `json:"comments,omitempty"`
+	ID           string            `json:"id"`
+	Title        string            `json:"title"`
+	Description  string            `json:"description,omitempty"`
+	Status       string            `json:"status"`
+	Priority     int               `json:"priority"`
+	IssueType    string            `json:"issue_type"`
+	Owner        string            `json:"owner,omitempty"`
+	CreatedAt    string            `json:"created_at"`
+	UpdatedAt    string            `json:"updated_at,omitempty"`
+	ClosedAt     string            `json:"closed_at,omitempty"`
+	CloseReason  string            `json:"close_reason,omitempty"`
+	Labels       []string          `json:"labels,omitempty"`
+	DeferUntil   string            `json:"defer_until,omitempty"`
+	Due          string            `json:"due,omitempty"`
+	Fields       map[string]string `json:"fields,omitempty"`
+	Blocks       []string          `json:"blocks,omitempty"`
+	BlockedBy    []string          `json:"blocked_by,omitempty"`
+	Dependencies []beadsDep        `json:"dependencies,omitempty"`
+	Comments     []exportComment   `json:"comments,omitempty"`
 }
 
 type ExportArgs struct {
 	Status string
+	Query  *issue.Query
 	JSON   bool
 }
 
 func parseExportArgs(raw []string) (ExportArgs, error) {
-	a, err := ParseArgs(raw, []string{"--status"}, []string{"--json"})
+	a, err := ParseArgs(raw, []string{"--status", "--query"}, []string{"--json"})
 	if err != nil {
 		return ExportArgs{}, err
 	}
-	return ExportArgs{
+	ea := ExportArgs{
 		Status: a.String("--status"),
 		JSON:   a.JSON(),
-	}, nil
+	}
+	if a.Has("--query") {
+		ea.Query, err = issue.ParseQuery(a.String("--query"))
+		if err != nil {
+			return ea, err
+		}
+	}
+	return ea, nil
 }
 
 func cmdExport(store *issue.Store, args []string, w Writer, _ *config.Config) (*config.Config, error) {
@@ -75,7 +84,7 @@ func cmdExport(store *issue.Store, args []string, w Writer, _ *config.Config) (*
 		return nil, err
 	}
 
-	filter := issue.Filter{Status: ea.Status}
+	filter := issue.Filter{Status: ea.Status, Query: ea.Query}
 
 	issues, err := store.List(filter)
 	if err != nil {
@@ -98,6 +107,7 @@ func cmdExport(store *issue.Store, args []string, w Writer, _ *config.Config) (*
 			Labels:      nilIfEmpty(iss.Labels),
 			DeferUntil:  iss.DeferUntil,
 			Due:         iss.Due,
+			Fields:      iss.Fields,
 			Blocks:      nilIfEmpty(iss.Blocks),
 			BlockedBy:   nilIfEmpty(iss.BlockedBy),
 		}
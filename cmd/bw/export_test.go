@@ -193,6 +193,50 @@ func TestExportImportWithDue(t *testing.T) {
 	}
 }
 
+func TestExportImportWithFields(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+	schema, _ := issue.ParseFields(map[string]string{"field.severity": "enum:low,high", "field.estimate": "int"})
+	env.Store.Fields = schema
+
+	iss, err := env.Store.Create("Has fields", issue.CreateOpts{Fields: map[string]string{"severity": "high", "estimate": "5"}})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	env.Repo.Commit("create " + iss.ID)
+
+	var buf bytes.Buffer
+	if _, err := cmdExport(env.Store, []string{}, PlainWriter(&buf), nil); err != nil {
+		t.Fatalf("cmdExport: %v", err)
+	}
+	if !strings.Contains(buf.String(), `"fields":{"estimate":"5","severity":"high"}`) {
+		t.Errorf("export missing fields, got: %s", buf.String())
+	}
+
+	env2 := testutil.NewEnv(t)
+	defer env2.Cleanup()
+	tmpFile := env2.Dir + "/fields-export.jsonl"
+	os.WriteFile(tmpFile, buf.Bytes(), 0644)
+
+	// Without the fields declared, the store refuses the import.
+	var buf2 bytes.Buffer
+	if _, err := cmdImport(env2.Store, []string{tmpFile}, PlainWriter(&buf2), nil); err == nil || !strings.Contains(err.Error(), "unknown field") {
+		t.Fatalf("cmdImport without schema: err = %v", err)
+	}
+
+	env2.Store.Fields = schema
+	if _, err := cmdImport(env2.Store, []string{tmpFile}, PlainWriter(&buf2), nil); err != nil {
+		t.Fatalf("cmdImport: %v", err)
+	}
+	got, err := env2.Store.Get(iss.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.Fields["severity"] != "high" || got.Fields["estimate"] != "5" {
+		t.Errorf("fields = %v", got.Fields)
+	}
+}
+
 func TestImportOldFormatDeferUntil(t *testing.T) {
 	env := testutil.NewEnv(t)
 	defer env.Cleanup()
@@ -214,3 +258,23 @@ func TestImportOldFormatDeferUntil(t *testing.T) {
 		t.Errorf("defer_until = %q, want 2027-06-01T00:00:00Z (stored as-is)", iss.DeferUntil)
 	}
 }
+
+func TestCmdExportQuery(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	a, _ := env.Store.Create("Release blocker", issue.CreateOpts{Type: "bug"})
+	env.Store.Label(a.ID, []string{"release"}, nil)
+	env.Store.Create("Other bug", issue.CreateOpts{Type: "bug"})
+	env.Repo.Commit("create issues")
+
+	var buf bytes.Buffer
+	_, err := cmdExport(env.Store, []string{"--query", "label:release"}, PlainWriter(&buf), nil)
+	if err != nil {
+		t.Fatalf("cmdExport: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 || !strings.Contains(lines[0], a.ID) {
+		t.Errorf("export = %q", buf.String())
+	}
+}
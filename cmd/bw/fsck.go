@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/jallum/beadwork/internal/config"
+
+	"github.com/jallum/beadwork/internal/issue"
+)
+
+type FsckArgs struct {
+	Repair bool
+	JSON   bool
+}
+
+func parseFsckArgs(raw []string) (FsckArgs, error) {
+	a, err := ParseArgs(raw, nil, []string{"--repair", "--json"})
+	if err != nil {
+		return FsckArgs{}, err
+	}
+	return FsckArgs{Repair: a.Bool("--repair"), JSON: a.JSON()}, nil
+}
+
+func cmdFsck(store *issue.Store, args []string, w Writer, _ *config.Config) (*config.Config, error) {
+	fa, err := parseFsckArgs(args)
+	if err != nil {
+		return nil, err
+	}
+
+	problems, err := store.Check()
+	if err != nil {
+		return nil, err
+	}
+	repairable := 0
+	for _, p := range problems {
+		if p.Repairable {
+			repairable++
+		}
+	}
+
+	repaired := false
+	if fa.Repair && repairable > 0 {
+		// One commit for every fix; replaying "repair" re-runs it.
+		err := commitWithRetry(store, commitMaxRetries, func() (string, error) {
+			var rerr error
+			problems, rerr = store.Repair()
+			return "repair", rerr
+		})
+		if err != nil {
+			return nil, err
+		}
+		repaired = true
+	}
+
+	if fa.JSON {
+		if problems == nil {
+			problems = []issue.Problem{}
+		}
+		fprintJSON(w, problems)
+	} else {
+		for _, p := range problems {
+			note := ""
+			switch {
+			case repaired && p.Repairable:
+				note = " (repaired)"
+			case !p.Repairable:
+				note = " (cannot repair)"
+			}
+			fmt.Fprintf(w, "%s: %s%s\n", p.Path, p.Problem, note)
+		}
+	}
+
+	unfixed := 0
+	for _, p := range problems {
+		if !repaired || !p.Repairable {
+			unfixed++
+		}
+	}
+	if len(problems) == 0 {
+		if !fa.JSON {
+			fmt.Fprintln(w, "no problems found")
+		}
+		return nil, nil
+	}
+	if repaired && !fa.JSON {
+		fmt.Fprintf(w, "repaired %d problem(s)\n", len(problems)-unfixed)
+	}
+	switch {
+	case unfixed == 0:
+		return nil, nil
+	case !fa.Repair && repairable > 0:
+		return nil, fmt.Errorf("%d problem(s) found; run: bw fsck --repair", unfixed)
+	}
+	return nil, fmt.Errorf("%d problem(s) cannot be repaired automatically", unfixed)
+}
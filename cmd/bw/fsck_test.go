@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/jallum/beadwork/internal/issue"
+	"github.com/jallum/beadwork/internal/testutil"
+)
+
+func TestCmdFsckClean(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	env.Store.Create("Fine", issue.CreateOpts{})
+	env.Repo.Commit("create")
+
+	var buf bytes.Buffer
+	if _, err := cmdFsck(env.Store, nil, PlainWriter(&buf), nil); err != nil {
+		t.Fatalf("cmdFsck: %v", err)
+	}
+	if !strings.Contains(buf.String(), "no problems found") {
+		t.Errorf("output = %q", buf.String())
+	}
+}
+
+func TestCmdFsckReportAndRepair(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	iss, _ := env.Store.Create("Damaged", issue.CreateOpts{})
+	env.Repo.Commit("create")
+	env.Store.FS.WriteFile("status/closed/"+iss.ID, []byte{})
+	env.Repo.Commit("damage")
+
+	var buf bytes.Buffer
+	_, err := cmdFsck(env.Store, nil, PlainWriter(&buf), nil)
+	if err == nil || !strings.Contains(err.Error(), "run: bw fsck --repair") {
+		t.Errorf("err = %v", err)
+	}
+	want := "status/closed/" + iss.ID + ": stray status marker: " + iss.ID + " is open"
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("output = %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	if _, err := cmdFsck(env.Store, []string{"--repair"}, PlainWriter(&buf), nil); err != nil {
+		t.Fatalf("cmdFsck --repair: %v", err)
+	}
+	if !strings.Contains(buf.String(), "(repaired)") || !strings.Contains(buf.String(), "repaired 1 problem(s)") {
+		t.Errorf("output = %q", buf.String())
+	}
+
+	buf.Reset()
+	if _, err := cmdFsck(env.Store, []string{"--json"}, PlainWriter(&buf), nil); err != nil {
+		t.Fatalf("cmdFsck --json: %v", err)
+	}
+	var problems []issue.Problem
+	if err := json.Unmarshal(buf.Bytes(), &problems); err != nil || len(problems) != 0 {
+		t.Errorf("json = %q (%v)", buf.String(), err)
+	}
+}
+
+func TestCmdFsckUnrepairable(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	env.Store.FS.WriteFile("issues/test-bad.json", []byte("{"))
+	env.Repo.Commit("damage")
+
+	var buf bytes.Buffer
+	_, err := cmdFsck(env.Store, []string{"--repair"}, PlainWriter(&buf), nil)
+	if err == nil || !strings.Contains(err.Error(), "cannot be repaired") {
+		t.Errorf("err = %v", err)
+	}
+	if !strings.Contains(buf.String(), "issues/test-bad.json: corrupt JSON") || !strings.Contains(buf.String(), "(cannot repair)") {
+		t.Errorf("output = %q", buf.String())
+	}
+}
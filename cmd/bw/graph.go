@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jallum/beadwork/internal/config"
+
+	"github.com/jallum/beadwork/internal/issue"
+)
+
+var graphFormats = []string{"dot", "mermaid", "json"}
+
+type GraphArgs struct {
+	ID            string // empty graphs every issue
+	Format        string // "dot", "mermaid" or "json"
+	Depth         int
+	IncludeClosed bool
+}
+
+func parseGraphArgs(raw []string) (GraphArgs, error) {
+	a, err := ParseArgs(raw, []string{"--format", "--depth"}, []string{"--include-closed", "--json"})
+	if err != nil {
+		return GraphArgs{}, err
+	}
+	pos := a.Pos()
+	if len(pos) > 1 {
+		return GraphArgs{}, fmt.Errorf("usage: bw graph [<id>]")
+	}
+	ga := GraphArgs{
+		ID:            a.PosFirst(),
+		Format:        a.String("--format"),
+		IncludeClosed: a.Bool("--include-closed"),
+	}
+	if a.JSON() {
+		ga.Format = "json"
+	}
+	switch ga.Format {
+	case "":
+		ga.Format = "dot"
+	case "dot", "mermaid", "json":
+	default:
+		return GraphArgs{}, fmt.Errorf("invalid format %q (expected %s)", ga.Format, strings.Join(graphFormats, ", "))
+	}
+	depth, set, err := a.IntErr("--depth")
+	if err != nil {
+		return GraphArgs{}, err
+	}
+	if set && depth < 1 {
+		return GraphArgs{}, fmt.Errorf("--depth must be at least 1")
+	}
+	if set && ga.ID == "" {
+		return GraphArgs{}, fmt.Errorf("--depth needs an issue ID")
+	}
+	ga.Depth = depth
+	return ga, nil
+}
+
+func cmdGraph(store *issue.Store, args []string, w Writer, _ *config.Config) (*config.Config, error) {
+	ga, err := parseGraphArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	g, err := store.Graph(issue.GraphOpts{Root: ga.ID, Depth: ga.Depth, IncludeClosed: ga.IncludeClosed})
+	if err != nil {
+		return nil, err
+	}
+	switch ga.Format {
+	case "json":
+		fprintJSON(w, g)
+	case "mermaid":
+		writeMermaid(w, store, g)
+	default:
+		writeDot(w, store, g)
+	}
+	return nil, nil
+}
+
+// graphColors are node fills by status; statuses not listed fall back to
+// their category's entry.
+var graphColors = map[string]string{
+	"open":                "#ffffff",
+	"in_progress":         "#d0ebff",
+	issue.CategoryActive:  "#ffffff",
+	issue.CategoryWaiting: "#e9ecef",
+	issue.CategoryDone:    "#d3f9d8",
+}
+
+// graphPriorityColors are node borders by priority, following the colours
+// bw uses for priorities in the terminal.
+var graphPriorityColors = map[int]string{
+	0: "#c92a2a",
+	1: "#e03131",
+	2: "#f08c00",
+	3: "#1098ad",
+	4: "#868e96",
+}
+
+// graphNodeStyle returns the fill, border colour and border width of n.
+func graphNodeStyle(store *issue.Store, n issue.GraphNode) (fill, border string, width int) {
+	fill, ok := graphColors[n.Status]
+	if !ok {
+		fill = graphColors[store.CurrentWorkflow().Category(n.Status)]
+	}
+	border, ok = graphPriorityColors[n.Priority]
+	if !ok {
+		border = graphPriorityColors[4]
+	}
+	width = 1
+	if n.Priority <= 1 {
+		width = 3 - n.Priority
+	}
+	return fill, border, width
+}
+
+func graphLabelTitle(title string) string {
+	return strings.Join(strings.Fields(title), " ")
+}
+
+// writeDot renders g for Graphviz. Blocks edges are solid arrows; parent
+// edges are dashed lines from parent to child.
+func writeDot(w Writer, store *issue.Store, g *issue.Graph) {
+	q := func(s string) string {
+		return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+	}
+	fmt.Fprintln(w, "digraph beadwork {")
+	fmt.Fprintln(w, "  rankdir=LR;")
+	fmt.Fprintln(w, `  node [shape=box, style="rounded,filled", fontname="Helvetica"];`)
+	for _, n := range g.Nodes {
+		fill, border, width := graphNodeStyle(store, n)
+		label := fmt.Sprintf("%s P%d [%s]\n%s", n.ID, n.Priority, n.Status, graphLabelTitle(n.Title))
+		fmt.Fprintf(w, "  %s [label=%s, fillcolor=%q, color=%q, penwidth=%d];\n",
+			q(n.ID), strings.ReplaceAll(q(label), "\n", `\n`), fill, border, width)
+	}
+	for _, e := range g.Edges {
+		attrs := ""
+		if e.Kind == issue.EdgeParent {
+			attrs = " [style=dashed, arrowhead=none]"
+		}
+		fmt.Fprintf(w, "  %s -> %s%s;\n", q(e.From), q(e.To), attrs)
+	}
+	fmt.Fprintln(w, "}")
+}
+
+var mermaidIDRe = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// writeMermaid renders g as a Mermaid flowchart. Blocks edges are arrows;
+// parent edges are dotted lines from parent to child.
+func writeMermaid(w Writer, store *issue.Store, g *issue.Graph) {
+	nid := func(id string) string { return mermaidIDRe.ReplaceAllString(id, "_") }
+	esc := strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;")
+	fmt.Fprintln(w, "flowchart LR")
+	for _, n := range g.Nodes {
+		fmt.Fprintf(w, "  %s[\"%s P%d [%s]<br/>%s\"]\n",
+			nid(n.ID), n.ID, n.Priority, n.Status, esc.Replace(graphLabelTitle(n.Title)))
+	}
+	for _, e := range g.Edges {
+		arrow := "-->"
+		if e.Kind == issue.EdgeParent {
+			arrow = "-.-"
+		}
+		fmt.Fprintf(w, "  %s %s %s\n", nid(e.From), arrow, nid(e.To))
+	}
+	for _, n := range g.Nodes {
+		fill, border, width := graphNodeStyle(store, n)
+		fmt.Fprintf(w, "  style %s fill:%s,stroke:%s,stroke-width:%dpx\n", nid(n.ID), fill, border, width)
+	}
+}
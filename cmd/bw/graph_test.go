@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/jallum/beadwork/internal/issue"
+	"github.com/jallum/beadwork/internal/testutil"
+)
+
+func TestParseGraphArgs(t *testing.T) {
+	ga, err := parseGraphArgs([]string{"bw-1", "--format", "mermaid", "--depth", "2", "--include-closed"})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if ga.ID != "bw-1" || ga.Format != "mermaid" || ga.Depth != 2 || !ga.IncludeClosed {
+		t.Errorf("args = %+v", ga)
+	}
+	if ga, _ := parseGraphArgs(nil); ga.Format != "dot" {
+		t.Errorf("default format = %q, want dot", ga.Format)
+	}
+	if ga, _ := parseGraphArgs([]string{"--json"}); ga.Format != "json" {
+		t.Errorf("--json format = %q, want json", ga.Format)
+	}
+
+	for _, raw := range [][]string{
+		{"--format", "png"},
+		{"bw-1", "--depth", "0"},
+		{"--depth", "2"},
+		{"bw-1", "bw-2"},
+	} {
+		if _, err := parseGraphArgs(raw); err == nil {
+			t.Errorf("parseGraphArgs(%q) should fail", raw)
+		}
+	}
+}
+
+func TestCmdGraphFormats(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	p0 := 0
+	epic, _ := env.Store.Create("Epic \"one\"", issue.CreateOpts{Priority: &p0})
+	a, _ := env.Store.Create("A", issue.CreateOpts{Parent: epic.ID})
+	b, _ := env.Store.Create("B", issue.CreateOpts{})
+	env.Store.Link(a.ID, b.ID)
+	env.Store.Start(a.ID, "")
+	env.Repo.Commit("setup")
+
+	var buf bytes.Buffer
+	if _, err := cmdGraph(env.Store, nil, PlainWriter(&buf), nil); err != nil {
+		t.Fatalf("graph: %v", err)
+	}
+	dot := buf.String()
+	for _, want := range []string{
+		"digraph beadwork {",
+		`"` + a.ID + `" -> "` + b.ID + `";`,
+		`"` + epic.ID + `" -> "` + a.ID + `" [style=dashed, arrowhead=none];`,
+		`Epic \"one\"`,
+		`fillcolor="#d0ebff"`,
+		"penwidth=3",
+	} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("dot output missing %q:\n%s", want, dot)
+		}
+	}
+
+	buf.Reset()
+	if _, err := cmdGraph(env.Store, []string{"--format", "mermaid"}, PlainWriter(&buf), nil); err != nil {
+		t.Fatalf("graph mermaid: %v", err)
+	}
+	mm := buf.String()
+	nid := strings.NewReplacer("-", "_", ".", "_").Replace
+	for _, want := range []string{
+		"flowchart LR",
+		nid(a.ID) + " --> " + nid(b.ID),
+		nid(epic.ID) + " -.- " + nid(a.ID),
+		"Epic #quot;one#quot;",
+		"style " + nid(epic.ID) + " fill:#ffffff,stroke:#c92a2a,stroke-width:3px",
+	} {
+		if !strings.Contains(mm, want) {
+			t.Errorf("mermaid output missing %q:\n%s", want, mm)
+		}
+	}
+
+	buf.Reset()
+	if _, err := cmdGraph(env.Store, []string{b.ID, "--json", "--depth", "1"}, PlainWriter(&buf), nil); err != nil {
+		t.Fatalf("graph json: %v", err)
+	}
+	var g issue.Graph
+	if err := json.Unmarshal(buf.Bytes(), &g); err != nil {
+		t.Fatalf("unmarshal: %v\n%s", err, buf.String())
+	}
+	if len(g.Nodes) != 2 || len(g.Edges) != 1 || g.Edges[0].Kind != issue.EdgeBlocks {
+		t.Errorf("json graph = %+v", g)
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jallum/beadwork/internal/config"
+
+	"github.com/jallum/beadwork/internal/issue"
+	"github.com/jallum/beadwork/internal/repo"
+)
+
+type HeartbeatArgs struct {
+	ID       string
+	Assignee string
+	Lease    time.Duration
+	JSON     bool
+}
+
+func parseHeartbeatArgs(raw []string) (HeartbeatArgs, error) {
+	if len(raw) == 0 {
+		return HeartbeatArgs{}, fmt.Errorf("usage: bw heartbeat <id> [--lease <duration>]")
+	}
+	a, err := ParseArgs(raw[1:], []string{"--assignee", "--lease"}, []string{"--json"})
+	if err != nil {
+		return HeartbeatArgs{}, err
+	}
+	ha := HeartbeatArgs{ID: raw[0], Assignee: a.String("--assignee"), JSON: a.JSON()}
+	if a.Has("--lease") {
+		ha.Lease, err = parseLease(a.String("--lease"))
+		if err != nil {
+			return ha, err
+		}
+	}
+	return ha, nil
+}
+
+func cmdHeartbeat(store *issue.Store, args []string, w Writer, _ *config.Config) (*config.Config, error) {
+	ha, err := parseHeartbeatArgs(args)
+	if err != nil {
+		return nil, err
+	}
+
+	assignee := ha.Assignee
+	if assignee == "" {
+		assignee = store.Committer.(*repo.Repo).UserName()
+	}
+
+	var iss *issue.Issue
+	err = commitWithRetry(store, commitMaxRetries, func() (string, error) {
+		var herr error
+		iss, herr = store.Heartbeat(ha.ID, issue.HeartbeatOpts{Assignee: assignee, Lease: ha.Lease})
+		if herr != nil {
+			return "", herr
+		}
+		return fmt.Sprintf("heartbeat %s assignee=%q lease=%s expires=%s", iss.ID, assignee, iss.Lease.Duration, iss.Lease.Expires), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if ha.JSON {
+		fprintJSON(w, iss)
+	} else {
+		fmt.Fprintf(w, "extended lease on %s until %s\n", iss.ID, iss.Lease.Expires)
+	}
+	return nil, nil
+}
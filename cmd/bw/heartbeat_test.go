@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jallum/beadwork/internal/issue"
+	"github.com/jallum/beadwork/internal/testutil"
+)
+
+func TestCmdHeartbeat(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+	t.Setenv("BW_CLOCK", "2027-03-01T10:00:00Z")
+
+	iss, _ := env.Store.Create("Leased", issue.CreateOpts{})
+	env.Store.Claim(iss.ID, issue.ClaimOpts{Assignee: "alice", Lease: time.Hour})
+	env.Repo.Commit("start " + iss.ID)
+
+	t.Setenv("BW_CLOCK", "2027-03-01T10:30:00Z")
+	var buf bytes.Buffer
+	if _, err := cmdHeartbeat(env.Store, []string{iss.ID, "--assignee", "alice"}, PlainWriter(&buf), nil); err != nil {
+		t.Fatalf("cmdHeartbeat: %v", err)
+	}
+	if !strings.Contains(buf.String(), "extended lease on "+iss.ID+" until 2027-03-01T11:30:00Z") {
+		t.Errorf("output = %q", buf.String())
+	}
+	commits, _ := env.Repo.AllCommits()
+	want := `heartbeat ` + iss.ID + ` assignee="alice" lease=1h expires=2027-03-01T11:30:00Z`
+	if msg := strings.TrimRight(commits[0].Message, "\n"); msg != want {
+		t.Errorf("intent = %q, want %q", msg, want)
+	}
+
+	buf.Reset()
+	if _, err := cmdHeartbeat(env.Store, []string{iss.ID, "--assignee", "bob"}, PlainWriter(&buf), nil); err == nil {
+		t.Error("expected error renewing alice's lease as bob")
+	}
+}
+
+func TestParseHeartbeatArgs(t *testing.T) {
+	ha, err := parseHeartbeatArgs([]string{"bw-1", "--lease", "45m", "--json"})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if ha.ID != "bw-1" || ha.Lease != 45*time.Minute || !ha.JSON {
+		t.Errorf("args = %+v", ha)
+	}
+	if _, err := parseHeartbeatArgs(nil); err == nil {
+		t.Error("expected usage error")
+	}
+}
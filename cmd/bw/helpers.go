@@ -8,6 +8,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/jallum/beadwork/internal/config"
+	"github.com/jallum/beadwork/internal/hooks"
 	"github.com/jallum/beadwork/internal/issue"
 	"github.com/jallum/beadwork/internal/repo"
 )
@@ -23,6 +25,12 @@ func getInitializedStore() (*issue.Store, error) {
 	if err != nil {
 		return nil, err
 	}
+	return openStore(r)
+}
+
+// openStore returns a writable store over an already-open repo, with
+// the repo's workflow and defaults applied.
+func openStore(r *repo.Repo) (*issue.Store, error) {
 	if !r.IsInitialized() {
 		return nil, fmt.Errorf("beadwork not initialized. Run: bw init")
 	}
@@ -33,6 +41,11 @@ func getInitializedStore() (*issue.Store, error) {
 	}
 	store := issue.NewStore(r.TreeFS(), r.Prefix)
 	store.Committer = r
+	store.User = r.UserName()
+	store.IndexPath = r.IndexPath()
+	if err := loadWorkflow(store, r); err != nil {
+		return nil, err
+	}
 	if val, ok := r.GetConfig("default.priority"); ok {
 		if p, err := strconv.Atoi(val); err == nil && p >= 0 {
 			store.DefaultPriority = &p
@@ -43,9 +56,73 @@ func getInitializedStore() (*issue.Store, error) {
 			store.IDRetries = n
 		}
 	}
+	userCfg, _ := config.Load(config.DefaultPath())
+	if h := hooks.Load(userCfg, r.Hooks(), r.RepoDir()); h != nil {
+		store.Hooks = h
+	}
+	return store, nil
+}
+
+// getStoreAt returns a read-only store over the beadwork tree as of the
+// commit named by spec (see repo.ResolveAt). The store has no Committer,
+// so any attempted write fails.
+func getStoreAt(spec string) (*issue.Store, error) {
+	r, err := getRepo()
+	if err != nil {
+		return nil, err
+	}
+	if !r.IsInitialized() {
+		return nil, fmt.Errorf("beadwork not initialized. Run: bw init")
+	}
+	hash, err := r.ResolveAt(spec, bwNow())
+	if err != nil {
+		return nil, fmt.Errorf("--at %s: %w", spec, err)
+	}
+	fs, err := r.TreeFSAt(hash)
+	if err != nil {
+		return nil, err
+	}
+	store := issue.NewStore(fs, r.Prefix)
+	store.User = r.UserName()
+	if err := loadWorkflow(store, r); err != nil {
+		return nil, err
+	}
 	return store, nil
 }
 
+// loadWorkflow applies the repo's status/transition and custom field
+// config to store.
+func loadWorkflow(store *issue.Store, r *repo.Repo) error {
+	wf, err := issue.ParseWorkflow(r.ListConfig())
+	if err != nil {
+		return fmt.Errorf("workflow config: %w", err)
+	}
+	store.Workflow = wf
+	fields, err := issue.ParseFields(r.ListConfig())
+	if err != nil {
+		return fmt.Errorf("field config: %w", err)
+	}
+	store.Fields = fields
+	return nil
+}
+
+// parseFieldArgs parses repeated key=value flag values (--field, --where)
+// into a map. Values are validated later by the store.
+func parseFieldArgs(flag string, vals []string) (map[string]string, error) {
+	if len(vals) == 0 {
+		return nil, nil
+	}
+	fields := make(map[string]string, len(vals))
+	for _, kv := range vals {
+		key, val, ok := strings.Cut(kv, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid %s %q: expected key=value", flag, kv)
+		}
+		fields[key] = val
+	}
+	return fields, nil
+}
+
 func fatal(msg string) {
 	fmt.Fprintf(os.Stderr, "error: %s\n", msg)
 	os.Exit(1)
@@ -104,6 +181,7 @@ var aliases = map[string]string{
 	"-g": "--grep",
 	"-y": "--yes",
 	"-r": "--recursive",
+	"-q": "--query",
 }
 
 // Args holds parsed command-line arguments separated into boolean flags,
@@ -111,6 +189,7 @@ var aliases = map[string]string{
 type Args struct {
 	bools map[string]bool
 	flags map[string]string
+	multi map[string][]string // every value of a repeated flag, in order
 	pos   []string
 }
 
@@ -131,6 +210,7 @@ func ParseArgs(raw []string, valueFlags []string, boolFlags []string) (Args, err
 	a := Args{
 		bools: make(map[string]bool),
 		flags: make(map[string]string),
+		multi: make(map[string][]string),
 	}
 
 	for i := 0; i < len(raw); i++ {
@@ -147,6 +227,7 @@ func ParseArgs(raw []string, valueFlags []string, boolFlags []string) (Args, err
 		if vf[tok] {
 			if i+1 < len(raw) {
 				a.flags[tok] = raw[i+1]
+				a.multi[tok] = append(a.multi[tok], raw[i+1])
 				i++
 			}
 		} else if bf[tok] {
@@ -167,6 +248,9 @@ func (a Args) JSON() bool { return a.bools["--json"] }
 // String returns the value of a key-value flag, or "" if absent.
 func (a Args) String(name string) string { return a.flags[name] }
 
+// Strings returns every value given for a repeatable flag, in order.
+func (a Args) Strings(name string) []string { return a.multi[name] }
+
 // Int returns the parsed int value of a flag, or 0 if absent/invalid.
 func (a Args) Int(name string) int {
 	v, _ := strconv.Atoi(a.flags[name])
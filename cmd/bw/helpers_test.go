@@ -53,6 +53,35 @@ func TestParseArgsValueFlags(t *testing.T) {
 	}
 }
 
+func TestParseArgsRepeatedValueFlag(t *testing.T) {
+	a, err := ParseArgs([]string{"--field", "a=1", "--field", "b=2"}, []string{"--field"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := a.Strings("--field"); len(got) != 2 || got[0] != "a=1" || got[1] != "b=2" {
+		t.Errorf("Strings(--field) = %v, want [a=1 b=2]", got)
+	}
+	if a.String("--field") != "b=2" {
+		t.Errorf("String(--field) = %q, want last value", a.String("--field"))
+	}
+	if a.Strings("--missing") != nil {
+		t.Error("expected nil for absent flag")
+	}
+}
+
+func TestParseFieldArgs(t *testing.T) {
+	got, err := parseFieldArgs("--field", []string{"severity=high", "note=a=b", "estimate="})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["severity"] != "high" || got["note"] != "a=b" || got["estimate"] != "" || len(got) != 3 {
+		t.Errorf("parseFieldArgs = %v", got)
+	}
+	if _, err := parseFieldArgs("--where", []string{"severity"}); err == nil {
+		t.Error("expected error for missing '='")
+	}
+}
+
 func TestParseArgsAliases(t *testing.T) {
 	a, err := ParseArgs([]string{"-p", "2", "-t", "bug", "-a", "alice"}, []string{"--priority", "--type", "--assignee"}, nil)
 	if err != nil {
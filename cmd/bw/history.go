@@ -1,36 +1,43 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
 	"regexp"
 	"strings"
 
+	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/jallum/beadwork/internal/config"
 
 	"github.com/jallum/beadwork/internal/issue"
 	"github.com/jallum/beadwork/internal/repo"
+	"github.com/jallum/beadwork/internal/treefs"
 )
 
 // HistoryArgs holds parsed arguments for the history command.
 type HistoryArgs struct {
 	ID    string
 	Limit int
+	Diff  bool
 	JSON  bool
 }
 
 func parseHistoryArgs(raw []string) (HistoryArgs, error) {
 	if len(raw) == 0 {
-		return HistoryArgs{}, fmt.Errorf("usage: bw history <id> [--limit N] [--json]")
+		return HistoryArgs{}, fmt.Errorf("usage: bw history <id> [--limit N] [--diff] [--json]")
 	}
 	a, err := ParseArgs(raw[1:],
 		[]string{"--limit"},
-		[]string{"--json"},
+		[]string{"--diff", "--json"},
 	)
 	if err != nil {
 		return HistoryArgs{}, err
 	}
 	ha := HistoryArgs{
 		ID:   raw[0],
+		Diff: a.Bool("--diff"),
 		JSON: a.JSON(),
 	}
 	if a.Has("--limit") {
@@ -48,6 +55,9 @@ type commitEntry struct {
 	Author    string   `json:"author"`
 	Intent    string   `json:"intent"`
 	Unblocked []string `json:"unblocked,omitempty"`
+
+	// Changes lists the issue's field changes; only filled in by --diff.
+	Changes []issue.FieldChange `json:"changes,omitempty"`
 }
 
 func firstLine(s string) string {
@@ -92,6 +102,12 @@ func cmdHistory(store *issue.Store, args []string, w Writer, _ *config.Config) (
 					entry.Unblocked = append(entry.Unblocked, m[1])
 				}
 			}
+			if ha.Diff {
+				entry.Changes, err = commitChanges(store.FS, c, iss.ID)
+				if err != nil {
+					return nil, err
+				}
+			}
 			matched = append(matched, entry)
 		}
 	}
@@ -116,6 +132,81 @@ func cmdHistory(store *issue.Store, args []string, w Writer, _ *config.Config) (
 		for _, uid := range e.Unblocked {
 			fmt.Fprintf(w, "  → unblocked %s\n", uid)
 		}
+		w.Push(4)
+		for _, ch := range e.Changes {
+			printFieldChange(w, ch)
+		}
+		w.Pop()
 	}
 	return nil, nil
 }
+
+// commitChanges compares the issue's JSON before and after commit c.
+func commitChanges(fs *treefs.TreeFS, c treefs.CommitInfo, id string) ([]issue.FieldChange, error) {
+	path := "issues/" + id + ".json"
+	after, err := issueAt(fs, c.Hash, path)
+	if err != nil {
+		return nil, err
+	}
+	var before *issue.Issue
+	if c.Parent != "" {
+		if before, err = issueAt(fs, c.Parent, path); err != nil {
+			return nil, err
+		}
+	}
+	return issue.Diff(before, after), nil
+}
+
+// issueAt reads the issue at path as of the given commit. A missing file
+// yields nil: the issue did not exist yet, or had been deleted.
+func issueAt(fs *treefs.TreeFS, hash, path string) (*issue.Issue, error) {
+	data, err := fs.ReadFileAt(plumbing.NewHash(hash), path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s at %.7s: %w", path, hash, err)
+	}
+	var iss issue.Issue
+	if err := json.Unmarshal(data, &iss); err != nil {
+		return nil, fmt.Errorf("parsing %s at %.7s: %w", path, hash, err)
+	}
+	return &iss, nil
+}
+
+func printFieldChange(w Writer, ch issue.FieldChange) {
+	switch {
+	case ch.Field == "description":
+		fmt.Fprintln(w, "description:")
+		w.Push(2)
+		for _, line := range strings.Split(strings.TrimSuffix(ch.Diff, "\n"), "\n") {
+			fmt.Fprintln(w, line)
+		}
+		w.Pop()
+	case ch.Field == "comments":
+		for _, c := range ch.Added {
+			fmt.Fprintf(w, "comment added: %s\n", c)
+		}
+		for _, c := range ch.Removed {
+			fmt.Fprintf(w, "comment removed: %s\n", c)
+		}
+	case len(ch.Added) > 0 || len(ch.Removed) > 0:
+		var parts []string
+		for _, v := range ch.Added {
+			parts = append(parts, "+"+v)
+		}
+		for _, v := range ch.Removed {
+			parts = append(parts, "-"+v)
+		}
+		fmt.Fprintf(w, "%s: %s\n", ch.Field, strings.Join(parts, " "))
+	default:
+		fmt.Fprintf(w, "%s: %s → %s\n", ch.Field, orNone(ch.Old), orNone(ch.New))
+	}
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+	return s
+}
@@ -67,6 +67,16 @@ func TestParseHistoryArgsWithAll(t *testing.T) {
 	}
 }
 
+func TestParseHistoryArgsWithDiff(t *testing.T) {
+	ha, err := parseHistoryArgs([]string{"bw-1234", "--diff"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ha.Diff {
+		t.Error("expected Diff = true")
+	}
+}
+
 func TestParseHistoryArgsMissingID(t *testing.T) {
 	_, err := parseHistoryArgs([]string{})
 	if err == nil {
@@ -278,6 +288,90 @@ func TestCmdHistoryNonexistentID(t *testing.T) {
 	}
 }
 
+func TestCmdHistoryDiff(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	iss, err := env.Store.Create("Old title", issue.CreateOpts{Description: "one\ntwo\nthree"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	env.Repo.Commit("create " + iss.ID)
+
+	title := "New title"
+	desc := "one\n2\nthree"
+	if _, err := env.Store.Update(iss.ID, issue.UpdateOpts{Title: &title, Description: &desc}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	env.Repo.Commit("update " + iss.ID)
+
+	if _, err := env.Store.Label(iss.ID, []string{"bug"}, nil); err != nil {
+		t.Fatalf("Label: %v", err)
+	}
+	env.Repo.Commit("label " + iss.ID + " +bug")
+
+	if _, err := env.Store.Comment(iss.ID, "looked into it", "agent"); err != nil {
+		t.Fatalf("Comment: %v", err)
+	}
+	env.Repo.Commit("comment " + iss.ID)
+
+	var buf bytes.Buffer
+	if _, err := cmdHistory(env.Store, []string{iss.ID, "--diff"}, PlainWriter(&buf), nil); err != nil {
+		t.Fatalf("cmdHistory: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"title: (none) → Old title",
+		"title: Old title → New title",
+		"-two",
+		"+2",
+		"labels: +bug",
+		"comment added: agent: looked into it",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "updated_at") {
+		t.Errorf("output should not report updated_at:\n%s", out)
+	}
+}
+
+func TestCmdHistoryDiffJSON(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	iss, err := env.Store.Create("Diff JSON", issue.CreateOpts{})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	env.Repo.Commit("create " + iss.ID)
+	if _, err := env.Store.Label(iss.ID, []string{"a", "b"}, nil); err != nil {
+		t.Fatalf("Label: %v", err)
+	}
+	env.Repo.Commit("label " + iss.ID + " +a +b")
+	if _, err := env.Store.Label(iss.ID, nil, []string{"a"}); err != nil {
+		t.Fatalf("Label: %v", err)
+	}
+	env.Repo.Commit("label " + iss.ID + " -a")
+
+	var buf bytes.Buffer
+	if _, err := cmdHistory(env.Store, []string{iss.ID, "--diff", "--json"}, PlainWriter(&buf), nil); err != nil {
+		t.Fatalf("cmdHistory: %v", err)
+	}
+	var entries []commitEntry
+	if err := json.Unmarshal(buf.Bytes(), &entries); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	if len(entries) != 3 {
+		t.Fatalf("entries = %d, want 3", len(entries))
+	}
+	last := entries[2].Changes
+	if len(last) != 1 || last[0].Field != "labels" || len(last[0].Removed) != 1 || last[0].Removed[0] != "a" {
+		t.Errorf("last changes = %+v, want labels -a", last)
+	}
+}
+
 func nonEmptyLines(s string) []string {
 	var lines []string
 	for _, line := range strings.Split(strings.TrimSpace(s), "\n") {
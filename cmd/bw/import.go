@@ -19,22 +19,23 @@ type importComment struct {
 }
 
 type importRecord struct {
-	ID           string          `json:"id"`
-	Title        string          `json:"title"`
-	Description  string          `json:"description"`
-	Status       string          `json:"status"`
-	Priority     *int            `json:"priority"`
-	IssueType    string          `json:"issue_type"`
-	Owner        string          `json:"owner"`
-	CreatedAt    string          `json:"created_at"`
-	UpdatedAt    string          `json:"updated_at"`
-	ClosedAt     string          `json:"closed_at"`
-	CloseReason  string          `json:"close_reason"`
-	Labels       []string        `json:"labels"`
-	DeferUntil   string          `json:"defer_until"`
-	Due          string          `json:"due"`
-	Dependencies []beadsDep      `json:"dependencies"`
-	Comments     []importComment `json:"comments"`
+	ID           string            `json:"id"`
+	Title        string            `json:"title"`
+	Description  string            `json:"description"`
+	Status       string            `json:"status"`
+	Priority     *int              `json:"priority"`
+	IssueType    string            `json:"issue_type"`
+	Owner        string            `json:"owner"`
+	CreatedAt    string            `json:"created_at"`
+	UpdatedAt    string            `json:"updated_at"`
+	ClosedAt     string            `json:"closed_at"`
+	CloseReason  string            `json:"close_reason"`
+	Labels       []string          `json:"labels"`
+	DeferUntil   string            `json:"defer_until"`
+	Due          string            `json:"due"`
+	Fields       map[string]string `json:"fields"`
+	Dependencies []beadsDep        `json:"dependencies"`
+	Comments     []importComment   `json:"comments"`
 }
 
 type ImportArgs struct {
@@ -154,6 +155,7 @@ func cmdImport(store *issue.Store, args []string, w Writer, _ *config.Config) (*
 			CloseReason: rec.CloseReason,
 			DeferUntil:  rec.DeferUntil,
 			Due:         rec.Due,
+			Fields:      rec.Fields,
 			Labels:      labels,
 			Blocks:      []string{},
 			BlockedBy:   []string{},
@@ -217,7 +219,7 @@ func cmdImport(store *issue.Store, args []string, w Writer, _ *config.Config) (*
 	}
 	fmt.Fprintf(w, "imported %d issues", len(toImport))
 	parts := []string{}
-	for _, s := range []string{"open", "in_progress", "deferred", "closed"} {
+	for _, s := range store.CurrentWorkflow().Names() {
 		if c := counts[s]; c > 0 {
 			parts = append(parts, fmt.Sprintf("%d %s", c, s))
 		}
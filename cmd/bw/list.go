@@ -17,19 +17,21 @@ type ListArgs struct {
 	Label    string
 	Grep     string
 	Parent   string
+	Where    map[string]string
+	Query    *issue.Query
 	Limit    int
 	LimitSet bool
 	All      bool
 	Deferred bool
 	Overdue  bool
+	Deleted  bool
 	JSON     bool
 }
 
+var listValueFlags = []string{"--status", "--assignee", "--priority", "--type", "--label", "--limit", "--grep", "--parent", "--where", "--query"}
+
 func parseListArgs(raw []string) (ListArgs, error) {
-	a, err := ParseArgs(raw,
-		[]string{"--status", "--assignee", "--priority", "--type", "--label", "--limit", "--grep", "--parent"},
-		[]string{"--all", "--deferred", "--overdue", "--json"},
-	)
+	a, err := ParseArgs(raw, listValueFlags, []string{"--all", "--deferred", "--overdue", "--deleted", "--json"})
 	if err != nil {
 		return ListArgs{}, err
 	}
@@ -43,6 +45,7 @@ func parseListArgs(raw []string) (ListArgs, error) {
 		All:      a.Bool("--all"),
 		Deferred: a.Bool("--deferred"),
 		Overdue:  a.Bool("--overdue"),
+		Deleted:  a.Bool("--deleted"),
 		JSON:     a.JSON(),
 		Limit:    10,
 	}
@@ -53,6 +56,16 @@ func parseListArgs(raw []string) (ListArgs, error) {
 		}
 		la.Priority = &p
 	}
+	la.Where, err = parseFieldArgs("--where", a.Strings("--where"))
+	if err != nil {
+		return la, err
+	}
+	if a.Has("--query") {
+		la.Query, err = issue.ParseQuery(a.String("--query"))
+		if err != nil {
+			return la, err
+		}
+	}
 	if a.Has("--limit") {
 		la.Limit = a.Int("--limit")
 		la.LimitSet = true
@@ -61,11 +74,19 @@ func parseListArgs(raw []string) (ListArgs, error) {
 }
 
 func cmdList(store *issue.Store, args []string, w Writer, _ *config.Config) (*config.Config, error) {
+	args, err := expandViews(store, args)
+	if err != nil {
+		return nil, err
+	}
 	la, err := parseListArgs(args)
 	if err != nil {
 		return nil, err
 	}
 
+	if la.Deleted {
+		return listDeleted(store, la, w)
+	}
+
 	filter := issue.Filter{
 		Status:   la.Status,
 		Assignee: la.Assignee,
@@ -74,6 +95,8 @@ func cmdList(store *issue.Store, args []string, w Writer, _ *config.Config) (*co
 		Label:    la.Label,
 		Grep:     la.Grep,
 		Parent:   la.Parent,
+		Fields:   la.Where,
+		Query:    la.Query,
 	}
 
 	limit := la.Limit
@@ -81,10 +104,12 @@ func cmdList(store *issue.Store, args []string, w Writer, _ *config.Config) (*co
 	// Defaults: open status, limit 10. --all overrides both.
 	// --deferred overrides status to "deferred".
 	// --overdue filters to overdue issues across actionable statuses.
+	// A --query that names a status picks statuses itself.
 	if la.Overdue {
 		filter.Overdue = true
 		if la.Status == "" {
-			filter.Statuses = []string{"open", "in_progress", "deferred"}
+			wf := store.CurrentWorkflow()
+			filter.Statuses = append(wf.NamesIn(issue.CategoryActive), wf.NamesIn(issue.CategoryWaiting)...)
 			filter.IncludeExpiredDeferred = true
 		}
 	} else if la.Deferred {
@@ -93,8 +118,8 @@ func cmdList(store *issue.Store, args []string, w Writer, _ *config.Config) (*co
 		if !la.LimitSet {
 			limit = 0
 		}
-	} else if la.Status == "" {
-		filter.Statuses = []string{"open", "in_progress"}
+	} else if la.Status == "" && !(la.Query != nil && la.Query.Mentions("status")) {
+		filter.Statuses = store.CurrentWorkflow().NamesIn(issue.CategoryActive)
 		filter.IncludeExpiredDeferred = true
 	}
 
@@ -128,3 +153,36 @@ func cmdList(store *issue.Store, args []string, w Writer, _ *config.Config) (*co
 	}
 	return nil, nil
 }
+
+// listDeleted lists the issues bw undelete can restore, most recently
+// deleted first. Only --limit and --all apply.
+func listDeleted(store *issue.Store, la ListArgs, w Writer) (*config.Config, error) {
+	deleted, err := store.Deleted()
+	if err != nil {
+		return nil, err
+	}
+	limit := la.Limit
+	if la.All && !la.LimitSet {
+		limit = 0
+	}
+	shown := deleted
+	if limit > 0 && len(shown) > limit {
+		shown = shown[:limit]
+	}
+
+	if la.JSON {
+		fprintJSON(w, shown)
+		return nil, nil
+	}
+	if len(deleted) == 0 {
+		fmt.Fprintln(w, "no deleted issues found")
+		return nil, nil
+	}
+	for _, d := range shown {
+		fmt.Fprintf(w, "%s  %s\n", md.IssueOneLiner(d.Issue), w.Style("deleted "+d.DeletedAt[:10], Dim))
+	}
+	if len(deleted) > len(shown) {
+		fmt.Fprintf(w, "... and %d more (use --limit or --all)\n", len(deleted)-len(shown))
+	}
+	return nil, nil
+}
@@ -297,3 +297,46 @@ func TestCmdListFilterByParent(t *testing.T) {
 		t.Errorf("--parent should NOT show the epic itself: %q", out)
 	}
 }
+
+func TestCmdListQuery(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	p0 := 0
+	urgent, _ := env.Store.Create("Urgent bug", issue.CreateOpts{Type: "bug", Priority: &p0})
+	env.Store.Create("Routine task", issue.CreateOpts{Type: "task"})
+	done, _ := env.Store.Create("Finished bug", issue.CreateOpts{Type: "bug", Priority: &p0})
+	env.Store.Close(done.ID, "")
+	env.Repo.Commit("create issues")
+
+	var buf bytes.Buffer
+	_, err := cmdList(env.Store, []string{"--query", "type:bug priority<=1"}, PlainWriter(&buf), nil)
+	if err != nil {
+		t.Fatalf("cmdList: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, urgent.ID) || strings.Contains(out, "Routine") || strings.Contains(out, done.ID) {
+		t.Errorf("default status scope should still apply: %q", out)
+	}
+
+	// A query that names a status widens the default scope.
+	buf.Reset()
+	_, err = cmdList(env.Store, []string{"-q", "type:bug (status:open OR status:closed)"}, PlainWriter(&buf), nil)
+	if err != nil {
+		t.Fatalf("cmdList: %v", err)
+	}
+	if !strings.Contains(buf.String(), urgent.ID) || !strings.Contains(buf.String(), done.ID) {
+		t.Errorf("expected open and closed bugs: %q", buf.String())
+	}
+}
+
+func TestCmdListQueryError(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	var buf bytes.Buffer
+	_, err := cmdList(env.Store, []string{"--query", "status:open prio<2"}, PlainWriter(&buf), nil)
+	if err == nil || !strings.Contains(err.Error(), `"prio<2"`) {
+		t.Errorf("err = %v, want it to name the bad token", err)
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/jallum/beadwork/internal/config"
+
+	"github.com/jallum/beadwork/internal/issue"
+	"github.com/jallum/beadwork/internal/repo"
+)
+
+// LogArgs holds parsed arguments for the log command.
+type LogArgs struct {
+	ID    string
+	Limit int
+	JSON  bool
+}
+
+func parseLogArgs(raw []string) (LogArgs, error) {
+	if len(raw) == 0 {
+		return LogArgs{}, fmt.Errorf("usage: bw log <id> [--limit N] [--json]")
+	}
+	a, err := ParseArgs(raw[1:], []string{"--limit"}, []string{"--json"})
+	if err != nil {
+		return LogArgs{}, err
+	}
+	la := LogArgs{ID: raw[0], JSON: a.JSON()}
+	if a.Has("--limit") {
+		la.Limit = a.Int("--limit")
+	}
+	return la, nil
+}
+
+type logEntry struct {
+	repo.CodeCommit
+	Stat repo.CommitStat `json:"stat"`
+}
+
+func cmdLog(store *issue.Store, args []string, w Writer, _ *config.Config) (*config.Config, error) {
+	la, err := parseLogArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	iss, err := store.Get(la.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	r := store.Committer.(*repo.Repo)
+	commits, err := r.CodeCommits(iss.ID)
+	if err != nil {
+		return nil, fmt.Errorf("scanning commits: %w", err)
+	}
+	if la.Limit > 0 && len(commits) > la.Limit {
+		commits = commits[:la.Limit]
+	}
+	hashes := make([]string, len(commits))
+	for i, c := range commits {
+		hashes[i] = c.Hash
+	}
+	stats, err := r.CommitStats(hashes)
+	if err != nil {
+		return nil, fmt.Errorf("reading commit stats: %w", err)
+	}
+	entries := make([]logEntry, len(commits))
+	for i, c := range commits {
+		entries[i] = logEntry{CodeCommit: c, Stat: stats[c.Hash]}
+	}
+
+	if la.JSON {
+		fprintJSON(w, entries)
+		return nil, nil
+	}
+	if len(entries) == 0 {
+		fmt.Fprintf(w, "no commits mention %s\n", iss.ID)
+		return nil, nil
+	}
+	for _, e := range entries {
+		subject := e.Subject
+		for _, id := range e.Closes {
+			if id == iss.ID {
+				subject += " " + w.Style("(closes)", Green)
+				break
+			}
+		}
+		fmt.Fprintf(w, "%s  %s  %s  %s\n", w.Style(shortHash(e.Hash), Yellow), e.Time.Format("2006-01-02"), e.Author, subject)
+		w.Push(2)
+		fmt.Fprintf(w, "%s\n", w.Style(formatCommitStat(e.Stat), Dim))
+		w.Pop()
+	}
+	return nil, nil
+}
+
+func formatCommitStat(st repo.CommitStat) string {
+	files := "files"
+	if st.Files == 1 {
+		files = "file"
+	}
+	return fmt.Sprintf("%d %s changed, +%d -%d", st.Files, files, st.Insertions, st.Deletions)
+}
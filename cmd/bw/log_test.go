@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/jallum/beadwork/internal/issue"
+	"github.com/jallum/beadwork/internal/testutil"
+)
+
+func TestParseLogArgs(t *testing.T) {
+	la, err := parseLogArgs([]string{"bw-1234", "--limit", "5", "--json"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if la.ID != "bw-1234" || la.Limit != 5 || !la.JSON {
+		t.Errorf("args = %+v", la)
+	}
+	if _, err := parseLogArgs(nil); err == nil {
+		t.Error("parseLogArgs(nil): expected error")
+	}
+}
+
+func TestCmdLog(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	iss, _ := env.Store.Create("Widget", issue.CreateOpts{})
+	env.Repo.Commit("create " + iss.ID)
+	env.CodeCommit("widget.go", "package widget\n", "Start the widget for "+iss.ID)
+	env.CodeCommit("widget.go", "package widget\n\nvar Ready = true\n", "Finish the widget\n\nCloses: "+iss.ID)
+	env.CodeCommit("other.go", "package other\n", "Unrelated change")
+
+	var buf bytes.Buffer
+	if _, err := cmdLog(env.Store, []string{iss.ID}, PlainWriter(&buf), nil); err != nil {
+		t.Fatalf("cmdLog: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "Finish the widget (closes)") {
+		t.Errorf("missing closing commit: %q", out)
+	}
+	if !strings.Contains(out, "Start the widget for "+iss.ID) {
+		t.Errorf("missing mentioning commit: %q", out)
+	}
+	if strings.Contains(out, "Unrelated") {
+		t.Errorf("unrelated commit listed: %q", out)
+	}
+	if !strings.Contains(out, "1 file changed, +2 -0") {
+		t.Errorf("missing stat line: %q", out)
+	}
+	if strings.Index(out, "Finish") > strings.Index(out, "Start") {
+		t.Errorf("commits should be newest first: %q", out)
+	}
+}
+
+func TestCmdLogJSON(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	iss, _ := env.Store.Create("Widget", issue.CreateOpts{})
+	env.Repo.Commit("create " + iss.ID)
+	env.CodeCommit("a.go", "package a\n", "First pass at "+iss.ID)
+	env.CodeCommit("b.go", "package b\n", "Second pass at "+iss.ID)
+
+	var buf bytes.Buffer
+	if _, err := cmdLog(env.Store, []string{iss.ID, "--limit", "1", "--json"}, PlainWriter(&buf), nil); err != nil {
+		t.Fatalf("cmdLog: %v", err)
+	}
+	var got []logEntry
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("JSON parse: %v\n%s", err, buf.String())
+	}
+	if len(got) != 1 {
+		t.Fatalf("got %d entries, want 1 with --limit", len(got))
+	}
+	if got[0].Stat.Files != 1 || got[0].Stat.Insertions != 1 {
+		t.Errorf("Stat = %+v", got[0].Stat)
+	}
+}
+
+func TestCmdLogNone(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	iss, _ := env.Store.Create("Quiet", issue.CreateOpts{})
+	env.Repo.Commit("create " + iss.ID)
+
+	var buf bytes.Buffer
+	if _, err := cmdLog(env.Store, []string{iss.ID}, PlainWriter(&buf), nil); err != nil {
+		t.Fatalf("cmdLog: %v", err)
+	}
+	if !strings.Contains(buf.String(), "no commits mention "+iss.ID) {
+		t.Errorf("output = %q", buf.String())
+	}
+}
@@ -41,9 +41,10 @@ func resolveRenderMode(args []string) string {
 
 func main() {
 	var w Writer
-	switch resolveRenderMode(os.Args) {
+	render := resolveRenderMode(os.Args)
+	width := 80
+	switch render {
 	case "tty":
-		width := 80
 		if term.IsTerminal(int(os.Stdout.Fd())) {
 			width, _, _ = term.GetSize(int(os.Stdout.Fd()))
 		}
@@ -83,6 +84,14 @@ func main() {
 		globalDryRun = true
 	}
 
+	if hasFlag(args, "--at") {
+		if _, ok := flagValue(args, "--at"); !ok {
+			fatal("--at requires a commit or time")
+		}
+	}
+	atSpec, _ := flagValue(args, "--at")
+	args, _ = removeFlagValue(args, "--at")
+
 	switch cmd {
 	case "--version", "-v":
 		fmt.Fprintln(w, "bw "+version)
@@ -109,8 +118,33 @@ func main() {
 	// that repo. Never overrides an explicit -C.
 	resolveCrossRepo(cfg, args)
 
+	if atSpec != "" && !c.ReadOnly {
+		fatal(fmt.Sprintf("bw %s cannot be used with --at: point-in-time views are read-only", c.Name))
+	}
+
+	// Hand the command to a running bw serve for this repo, if any.
+	if c.NeedsStore && !c.Local && atSpec == "" {
+		res, ok, err := forwardToServer(forwardDir(), append([]string{c.Name}, args...), render, width, dryRun)
+		if err != nil {
+			fatal(err.Error())
+		}
+		if ok {
+			fmt.Fprint(os.Stdout, res.Output)
+			if res.Error != "" {
+				fatal(res.Error)
+			}
+			return
+		}
+	}
+
 	var store *issue.Store
-	if c.NeedsStore {
+	if c.NeedsStore && atSpec != "" {
+		var err error
+		store, err = getStoreAt(atSpec)
+		if err != nil {
+			fatal(err.Error())
+		}
+	} else if c.NeedsStore {
 		var err error
 		store, err = getInitializedStore()
 		if err != nil {
@@ -122,6 +156,9 @@ func main() {
 
 	originalCfg := cfg
 
+	if store != nil {
+		w = withStatusIcons(w, store.CurrentWorkflow())
+	}
 	newCfg, err := c.Run(store, args, w, cfg)
 	if err != nil {
 		fatal(err.Error())
@@ -130,7 +167,7 @@ func main() {
 		cfg = newCfg
 	}
 
-	if store != nil && registry.Auto(cfg) {
+	if store != nil && store.Committer != nil && registry.Auto(cfg) {
 		r := store.Committer.(*repo.Repo)
 		cfg = registry.Register(cfg, r.RepoDir())
 	}
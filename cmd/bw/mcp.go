@@ -0,0 +1,281 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/jallum/beadwork/internal/config"
+
+	"github.com/jallum/beadwork/internal/issue"
+)
+
+// mcpStdin and mcpStdout carry the protocol; tests swap them for pipes.
+var (
+	mcpStdin  io.Reader = os.Stdin
+	mcpStdout io.Writer = os.Stdout
+)
+
+// mcpProtocolVersion is offered when the client does not ask for one.
+const mcpProtocolVersion = "2025-06-18"
+
+// mcpToolNames lists the commands exposed as MCP tools. Their schemas
+// are derived from the Command definitions, so a new flag shows up in
+// the tool without further wiring.
+var mcpToolNames = []string{"create", "show", "list", "ready", "blocked", "start", "close", "comment", "dep", "defer"}
+
+type mcpTool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	InputSchema map[string]any `json:"inputSchema"`
+}
+
+type mcpContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type mcpToolResult struct {
+	Content []mcpContent `json:"content"`
+	IsError bool         `json:"isError,omitempty"`
+}
+
+func cmdMCP(store *issue.Store, args []string, _ Writer, _ *config.Config) (*config.Config, error) {
+	if _, err := ParseArgs(args, nil, nil); err != nil {
+		return nil, err
+	}
+	return nil, serveMCP(store, mcpStdin, mcpStdout)
+}
+
+// serveMCP answers newline-delimited JSON-RPC messages from r until it
+// reaches EOF. Requests are handled one at a time.
+func serveMCP(store *issue.Store, r io.Reader, w io.Writer) error {
+	tools := mcpTools()
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	enc := json.NewEncoder(w)
+	for sc.Scan() {
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var req rpcRequest
+		if err := json.Unmarshal(line, &req); err != nil {
+			enc.Encode(rpcResponse{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &rpcError{Code: rpcParseError, Message: "parse error: " + err.Error()}})
+			continue
+		}
+		result, rerr := handleMCP(store, tools, req)
+		if len(req.ID) == 0 {
+			continue // notification: no reply
+		}
+		resp := rpcResponse{JSONRPC: "2.0", ID: req.ID, Result: result, Error: rerr}
+		if err := enc.Encode(resp); err != nil {
+			return err
+		}
+	}
+	return sc.Err()
+}
+
+func handleMCP(store *issue.Store, tools []mcpTool, req rpcRequest) (any, *rpcError) {
+	switch req.Method {
+	case "initialize":
+		var p struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		}
+		json.Unmarshal(req.Params, &p)
+		if p.ProtocolVersion == "" {
+			p.ProtocolVersion = mcpProtocolVersion
+		}
+		return map[string]any{
+			"protocolVersion": p.ProtocolVersion,
+			"capabilities":    map[string]any{"tools": map[string]any{}},
+			"serverInfo":      map[string]any{"name": "bw", "version": version},
+		}, nil
+	case "ping":
+		return map[string]any{}, nil
+	case "tools/list":
+		return map[string]any{"tools": tools}, nil
+	case "tools/call":
+		var p struct {
+			Name      string         `json:"name"`
+			Arguments map[string]any `json:"arguments"`
+		}
+		if err := json.Unmarshal(req.Params, &p); err != nil {
+			return nil, &rpcError{Code: rpcInvalidParams, Message: "invalid params: " + err.Error()}
+		}
+		c := commandMap[p.Name]
+		if c == nil || !slices.Contains(mcpToolNames, p.Name) {
+			return nil, &rpcError{Code: rpcInvalidParams, Message: "unknown tool: " + p.Name}
+		}
+		return callMCPTool(store, c, p.Arguments), nil
+	}
+	if strings.HasPrefix(req.Method, "notifications/") {
+		return nil, nil
+	}
+	return nil, &rpcError{Code: rpcMethodNotFound, Message: "method not found: " + req.Method}
+}
+
+// callMCPTool runs c with arguments translated to its command line.
+// Command errors become tool errors rather than protocol errors, so the
+// agent sees them.
+func callMCPTool(store *issue.Store, c *Command, arguments map[string]any) mcpToolResult {
+	argv, err := mcpArgv(c, arguments)
+	if err == nil {
+		// Pick up commits made by other processes since the last call.
+		err = store.Refresh()
+	}
+	var buf bytes.Buffer
+	if err == nil {
+		_, err = c.Run(store, argv, withStatusIcons(PlainWriter(&buf), store.CurrentWorkflow()), nil)
+	}
+	if err != nil {
+		return mcpToolResult{Content: []mcpContent{{Type: "text", Text: err.Error()}}, IsError: true}
+	}
+	return mcpToolResult{Content: []mcpContent{{Type: "text", Text: strings.TrimRight(buf.String(), "\n")}}}
+}
+
+func mcpTools() []mcpTool {
+	var tools []mcpTool
+	for _, name := range mcpToolNames {
+		c := commandMap[name]
+		desc := c.Description
+		if desc == "" {
+			desc = c.Summary
+		}
+		tools = append(tools, mcpTool{Name: c.Name, Description: desc, InputSchema: mcpSchema(c)})
+	}
+	return tools
+}
+
+// mcpParam names the tool argument for a positional: "<id>" becomes "id"
+// and a literal choice such as "add|remove" becomes "action".
+func mcpParam(p Positional) (name string, choices []string) {
+	if strings.HasPrefix(p.Name, "<") {
+		return strings.TrimSuffix(strings.Trim(p.Name, "<>"), "..."), nil
+	}
+	return "action", strings.Split(p.Name, "|")
+}
+
+// mcpFlags returns the flags exposed as tool arguments. --json is
+// always passed instead, and hidden flags stay hidden.
+func mcpFlags(c *Command) []Flag {
+	var flags []Flag
+	for _, f := range c.Flags {
+		if !f.Hidden && f.Long != "--json" {
+			flags = append(flags, f)
+		}
+	}
+	return flags
+}
+
+func mcpSchema(c *Command) map[string]any {
+	props := map[string]any{}
+	required := []string{}
+	for _, p := range c.Positionals {
+		name, choices := mcpParam(p)
+		prop := map[string]any{"type": "string", "description": p.Help}
+		if choices != nil {
+			prop["enum"] = choices
+		}
+		props[name] = prop
+		if p.Required {
+			required = append(required, name)
+		}
+	}
+	for _, f := range mcpFlags(c) {
+		var prop map[string]any
+		switch {
+		case f.Value == "":
+			prop = map[string]any{"type": "boolean"}
+		case f.Value == "N":
+			prop = map[string]any{"type": "integer"}
+		case strings.Contains(f.Help, "repeatable"):
+			prop = map[string]any{"type": "array", "items": map[string]any{"type": "string"}}
+		default:
+			prop = map[string]any{"type": "string"}
+		}
+		prop["description"] = f.Help
+		props[strings.TrimPrefix(f.Long, "--")] = prop
+	}
+	return map[string]any{"type": "object", "properties": props, "required": required}
+}
+
+// mcpArgv turns tool arguments into the argument list c.Run expects.
+func mcpArgv(c *Command, arguments map[string]any) ([]string, error) {
+	known := map[string]bool{}
+	var argv []string
+	for _, p := range c.Positionals {
+		name, _ := mcpParam(p)
+		known[name] = true
+		v, ok := arguments[name]
+		if !ok {
+			if p.Required {
+				return nil, fmt.Errorf("missing required argument %q", name)
+			}
+			continue
+		}
+		s, err := mcpString(name, v)
+		if err != nil {
+			return nil, err
+		}
+		argv = append(argv, s)
+	}
+	for _, f := range mcpFlags(c) {
+		name := strings.TrimPrefix(f.Long, "--")
+		known[name] = true
+		v, ok := arguments[name]
+		if !ok || v == nil {
+			continue
+		}
+		if f.Value == "" {
+			b, ok := v.(bool)
+			if !ok {
+				return nil, fmt.Errorf("argument %q must be a boolean", name)
+			}
+			if b {
+				argv = append(argv, f.Long)
+			}
+			continue
+		}
+		values, isList := v.([]any)
+		if !isList {
+			values = []any{v}
+		}
+		for _, item := range values {
+			s, err := mcpString(name, item)
+			if err != nil {
+				return nil, err
+			}
+			argv = append(argv, f.Long, s)
+		}
+	}
+	for name := range arguments {
+		if !known[name] {
+			return nil, fmt.Errorf("unknown argument %q", name)
+		}
+	}
+	for _, f := range c.Flags {
+		if f.Long == "--json" {
+			argv = append(argv, "--json")
+		}
+	}
+	return argv, nil
+}
+
+func mcpString(name string, v any) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case float64:
+		if v != float64(int64(v)) {
+			return "", fmt.Errorf("argument %q must be a whole number", name)
+		}
+		return fmt.Sprintf("%d", int64(v)), nil
+	}
+	return "", fmt.Errorf("argument %q must be a string", name)
+}
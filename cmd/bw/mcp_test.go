@@ -0,0 +1,248 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jallum/beadwork/internal/issue"
+	"github.com/jallum/beadwork/internal/testutil"
+)
+
+// mcpClient drives serveMCP over in-process pipes, the way an agent's
+// MCP client drives bw mcp over stdio.
+type mcpClient struct {
+	t      *testing.T
+	in     *io.PipeWriter
+	out    *bufio.Scanner
+	nextID int
+	done   chan error
+}
+
+func newMCPClient(t *testing.T, store *issue.Store) *mcpClient {
+	t.Helper()
+	reqR, reqW := io.Pipe()
+	respR, respW := io.Pipe()
+	c := &mcpClient{t: t, in: reqW, out: bufio.NewScanner(respR), done: make(chan error, 1)}
+	go func() {
+		err := serveMCP(store, reqR, respW)
+		respW.Close()
+		c.done <- err
+	}()
+	t.Cleanup(func() {
+		reqW.Close()
+		if err := <-c.done; err != nil {
+			t.Errorf("serveMCP: %v", err)
+		}
+	})
+	return c
+}
+
+func (c *mcpClient) send(msg map[string]any) {
+	c.t.Helper()
+	data, _ := json.Marshal(msg)
+	if _, err := c.in.Write(append(data, '\n')); err != nil {
+		c.t.Fatalf("write: %v", err)
+	}
+}
+
+func (c *mcpClient) call(method string, params any) rpcResponse {
+	c.t.Helper()
+	c.nextID++
+	c.send(map[string]any{"jsonrpc": "2.0", "id": c.nextID, "method": method, "params": params})
+	if !c.out.Scan() {
+		c.t.Fatalf("%s: no response", method)
+	}
+	var resp rpcResponse
+	if err := json.Unmarshal(c.out.Bytes(), &resp); err != nil {
+		c.t.Fatalf("%s: bad response %q: %v", method, c.out.Text(), err)
+	}
+	if string(resp.ID) != strings.TrimSpace(string(mustJSON(c.nextID))) {
+		c.t.Fatalf("%s: response id %s, want %d", method, resp.ID, c.nextID)
+	}
+	return resp
+}
+
+// tool calls a tool and returns its text and error flag.
+func (c *mcpClient) tool(name string, args map[string]any) (string, bool) {
+	c.t.Helper()
+	resp := c.call("tools/call", map[string]any{"name": name, "arguments": args})
+	if resp.Error != nil {
+		c.t.Fatalf("tools/call %s: %s", name, resp.Error.Message)
+	}
+	var res mcpToolResult
+	data, _ := json.Marshal(resp.Result)
+	json.Unmarshal(data, &res)
+	if len(res.Content) != 1 {
+		c.t.Fatalf("tools/call %s: content = %+v", name, res.Content)
+	}
+	return res.Content[0].Text, res.IsError
+}
+
+func mustJSON(v any) []byte {
+	data, _ := json.Marshal(v)
+	return data
+}
+
+func TestMCPInitializeAndListTools(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+	c := newMCPClient(t, env.Store)
+
+	resp := c.call("initialize", map[string]any{"protocolVersion": "2025-03-26"})
+	init := resp.Result.(map[string]any)
+	if init["protocolVersion"] != "2025-03-26" {
+		t.Errorf("protocolVersion = %v", init["protocolVersion"])
+	}
+	// Notifications get no reply; the next response must be the ping's.
+	c.send(map[string]any{"jsonrpc": "2.0", "method": "notifications/initialized"})
+	if resp := c.call("ping", nil); resp.Error != nil {
+		t.Errorf("ping: %v", resp.Error.Message)
+	}
+
+	resp = c.call("tools/list", nil)
+	var list struct {
+		Tools []mcpTool `json:"tools"`
+	}
+	json.Unmarshal(mustJSON(resp.Result), &list)
+	var names []string
+	for _, tool := range list.Tools {
+		names = append(names, tool.Name)
+	}
+	if !reflect.DeepEqual(names, mcpToolNames) {
+		t.Errorf("tools = %v, want %v", names, mcpToolNames)
+	}
+
+	dep := list.Tools[8].InputSchema
+	if got := dep["required"]; !reflect.DeepEqual(got, []any{"action", "from", "kind", "to"}) {
+		t.Errorf("dep required = %v", got)
+	}
+	props := dep["properties"].(map[string]any)
+	if got := props["action"].(map[string]any)["enum"]; !reflect.DeepEqual(got, []any{"add", "remove"}) {
+		t.Errorf("dep action enum = %v", got)
+	}
+	if got := props["close"].(map[string]any)["type"]; got != "boolean" {
+		t.Errorf("dep close type = %v", got)
+	}
+	listProps := list.Tools[2].InputSchema["properties"].(map[string]any)
+	if got := listProps["limit"].(map[string]any)["type"]; got != "integer" {
+		t.Errorf("list limit type = %v", got)
+	}
+	if got := listProps["where"].(map[string]any)["type"]; got != "array" {
+		t.Errorf("list where type = %v", got)
+	}
+	if _, ok := listProps["json"]; ok {
+		t.Error("json flag exposed as a tool argument")
+	}
+}
+
+func TestMCPToolCalls(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+	c := newMCPClient(t, env.Store)
+
+	text, isErr := c.tool("create", map[string]any{"title": "From MCP", "priority": 1})
+	if isErr {
+		t.Fatalf("create: %s", text)
+	}
+	var created issue.Issue
+	if err := json.Unmarshal([]byte(text), &created); err != nil {
+		t.Fatalf("create output is not JSON: %q", text)
+	}
+	if created.Title != "From MCP" || created.Priority != 1 {
+		t.Errorf("created = %+v", created)
+	}
+	blocker, _ := c.tool("create", map[string]any{"title": "Blocker"})
+	var b issue.Issue
+	json.Unmarshal([]byte(blocker), &b)
+
+	if text, isErr := c.tool("dep", map[string]any{"action": "add", "from": b.ID, "kind": "blocks", "to": created.ID}); isErr {
+		t.Fatalf("dep: %s", text)
+	}
+	text, _ = c.tool("ready", nil)
+	var ready []issue.Issue
+	json.Unmarshal([]byte(text), &ready)
+	if len(ready) != 1 || ready[0].ID != b.ID {
+		t.Errorf("ready = %s", text)
+	}
+	if text, isErr := c.tool("start", map[string]any{"id": created.ID}); !isErr || !strings.Contains(text, "blocked") {
+		t.Errorf("start blocked issue: isError=%v text=%q", isErr, text)
+	}
+	if text, isErr := c.tool("close", map[string]any{"id": b.ID, "reason": "done"}); isErr {
+		t.Fatalf("close: %s", text)
+	}
+	if text, isErr := c.tool("comment", map[string]any{"id": created.ID, "text": "unblocked now"}); isErr {
+		t.Fatalf("comment: %s", text)
+	}
+
+	// Writes land as ordinary commits.
+	got, err := env.Store.Get(created.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Comments) != 1 || len(got.BlockedBy) != 1 {
+		t.Errorf("issue after calls = %+v", got)
+	}
+	commits, _ := env.Repo.AllCommits()
+	if !strings.HasPrefix(commits[0].Message, "comment "+created.ID) {
+		t.Errorf("last commit = %q", commits[0].Message)
+	}
+
+	if text, isErr := c.tool("show", map[string]any{"id": "test-nope"}); !isErr || text == "" {
+		t.Errorf("show missing issue: isError=%v text=%q", isErr, text)
+	}
+	if text, isErr := c.tool("show", map[string]any{"bogus": true}); !isErr || !strings.Contains(text, "missing required argument") {
+		t.Errorf("show without id: isError=%v text=%q", isErr, text)
+	}
+}
+
+func TestMCPProtocolErrors(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+	c := newMCPClient(t, env.Store)
+
+	if resp := c.call("resources/list", nil); resp.Error == nil || resp.Error.Code != -32601 {
+		t.Errorf("unknown method: %+v", resp)
+	}
+	if resp := c.call("tools/call", map[string]any{"name": "delete"}); resp.Error == nil || resp.Error.Code != -32602 {
+		t.Errorf("unexposed tool: %+v", resp)
+	}
+}
+
+func TestMCPArgv(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		args map[string]any
+		want []string
+	}{
+		{"dep", map[string]any{"action": "add", "from": "bw-1", "kind": "duplicates", "to": "bw-2", "close": true},
+			[]string{"add", "bw-1", "duplicates", "bw-2", "--close"}},
+		{"list", map[string]any{"where": []any{"a=1", "b=2"}, "limit": float64(3), "all": false},
+			[]string{"--where", "a=1", "--where", "b=2", "--limit", "3", "--json"}},
+		{"defer", map[string]any{"id": "bw-1", "when": "next monday"},
+			[]string{"bw-1", "next monday", "--json"}},
+	}
+	for _, tt := range tests {
+		got, err := mcpArgv(commandMap[tt.cmd], tt.args)
+		if err != nil {
+			t.Errorf("%s: %v", tt.cmd, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s argv = %q, want %q", tt.cmd, got, tt.want)
+		}
+	}
+
+	for _, bad := range []map[string]any{
+		{"id": 1.5},
+		{"id": "bw-1", "reason": []any{true}},
+		{"id": "bw-1", "recursive": "yes"},
+	} {
+		if _, err := mcpArgv(commandMap["close"], bad); err == nil {
+			t.Errorf("close %v: expected error", bad)
+		}
+	}
+}
@@ -662,6 +662,22 @@ func TestParseDepRemoveArgs(t *testing.T) {
 	}
 }
 
+func TestParseDepAddArgsRelationKinds(t *testing.T) {
+	a, err := parseDepAddArgs([]string{"bw-aaaa", "duplicates", "bw-bbbb", "--close"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Kind != "duplicates" || !a.Close {
+		t.Errorf("got %+v", a)
+	}
+	if _, err := parseDepAddArgs([]string{"bw-aaaa", "relates-to", "bw-bbbb", "--close"}); err == nil {
+		t.Error("expected --close to be rejected for relates-to")
+	}
+	if _, err := parseDepAddArgs([]string{"bw-aaaa", "mentions", "bw-bbbb"}); err == nil {
+		t.Error("expected error for unknown kind")
+	}
+}
+
 func TestParseDepRemoveArgsBadSyntax(t *testing.T) {
 	_, err := parseDepRemoveArgs([]string{"a", "b"})
 	if err == nil {
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/jallum/beadwork/internal/config"
+
+	"github.com/jallum/beadwork/internal/issue"
+	"github.com/jallum/beadwork/internal/md"
+)
+
+type PathArgs struct {
+	ID       string
+	Estimate string
+	JSON     bool
+}
+
+func parsePathArgs(raw []string) (PathArgs, error) {
+	a, err := ParseArgs(raw, []string{"--estimate"}, []string{"--json"})
+	if err != nil {
+		return PathArgs{}, err
+	}
+	pos := a.Pos()
+	if len(pos) != 1 {
+		return PathArgs{}, fmt.Errorf("usage: bw path <id>")
+	}
+	return PathArgs{ID: pos[0], Estimate: a.String("--estimate"), JSON: a.JSON()}, nil
+}
+
+func cmdPath(store *issue.Store, args []string, w Writer, _ *config.Config) (*config.Config, error) {
+	pa, err := parsePathArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	cp, err := store.CriticalPath(pa.ID, issue.PathOpts{Estimate: pa.Estimate})
+	if err != nil {
+		return nil, err
+	}
+
+	if pa.JSON {
+		fprintJSON(w, cp)
+		return nil, nil
+	}
+
+	unit := "issues"
+	if cp.Estimate != "" {
+		unit = cp.Estimate
+	}
+	fmt.Fprintf(w, "Critical path to {id:%s}: %d %s, %d of %d open issues\n\n",
+		cp.Target, cp.Length, unit, len(cp.Path), cp.Open)
+
+	now := store.Now()
+	steps := make([]*issue.Issue, len(cp.Path))
+	for i, step := range cp.Path {
+		steps[i] = step.Issue
+	}
+	closedBlockers := store.ClosedBlockerSet(steps)
+	w.Push(2)
+	for _, step := range cp.Path {
+		fmt.Fprintf(w, "%4d  %s\n", step.Finish, md.IssueOneLinerWithDue(step.Issue, now, closedBlockers))
+	}
+	w.Pop()
+
+	if len(cp.Conflicts) > 0 {
+		fmt.Fprintf(w, "\nDue conflicts (%d):\n", len(cp.Conflicts))
+		w.Push(2)
+		for _, c := range cp.Conflicts {
+			fmt.Fprintf(w, "{id:%s} is due %s but waits on {id:%s}, due %s\n", c.ID, c.Due, c.Blocker, c.BlockerDue)
+		}
+		w.Pop()
+	}
+
+	fmt.Fprintln(w)
+	if cp.Next == nil {
+		fmt.Fprintln(w, "Next: nothing is ready (the remaining work is claimed or deferred)")
+		return nil, nil
+	}
+	fmt.Fprintf(w, "Next: %s\n", md.IssueOneLinerWithDue(cp.Next, now, closedBlockers))
+	w.Push(2)
+	if cp.NextSaves > 0 {
+		fmt.Fprintf(w, "closing it shortens the path from %d to %d\n", cp.Length, cp.Length-cp.NextSaves)
+	} else {
+		fmt.Fprintln(w, "another chain is as long, so closing it alone does not shorten the path")
+	}
+	w.Pop()
+	return nil, nil
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/jallum/beadwork/internal/issue"
+	"github.com/jallum/beadwork/internal/testutil"
+)
+
+func TestCmdPath(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	epic, _ := env.Store.Create("Ship", issue.CreateOpts{Due: "2027-03-01"})
+	env.Store.Create("Build", issue.CreateOpts{Parent: epic.ID})
+	blocker, _ := env.Store.Create("Design", issue.CreateOpts{Due: "2027-04-01"})
+	research, _ := env.Store.Create("Research", issue.CreateOpts{})
+	env.Store.Link(blocker.ID, epic.ID)
+	env.Store.Link(research.ID, blocker.ID)
+	env.Repo.Commit("setup")
+
+	var buf bytes.Buffer
+	if _, err := cmdPath(env.Store, []string{epic.ID}, PlainWriter(&buf), nil); err != nil {
+		t.Fatalf("path: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"Critical path to " + epic.ID + ": 3 issues, 3 of 4 open issues",
+		"Due conflicts (1):",
+		epic.ID + " is due 2027-03-01 but waits on " + blocker.ID + ", due 2027-04-01",
+		"Next: ",
+		"Next: ○ " + research.ID,
+		"closing it shortens the path from 3 to 2",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	if _, err := cmdPath(env.Store, []string{epic.ID, "--json"}, PlainWriter(&buf), nil); err != nil {
+		t.Fatalf("path --json: %v", err)
+	}
+	var cp issue.CriticalPath
+	if err := json.Unmarshal(buf.Bytes(), &cp); err != nil {
+		t.Fatalf("unmarshal: %v\n%s", err, buf.String())
+	}
+	if cp.Target != epic.ID || cp.Length != 3 || len(cp.Path) != 3 || cp.Path[1].ID != blocker.ID {
+		t.Errorf("json = %+v", cp)
+	}
+	if cp.Next == nil || cp.Next.ID != research.ID || cp.NextSaves != 1 {
+		t.Errorf("next = %+v saves %d", cp.Next, cp.NextSaves)
+	}
+
+	// Two chains of equal length: nothing shortens the path on its own.
+	env.Store.Close(research.ID, "")
+	env.Store.Close(blocker.ID, "")
+	env.Store.Create("Test", issue.CreateOpts{Parent: epic.ID})
+	env.Repo.Commit("close blockers, add a second child")
+	buf.Reset()
+	cmdPath(env.Store, []string{epic.ID}, PlainWriter(&buf), nil)
+	if !strings.Contains(buf.String(), "closing it alone does not shorten the path") {
+		t.Errorf("output:\n%s", buf.String())
+	}
+
+	if _, err := parsePathArgs(nil); err == nil {
+		t.Error("expected usage error without an ID")
+	}
+}
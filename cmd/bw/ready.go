@@ -13,20 +13,28 @@ import (
 
 type ReadyArgs struct {
 	ParentID  string
+	Query     *issue.Query
 	JSON      bool
 	NoContext bool
 }
 
 func parseReadyArgs(raw []string) (ReadyArgs, error) {
-	a, err := ParseArgs(raw, nil, []string{"--json", "--no-context"})
+	a, err := ParseArgs(raw, []string{"--query"}, []string{"--json", "--no-context"})
 	if err != nil {
 		return ReadyArgs{}, err
 	}
-	return ReadyArgs{
+	ra := ReadyArgs{
 		ParentID:  a.PosFirst(),
 		JSON:      a.JSON(),
 		NoContext: a.Bool("--no-context"),
-	}, nil
+	}
+	if a.Has("--query") {
+		ra.Query, err = issue.ParseQuery(a.String("--query"))
+		if err != nil {
+			return ra, err
+		}
+	}
+	return ra, nil
 }
 
 func cmdReady(store *issue.Store, args []string, w Writer, _ *config.Config) (*config.Config, error) {
@@ -44,6 +52,9 @@ func cmdReady(store *issue.Store, args []string, w Writer, _ *config.Config) (*c
 	if err != nil {
 		return nil, err
 	}
+	if ra.Query != nil {
+		issues = ra.Query.Filter(issues, store.QueryEnv())
+	}
 
 	if ra.JSON {
 		fprintJSON(w, issues)
@@ -117,7 +128,7 @@ func cmdReady(store *issue.Store, args []string, w Writer, _ *config.Config) (*c
 		fmt.Fprintln(w)
 
 		var legend []string
-		for _, s := range issue.Statuses {
+		for _, s := range store.CurrentWorkflow().Statuses {
 			legend = append(legend, s.Icon+" "+s.Name)
 		}
 		legend = append(legend, "⊘ blocked")
@@ -392,3 +392,22 @@ func TestCmdReadyScopedEmpty(t *testing.T) {
 		t.Errorf("expected 'no ready issues', got: %q", buf.String())
 	}
 }
+
+func TestCmdReadyQuery(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+	env.Store.User = "alice"
+
+	mine, _ := env.Store.Create("Mine", issue.CreateOpts{Assignee: "alice"})
+	env.Store.Create("Theirs", issue.CreateOpts{Assignee: "bob"})
+	env.Repo.Commit("create issues")
+
+	var buf bytes.Buffer
+	_, err := cmdReady(env.Store, []string{"--query", "assignee:me", "--no-context"}, PlainWriter(&buf), nil)
+	if err != nil {
+		t.Fatalf("cmdReady: %v", err)
+	}
+	if !strings.Contains(buf.String(), mine.ID) || strings.Contains(buf.String(), "Theirs") {
+		t.Errorf("output = %q", buf.String())
+	}
+}
@@ -261,7 +261,7 @@ func eventTypeStyle(t string) []Style {
 		return []Style{Green, Bold}
 	case "start", "reopen":
 		return []Style{Yellow, Bold}
-	case "create":
+	case "create", "undelete":
 		return []Style{Cyan, Bold}
 	case "unblocked":
 		return []Style{Cyan}
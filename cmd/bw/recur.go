@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jallum/beadwork/internal/issue"
+)
+
+// everyCompactRe matches compact intervals such as "2w" or "10d".
+var everyCompactRe = regexp.MustCompile(`^(\d+)([dw])$`)
+
+var everyCompactUnits = map[string]string{"d": "day", "w": "week"}
+
+// everyAliases are the single-word intervals --every accepts.
+var everyAliases = map[string]string{
+	"daily":   "1 day",
+	"weekly":  "1 week",
+	"monthly": "1 month",
+	"yearly":  "1 year",
+	"day":     "1 day",
+	"week":    "1 week",
+	"month":   "1 month",
+	"year":    "1 year",
+}
+
+// nextOccurrence resolves a recurrence rule to the next time it comes
+// around after now, in the same forms resolveDateAfterNow produces:
+// YYYY-MM-DD for date-only rules, RFC3339 for rules with a time of day.
+//
+// Rules are a weekday with an optional time ("monday", "monday 9am",
+// "fri at 17:00"), a bare time meaning every day ("9am"), or an interval
+// counted from now ("2w", "3d", "2 weeks", "1 month", "weekly").
+func nextOccurrence(every string, now time.Time) (string, error) {
+	parts := strings.Fields(strings.ToLower(every))
+	local := now.In(time.Local)
+
+	dateExpr, timeExpr := splitAtKeyword(parts)
+	if timeExpr == "" && len(parts) == 2 && looksLikeTime(parts[1]) {
+		dateExpr, timeExpr = parts[0], parts[1]
+	}
+
+	// A time of day, optionally on a weekday.
+	if timeExpr != "" {
+		tod, err := parseTimeOfDay(timeExpr)
+		if err != nil {
+			return "", err
+		}
+		result := time.Date(local.Year(), local.Month(), local.Day(),
+			tod.hour, tod.min, 0, 0, local.Location())
+		step := 1
+		if dateExpr != "" {
+			day, ok := parseWeekday(dateExpr)
+			if !ok {
+				return "", invalidEvery(every)
+			}
+			diff := (int(day) - int(local.Weekday()) + 7) % 7
+			result = result.AddDate(0, 0, diff)
+			step = 7
+		}
+		if !result.After(now) {
+			result = result.AddDate(0, 0, step)
+		}
+		return result.Format(time.RFC3339), nil
+	}
+
+	if day, ok := parseWeekday(dateExpr); ok {
+		return nextWeekday(now, day).Format("2006-01-02"), nil
+	}
+
+	if alias, ok := everyAliases[dateExpr]; ok {
+		dateExpr = alias
+	}
+	var n int
+	var unit string
+	var ok bool
+	if m := everyCompactRe.FindStringSubmatch(dateExpr); m != nil {
+		n, _ = strconv.Atoi(m[1])
+		unit, ok = everyCompactUnits[m[2]], true
+	} else {
+		n, unit, ok = parseDurationExpr(strings.Fields(dateExpr))
+	}
+	if !ok || n <= 0 {
+		return "", invalidEvery(every)
+	}
+	switch unit {
+	case "day":
+		return now.AddDate(0, 0, n).Format("2006-01-02"), nil
+	case "week":
+		return now.AddDate(0, 0, n*7).Format("2006-01-02"), nil
+	case "month":
+		return now.AddDate(0, n, 0).Format("2006-01-02"), nil
+	case "year":
+		return now.AddDate(n, 0, 0).Format("2006-01-02"), nil
+	}
+	return "", invalidEvery(every)
+}
+
+func invalidEvery(every string) error {
+	return fmt.Errorf("invalid recurrence %q (expected e.g. \"monday 9am\", \"friday\", \"9am\", \"2w\", \"3 days\", \"monthly\")", every)
+}
+
+// recurClosed spawns the next instance of every recurring issue in
+// closed. It returns the spawned issues and the recur intent lines that
+// record them; each line names the new ID and its defer date so replay
+// does not depend on when it runs.
+func recurClosed(store *issue.Store, closed []*issue.Issue) ([]*issue.Issue, []string, error) {
+	var spawned []*issue.Issue
+	var lines []string
+	for _, iss := range closed {
+		if iss.Every == "" {
+			continue
+		}
+		when, err := nextOccurrence(iss.Every, store.Now())
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", iss.ID, err)
+		}
+		next, err := store.Recur(iss.ID, issue.RecurOpts{DeferUntil: when})
+		if err != nil {
+			return nil, nil, err
+		}
+		spawned = append(spawned, next)
+		lines = append(lines, fmt.Sprintf("recur %s %s until %s", iss.ID, next.ID, when))
+	}
+	return spawned, lines, nil
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jallum/beadwork/internal/issue"
+	"github.com/jallum/beadwork/internal/testutil"
+)
+
+func TestNextOccurrence(t *testing.T) {
+	// Wednesday 2027-03-10 at noon, in whatever zone the runner uses.
+	now := time.Date(2027, 3, 10, 12, 0, 0, 0, time.Local)
+	at := func(day, hour, min int) string {
+		return time.Date(2027, 3, day, hour, min, 0, 0, time.Local).Format(time.RFC3339)
+	}
+
+	tests := []struct {
+		every string
+		want  string
+	}{
+		{"monday 9am", at(15, 9, 0)},
+		{"Mon at 9am", at(15, 9, 0)},
+		{"wednesday 3pm", at(10, 15, 0)},
+		{"wednesday 9am", at(17, 9, 0)},
+		{"9am", at(11, 9, 0)},
+		{"17:30", at(10, 17, 30)},
+		{"friday", "2027-03-12"},
+		{"2w", "2027-03-24"},
+		{"3d", "2027-03-13"},
+		{"2 weeks", "2027-03-24"},
+		{"1 month", "2027-04-10"},
+		{"weekly", "2027-03-17"},
+		{"monthly", "2027-04-10"},
+		{"yearly", "2028-03-10"},
+	}
+	for _, tt := range tests {
+		got, err := nextOccurrence(tt.every, now)
+		if err != nil {
+			t.Errorf("nextOccurrence(%q): %v", tt.every, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("nextOccurrence(%q) = %q, want %q", tt.every, got, tt.want)
+		}
+	}
+}
+
+func TestNextOccurrenceInvalid(t *testing.T) {
+	now := time.Date(2027, 3, 10, 12, 0, 0, 0, time.UTC)
+	for _, every := range []string{"", "sometimes", "0w", "2x", "3 hours", "someday 9am", "monday 25pm"} {
+		if got, err := nextOccurrence(every, now); err == nil {
+			t.Errorf("nextOccurrence(%q) = %q, want error", every, got)
+		}
+	}
+}
+
+func TestCmdCloseRecurring(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+	t.Setenv("BW_CLOCK", "2027-03-10T12:00:00Z")
+
+	var buf bytes.Buffer
+	if _, err := cmdCreate(env.Store, []string{"Water plants", "--every", "2w", "--silent"}, PlainWriter(&buf), nil); err != nil {
+		t.Fatalf("cmdCreate: %v", err)
+	}
+	id := strings.TrimSpace(buf.String())
+
+	buf.Reset()
+	if _, err := cmdClose(env.Store, []string{id, "--json"}, PlainWriter(&buf), nil); err != nil {
+		t.Fatalf("cmdClose: %v", err)
+	}
+	var result issue.CloseResult
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("JSON parse: %v", err)
+	}
+	next := result.Next
+	if next == nil {
+		t.Fatal("closing a recurring issue returned no next instance")
+	}
+	if next.Title != "Water plants" || next.Every != "2w" || next.Status != "deferred" || next.DeferUntil != "2027-03-24" {
+		t.Errorf("next = %q every=%q status=%s defer=%s", next.Title, next.Every, next.Status, next.DeferUntil)
+	}
+
+	commits, _ := env.Repo.AllCommits()
+	want := "close " + id + "\nrecur " + id + " " + next.ID + " until 2027-03-24"
+	if commits[0].Message != want {
+		t.Errorf("intent = %q, want %q", commits[0].Message, want)
+	}
+}
+
+func TestCmdCloseRecursiveRecurring(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+	t.Setenv("BW_CLOCK", "2027-03-10T12:00:00Z")
+
+	parent, _ := env.Store.Create("Sprint", issue.CreateOpts{})
+	child, _ := env.Store.Create("Retro", issue.CreateOpts{Parent: parent.ID, Every: "friday"})
+	env.Repo.Commit("create " + parent.ID)
+
+	var buf bytes.Buffer
+	if _, err := cmdClose(env.Store, []string{parent.ID, "--recursive"}, PlainWriter(&buf), nil); err != nil {
+		t.Fatalf("cmdClose --recursive: %v", err)
+	}
+	if !strings.Contains(buf.String(), "deferred until 2027-03-12") {
+		t.Errorf("output = %q, want next instance reported", buf.String())
+	}
+	got, _ := env.Store.Get(child.ID)
+	if len(got.SupersededBy) != 1 {
+		t.Errorf("superseded_by = %v, want the next instance", got.SupersededBy)
+	}
+}
+
+func TestCmdCreateEveryInvalid(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	var buf bytes.Buffer
+	_, err := cmdCreate(env.Store, []string{"Bad rule", "--every", "sometimes"}, PlainWriter(&buf), nil)
+	if err == nil || !strings.Contains(err.Error(), "invalid recurrence") {
+		t.Errorf("err = %v, want invalid recurrence", err)
+	}
+}
+
+func TestCmdUpdateEvery(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	iss, _ := env.Store.Create("Backups", issue.CreateOpts{})
+	env.Repo.Commit("create " + iss.ID)
+
+	var buf bytes.Buffer
+	if _, err := cmdUpdate(env.Store, []string{iss.ID, "--every", "weekly"}, PlainWriter(&buf), nil); err != nil {
+		t.Fatalf("cmdUpdate: %v", err)
+	}
+	got, _ := env.Store.Get(iss.ID)
+	if got.Every != "weekly" {
+		t.Errorf("every = %q, want weekly", got.Every)
+	}
+
+	if _, err := cmdUpdate(env.Store, []string{iss.ID, "--every", ""}, PlainWriter(&buf), nil); err != nil {
+		t.Fatalf("cmdUpdate clear: %v", err)
+	}
+	got, _ = env.Store.Get(iss.ID)
+	if got.Every != "" {
+		t.Errorf("every = %q, want cleared", got.Every)
+	}
+	commits, _ := env.Repo.AllCommits()
+	if commits[0].Message != "update "+iss.ID+` every=""` {
+		t.Errorf("intent = %q", commits[0].Message)
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jallum/beadwork/internal/config"
+	"github.com/jallum/beadwork/internal/intent"
+	"github.com/jallum/beadwork/internal/issue"
+	"github.com/jallum/beadwork/internal/repo"
+)
+
+type RevertArgs struct {
+	ID     string
+	To     string
+	Fields []string
+	JSON   bool
+}
+
+func parseRevertArgs(raw []string) (RevertArgs, error) {
+	if len(raw) == 0 {
+		return RevertArgs{}, fmt.Errorf("usage: bw revert <id> --to <commit|time> [--fields f1,f2]")
+	}
+	a, err := ParseArgs(raw[1:], []string{"--to", "--fields"}, []string{"--json"})
+	if err != nil {
+		return RevertArgs{}, err
+	}
+	ra := RevertArgs{ID: raw[0], To: a.String("--to"), JSON: a.JSON()}
+	if ra.To == "" {
+		return RevertArgs{}, fmt.Errorf("--to is required (a commit, RFC3339 time, YYYY-MM-DD, or window like yesterday, 7d)")
+	}
+	if a.Has("--fields") {
+		for _, f := range strings.Split(a.String("--fields"), ",") {
+			f = strings.TrimSpace(f)
+			if f == "" {
+				continue
+			}
+			if !slices.Contains(intent.RevertFields, f) {
+				return RevertArgs{}, fmt.Errorf("invalid field %q (expected %s)", f, strings.Join(intent.RevertFields, ", "))
+			}
+			ra.Fields = append(ra.Fields, f)
+		}
+		if len(ra.Fields) == 0 {
+			return RevertArgs{}, fmt.Errorf("--fields needs at least one field")
+		}
+	}
+	return ra, nil
+}
+
+type revertResult struct {
+	ID      string   `json:"id"`
+	Commit  string   `json:"commit"`
+	Intents []string `json:"intents"`
+}
+
+func cmdRevert(store *issue.Store, args []string, w Writer, _ *config.Config) (*config.Config, error) {
+	ra, err := parseRevertArgs(args)
+	if err != nil {
+		return nil, err
+	}
+
+	var res revertResult
+	err = commitWithRetry(store, commitMaxRetries, func() (string, error) {
+		cur, gerr := store.Get(ra.ID)
+		if gerr != nil {
+			return "", gerr
+		}
+		r := store.Committer.(*repo.Repo)
+		hash, rerr := r.ResolveAt(ra.To, store.Now())
+		if rerr != nil {
+			return "", fmt.Errorf("--to %s: %w", ra.To, rerr)
+		}
+		old, rerr := issueAt(store.FS, hash.String(), "issues/"+cur.ID+".json")
+		if rerr != nil {
+			return "", rerr
+		}
+		if old == nil {
+			return "", fmt.Errorf("%s did not exist at %s", cur.ID, shortHash(hash.String()))
+		}
+
+		exists := func(id string) bool {
+			_, err := store.Get(id)
+			return err == nil
+		}
+		lines := intent.Revert(cur, old, ra.Fields, exists)
+		if len(lines) == 0 {
+			return "", fmt.Errorf("nothing to revert: %s already matches %s", cur.ID, shortHash(hash.String()))
+		}
+		for _, line := range lines {
+			if aerr := intent.Apply(store, line); aerr != nil {
+				return "", fmt.Errorf("applying %q: %w", line, aerr)
+			}
+		}
+		res = revertResult{ID: cur.ID, Commit: hash.String(), Intents: lines}
+		return strings.Join(lines, "\n"), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if ra.JSON {
+		fprintJSON(w, res)
+		return nil, nil
+	}
+	fmt.Fprintf(w, "reverted %s to %s\n", w.Style(res.ID, Cyan), shortHash(res.Commit))
+	w.Push(2)
+	for _, line := range res.Intents {
+		fmt.Fprintln(w, line)
+	}
+	w.Pop()
+	return nil, nil
+}
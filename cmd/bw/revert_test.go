@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/jallum/beadwork/internal/issue"
+	"github.com/jallum/beadwork/internal/testutil"
+)
+
+func TestParseRevertArgs(t *testing.T) {
+	ra, err := parseRevertArgs([]string{"bw-1234", "--to", "abcd123", "--fields", "title, description"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ra.ID != "bw-1234" || ra.To != "abcd123" {
+		t.Errorf("args = %+v", ra)
+	}
+	if len(ra.Fields) != 2 || ra.Fields[0] != "title" || ra.Fields[1] != "description" {
+		t.Errorf("Fields = %v", ra.Fields)
+	}
+	for _, args := range [][]string{
+		{},
+		{"bw-1234"},
+		{"bw-1234", "--to", "abcd", "--fields", "status"},
+	} {
+		if _, err := parseRevertArgs(args); err == nil {
+			t.Errorf("parseRevertArgs(%q): expected error", args)
+		}
+	}
+}
+
+func TestCmdRevert(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	iss, _ := env.Store.Create("Good title", issue.CreateOpts{Description: "Careful notes"})
+	env.Store.Label(iss.ID, []string{"keep"}, nil)
+	env.Repo.Commit("create " + iss.ID)
+	commits, _ := env.Repo.AllCommits()
+	good := commits[0].Hash
+
+	title, desc := "Bad title", "Mangled"
+	env.Store.Update(iss.ID, issue.UpdateOpts{Title: &title, Description: &desc})
+	env.Store.Label(iss.ID, nil, []string{"keep"})
+	env.Repo.Commit("update " + iss.ID)
+
+	var buf bytes.Buffer
+	if _, err := cmdRevert(env.Store, []string{iss.ID, "--to", good[:8], "--fields", "description,labels"}, PlainWriter(&buf), nil); err != nil {
+		t.Fatalf("cmdRevert: %v", err)
+	}
+	got, _ := env.Store.Get(iss.ID)
+	if got.Description != "Careful notes" {
+		t.Errorf("Description = %q, want restored", got.Description)
+	}
+	if got.Title != "Bad title" {
+		t.Errorf("Title = %q, should be left alone by --fields", got.Title)
+	}
+	if len(got.Labels) != 1 || got.Labels[0] != "keep" {
+		t.Errorf("Labels = %v, want [keep]", got.Labels)
+	}
+
+	commits, _ = env.Repo.AllCommits()
+	want := `update ` + iss.ID + ` description="Careful notes"` + "\nlabel " + iss.ID + " +keep"
+	if commits[0].Message != want {
+		t.Errorf("commit message = %q, want %q", commits[0].Message, want)
+	}
+	if !strings.Contains(buf.String(), "reverted "+iss.ID) {
+		t.Errorf("output = %q", buf.String())
+	}
+
+	buf.Reset()
+	if _, err := cmdRevert(env.Store, []string{iss.ID, "--to", good[:8], "--json"}, PlainWriter(&buf), nil); err != nil {
+		t.Fatalf("cmdRevert --json: %v", err)
+	}
+	var res revertResult
+	if err := json.Unmarshal(buf.Bytes(), &res); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if res.Commit != good || len(res.Intents) != 1 {
+		t.Errorf("result = %+v", res)
+	}
+
+	if _, err := cmdRevert(env.Store, []string{iss.ID, "--to", good[:8]}, PlainWriter(&buf), nil); err == nil {
+		t.Error("expected error when nothing differs")
+	}
+}
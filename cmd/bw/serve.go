@@ -0,0 +1,391 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/jallum/beadwork/internal/agent"
+	"github.com/jallum/beadwork/internal/config"
+
+	"github.com/jallum/beadwork/internal/issue"
+	"github.com/jallum/beadwork/internal/repo"
+)
+
+// serveDialTimeout bounds how long the CLI waits for a daemon before
+// running the command itself.
+const serveDialTimeout = 200 * time.Millisecond
+
+// rpcServerError marks a request the daemon refused without running it;
+// the CLI then runs the command itself.
+const rpcServerError = -32000
+
+// serveRunParams is one forwarded command line.
+type serveRunParams struct {
+	Version string            `json:"version"`
+	Dir     string            `json:"dir"`
+	Args    []string          `json:"args"`   // command name first
+	Render  string            `json:"render"` // tty, raw or markdown
+	Width   int               `json:"width,omitempty"`
+	DryRun  bool              `json:"dry_run,omitempty"`
+	Env     map[string]string `json:"env,omitempty"`
+}
+
+// serveRunResult is what the command wrote and the error it returned.
+type serveRunResult struct {
+	Output string `json:"output"`
+	Error  string `json:"error,omitempty"`
+}
+
+type ServeArgs struct {
+	Socket string
+}
+
+func parseServeArgs(raw []string) (ServeArgs, error) {
+	a, err := ParseArgs(raw, []string{"--socket"}, nil)
+	if err != nil {
+		return ServeArgs{}, err
+	}
+	return ServeArgs{Socket: a.String("--socket")}, nil
+}
+
+func cmdServe(store *issue.Store, args []string, w Writer, _ *config.Config) (*config.Config, error) {
+	sa, err := parseServeArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	r := store.Committer.(*repo.Repo)
+	path := sa.Socket
+	if path == "" {
+		path = repo.SocketPath(r.GitDir)
+	}
+
+	ln, err := listenSocket(path)
+	if err != nil {
+		return nil, err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		ln.Close()
+	}()
+
+	sv := newServer()
+	sv.repos[r.GitDir] = &servedRepo{store: store, ref: store.FS.RefHash()}
+	fmt.Fprintf(w, "serving %s on %s\n", filepath.Dir(r.GitDir), path)
+	err = serveSocket(sv, ln)
+	os.Remove(path)
+	if ctx.Err() != nil {
+		return nil, nil // interrupted: a normal shutdown
+	}
+	return nil, err
+}
+
+// listenSocket listens on a Unix socket at path, replacing a stale
+// socket file left by a daemon that did not shut down cleanly.
+func listenSocket(path string) (net.Listener, error) {
+	if _, err := os.Stat(path); err == nil {
+		if conn, err := net.DialTimeout("unix", path, serveDialTimeout); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("bw serve is already listening on %s", path)
+		}
+		os.Remove(path)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		ln.Close()
+		return nil, err
+	}
+	return ln, nil
+}
+
+// server keeps one warm store per repository. Commands run one at a
+// time: they share process-wide state (the environment, globalDryRun).
+type server struct {
+	mu    sync.Mutex
+	repos map[string]*servedRepo // by git dir
+}
+
+type servedRepo struct {
+	store *issue.Store
+	ref   plumbing.Hash // beadwork ref the store was loaded at
+}
+
+func newServer() *server {
+	return &server{repos: make(map[string]*servedRepo)}
+}
+
+// serveSocket accepts connections until ln is closed.
+func serveSocket(sv *server, ln net.Listener) error {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go sv.serveConn(conn)
+	}
+}
+
+func (sv *server) serveConn(conn net.Conn) {
+	defer conn.Close()
+	sc := bufio.NewScanner(conn)
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	enc := json.NewEncoder(conn)
+	for sc.Scan() {
+		var req rpcRequest
+		if err := json.Unmarshal(sc.Bytes(), &req); err != nil {
+			enc.Encode(rpcResponse{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &rpcError{Code: rpcParseError, Message: "parse error: " + err.Error()}})
+			continue
+		}
+		var result any
+		var rerr *rpcError
+		switch req.Method {
+		case "ping":
+			result = map[string]any{"version": version}
+		case "run":
+			var p serveRunParams
+			if err := json.Unmarshal(req.Params, &p); err != nil {
+				rerr = &rpcError{Code: rpcInvalidParams, Message: "invalid params: " + err.Error()}
+				break
+			}
+			if res, err := sv.run(p); err != nil {
+				rerr = err
+			} else {
+				result = res
+			}
+		default:
+			rerr = &rpcError{Code: rpcMethodNotFound, Message: "method not found: " + req.Method}
+		}
+		if len(req.ID) == 0 {
+			continue
+		}
+		if err := enc.Encode(rpcResponse{JSONRPC: "2.0", ID: req.ID, Result: result, Error: rerr}); err != nil {
+			return
+		}
+	}
+}
+
+func (sv *server) run(p serveRunParams) (res *serveRunResult, rerr *rpcError) {
+	if p.Version != version {
+		return nil, &rpcError{Code: rpcServerError, Message: fmt.Sprintf("daemon is bw %s, client is %s", version, p.Version)}
+	}
+	if len(p.Args) == 0 {
+		return nil, &rpcError{Code: rpcInvalidParams, Message: "no command"}
+	}
+	c := commandMap[p.Args[0]]
+	if c == nil || !c.NeedsStore || c.Local {
+		return nil, &rpcError{Code: rpcServerError, Message: fmt.Sprintf("bw %s cannot run through bw serve", p.Args[0])}
+	}
+
+	sv.mu.Lock()
+	defer sv.mu.Unlock()
+	defer setServeEnv(p.Env)()
+
+	store, err := sv.store(p.Dir)
+	if err != nil {
+		return &serveRunResult{Error: err.Error()}, nil
+	}
+	store.DryRun = p.DryRun
+	globalDryRun = p.DryRun
+	defer func() {
+		store.DryRun = false
+		globalDryRun = false
+		if v := recover(); v != nil {
+			res = &serveRunResult{Error: fmt.Sprintf("bw %s: internal error: %v", c.Name, v)}
+		}
+	}()
+
+	var buf bytes.Buffer
+	w := withStatusIcons(serveWriter(&buf, p.Render, p.Width), store.CurrentWorkflow())
+	_, err = c.Run(store, p.Args[1:], w, nil)
+	res = &serveRunResult{Output: buf.String()}
+	if err != nil {
+		res.Error = err.Error()
+	}
+	return res, nil
+}
+
+// store returns the warm store for the repository containing dir. When
+// the beadwork ref has moved since the store was loaded, whether by
+// another process or by an earlier command, the store is rebuilt so no
+// cached issue or config outlives the commit it came from.
+func (sv *server) store(dir string) (*issue.Store, error) {
+	gitDir, err := repo.FindGitDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("not a git repository")
+	}
+	e := sv.repos[gitDir]
+	if e == nil {
+		r, err := repo.FindRepoAt(dir)
+		if err != nil {
+			return nil, err
+		}
+		store, err := openStore(r)
+		if err != nil {
+			return nil, err
+		}
+		sv.repos[gitDir] = &servedRepo{store: store, ref: store.FS.RefHash()}
+		return store, nil
+	}
+
+	r := e.store.Committer.(*repo.Repo)
+	fs := r.TreeFS()
+	if err := fs.Refresh(); err != nil {
+		return nil, err
+	}
+	if ref := fs.RefHash(); ref != e.ref || fs != e.store.FS {
+		store, err := openStore(r)
+		if err != nil {
+			return nil, err
+		}
+		e.store, e.ref = store, ref
+	}
+	return e.store, nil
+}
+
+func serveWriter(out io.Writer, render string, width int) Writer {
+	switch render {
+	case "tty":
+		if width <= 0 {
+			width = 80
+		}
+		return ColorWriter(out, width)
+	case "raw":
+		return RawWriter(out)
+	}
+	return PlainWriter(out)
+}
+
+// serveEnvVars are the client environment variables a forwarded command
+// consults; the daemon adopts the client's values while running it.
+func serveEnvVars() []string {
+	return append([]string{"BW_CLOCK"}, agent.EnvVars()...)
+}
+
+// setServeEnv applies env for serveEnvVars and returns a func restoring
+// the daemon's own values.
+func setServeEnv(env map[string]string) func() {
+	saved := make(map[string]*string)
+	for _, k := range serveEnvVars() {
+		if v, ok := os.LookupEnv(k); ok {
+			saved[k] = &v
+		} else {
+			saved[k] = nil
+		}
+		if v, ok := env[k]; ok {
+			os.Setenv(k, v)
+		} else {
+			os.Unsetenv(k)
+		}
+	}
+	return func() {
+		for k, v := range saved {
+			if v != nil {
+				os.Setenv(k, *v)
+			} else {
+				os.Unsetenv(k)
+			}
+		}
+	}
+}
+
+// serveSocketFor returns the socket a CLI run in dir should forward to:
+// $BW_SOCKET if set, otherwise the repository's default socket. It is
+// empty when no socket file exists.
+func serveSocketFor(dir string) string {
+	path := os.Getenv("BW_SOCKET")
+	if path == "" {
+		gitDir, err := repo.FindGitDir(dir)
+		if err != nil {
+			return ""
+		}
+		path = repo.SocketPath(gitDir)
+	}
+	if _, err := os.Stat(path); err != nil {
+		return ""
+	}
+	return path
+}
+
+// forwardToServer runs a command line through a bw serve daemon. It
+// reports false when no daemon accepted the command, in which case
+// nothing ran and the caller should run it in-process. Once the request
+// has been sent, a lost connection is an error rather than a fallback:
+// the command may already have committed.
+func forwardToServer(dir string, args []string, render string, width int, dryRun bool) (*serveRunResult, bool, error) {
+	path := serveSocketFor(dir)
+	if path == "" {
+		return nil, false, nil
+	}
+	conn, err := net.DialTimeout("unix", path, serveDialTimeout)
+	if err != nil {
+		return nil, false, nil
+	}
+	defer conn.Close()
+
+	env := make(map[string]string)
+	for _, k := range serveEnvVars() {
+		if v, ok := os.LookupEnv(k); ok {
+			env[k] = v
+		}
+	}
+	params, _ := json.Marshal(serveRunParams{
+		Version: version, Dir: dir, Args: args,
+		Render: render, Width: width, DryRun: dryRun, Env: env,
+	})
+	req, _ := json.Marshal(rpcRequest{JSONRPC: "2.0", ID: json.RawMessage("1"), Method: "run", Params: params})
+	if _, err := conn.Write(append(req, '\n')); err != nil {
+		return nil, false, nil
+	}
+
+	sc := bufio.NewScanner(conn)
+	sc.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	if !sc.Scan() {
+		return nil, true, fmt.Errorf("bw serve at %s closed the connection", path)
+	}
+	var resp struct {
+		Result *serveRunResult `json:"result"`
+		Error  *rpcError       `json:"error"`
+	}
+	if err := json.Unmarshal(sc.Bytes(), &resp); err != nil {
+		return nil, true, fmt.Errorf("bw serve at %s: %w", path, err)
+	}
+	if resp.Error != nil || resp.Result == nil {
+		return nil, false, nil
+	}
+	return resp.Result, true, nil
+}
+
+// forwardDir is the absolute directory a forwarded command runs in.
+func forwardDir() string {
+	dir := repoDir
+	if dir == "" {
+		dir, _ = os.Getwd()
+	}
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	return dir
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jallum/beadwork/internal/issue"
+	"github.com/jallum/beadwork/internal/testutil"
+)
+
+// startTestServer runs a daemon in-process and points BW_SOCKET at it.
+func startTestServer(t *testing.T) (*server, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "bw.sock")
+	ln, err := listenSocket(path)
+	if err != nil {
+		t.Fatalf("listenSocket: %v", err)
+	}
+	sv := newServer()
+	done := make(chan error, 1)
+	go func() { done <- serveSocket(sv, ln) }()
+	t.Cleanup(func() {
+		ln.Close()
+		if err := <-done; err != nil {
+			t.Errorf("serveSocket: %v", err)
+		}
+	})
+	t.Setenv("BW_SOCKET", path)
+	return sv, path
+}
+
+func TestServeForwardsCommands(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+	startTestServer(t)
+
+	res, ok, err := forwardToServer(env.Dir, []string{"create", "Via daemon", "--json"}, "markdown", 80, false)
+	if err != nil || !ok {
+		t.Fatalf("forward: ok=%v err=%v", ok, err)
+	}
+	if res.Error != "" {
+		t.Fatalf("create: %s", res.Error)
+	}
+	var created issue.Issue
+	if err := json.Unmarshal([]byte(res.Output), &created); err != nil {
+		t.Fatalf("create output: %q", res.Output)
+	}
+	if got, err := env.Store.Get(created.ID); err != nil || got.Title != "Via daemon" {
+		t.Errorf("issue not committed: %v", err)
+	}
+
+	// Command errors come back as results, not as a fallback.
+	res, ok, _ = forwardToServer(env.Dir, []string{"show", "test-nope"}, "markdown", 80, false)
+	if !ok || res.Error == "" {
+		t.Errorf("show missing issue: ok=%v res=%+v", ok, res)
+	}
+}
+
+func TestServeSeesOtherWriters(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+	startTestServer(t)
+
+	// Warm the daemon's store, then commit behind its back.
+	if _, ok, _ := forwardToServer(env.Dir, []string{"list", "--json"}, "markdown", 80, false); !ok {
+		t.Fatal("list not forwarded")
+	}
+	iss, _ := env.Store.Create("Written elsewhere", issue.CreateOpts{})
+	env.Repo.Commit("create " + iss.ID)
+
+	res, _, _ := forwardToServer(env.Dir, []string{"list", "--json"}, "markdown", 80, false)
+	if !strings.Contains(res.Output, iss.ID) {
+		t.Errorf("daemon missed external commit: %s", res.Output)
+	}
+}
+
+func TestServeRefusals(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+	sv, _ := startTestServer(t)
+
+	// Refused requests fall back to running locally.
+	if _, ok, err := forwardToServer(env.Dir, []string{"sync"}, "markdown", 80, false); ok || err != nil {
+		t.Errorf("sync forwarded: ok=%v err=%v", ok, err)
+	}
+	// template reads files and stdin, which only the client can see.
+	if _, ok, err := forwardToServer(env.Dir, []string{"template", "save", "bug", "bug.json"}, "markdown", 80, false); ok || err != nil {
+		t.Errorf("template forwarded: ok=%v err=%v", ok, err)
+	}
+	if _, rerr := sv.run(serveRunParams{Version: "0.0.0", Dir: env.Dir, Args: []string{"list"}}); rerr == nil {
+		t.Error("expected version mismatch to be refused")
+	}
+
+	t.Setenv("BW_SOCKET", filepath.Join(t.TempDir(), "none.sock"))
+	if _, ok, _ := forwardToServer(env.Dir, []string{"list"}, "markdown", 80, false); ok {
+		t.Error("forwarded without a socket")
+	}
+}
+
+func TestServeAppliesClientEnv(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+	sv := newServer()
+	os.Unsetenv("BW_CLOCK")
+
+	res, rerr := sv.run(serveRunParams{
+		Version: version, Dir: env.Dir,
+		Args: []string{"create", "Clocked", "--json"},
+		Env:  map[string]string{"BW_CLOCK": "2030-01-02T03:04:05Z"},
+	})
+	if rerr != nil || res.Error != "" {
+		t.Fatalf("run: %v %+v", rerr, res)
+	}
+	if !strings.Contains(res.Output, `"created": "2030-01-02T03:04:05Z"`) {
+		t.Errorf("client clock not applied: %s", res.Output)
+	}
+	if _, ok := os.LookupEnv("BW_CLOCK"); ok {
+		t.Error("BW_CLOCK leaked into the daemon's environment")
+	}
+}
+
+func TestListenSocketReplacesStaleFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bw.sock")
+	os.WriteFile(path, nil, 0644)
+	ln, err := listenSocket(path)
+	if err != nil {
+		t.Fatalf("listenSocket over stale file: %v", err)
+	}
+	defer ln.Close()
+	if _, err := listenSocket(path); err == nil || !strings.Contains(err.Error(), "already listening") {
+		t.Errorf("second listener: err = %v", err)
+	}
+}
+
+func TestServeKeepsStatusIconsPerRepo(t *testing.T) {
+	custom := testutil.NewEnv(t)
+	defer custom.Cleanup()
+	plain := testutil.NewEnv(t)
+	defer plain.Cleanup()
+	startTestServer(t)
+
+	custom.Repo.SetConfig("status.open.icon", "★")
+	custom.CommitIntent("config status.open.icon=★")
+	for _, env := range []*testutil.Env{custom, plain} {
+		iss, _ := env.Store.Create("Icon check", issue.CreateOpts{})
+		env.CommitIntent("create " + iss.ID)
+	}
+
+	list := func(dir string) string {
+		t.Helper()
+		res, ok, err := forwardToServer(dir, []string{"list"}, "markdown", 80, false)
+		if !ok || err != nil {
+			t.Fatalf("list not forwarded: ok=%v err=%v", ok, err)
+		}
+		return res.Output
+	}
+	if out := list(custom.Dir); !strings.Contains(out, "★") {
+		t.Errorf("custom repo should use its icon:\n%s", out)
+	}
+	if out := list(plain.Dir); strings.Contains(out, "★") || !strings.Contains(out, "○") {
+		t.Errorf("custom icon leaked into another repo:\n%s", out)
+	}
+	// Both stores are cached now; neither may repaint the other.
+	if out := list(custom.Dir); !strings.Contains(out, "★") {
+		t.Errorf("custom repo lost its icon to another repo:\n%s", out)
+	}
+}
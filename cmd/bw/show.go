@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
@@ -9,6 +10,7 @@ import (
 
 	"github.com/jallum/beadwork/internal/issue"
 	"github.com/jallum/beadwork/internal/md"
+	"github.com/jallum/beadwork/internal/repo"
 )
 
 // validShowSections lists section names accepted by --only.
@@ -18,7 +20,9 @@ var validShowSections = map[string]bool{
 	"children":    true,
 	"blockedby":   true,
 	"unblocks":    true,
+	"related":     true,
 	"comments":    true,
+	"commits":     true,
 }
 
 type ShowArgs struct {
@@ -99,9 +103,15 @@ func cmdShow(store *issue.Store, args []string, w Writer, _ *config.Config) (*co
 	if sa.showSection("blockedby") || sa.showSection("unblocks") {
 		showMap(w, iss, store)
 	}
+	if sa.showSection("related") {
+		showRelated(w, iss, store)
+	}
 	if sa.showSection("comments") {
 		showComments(w, iss)
 	}
+	if sa.showSection("commits") {
+		showCommits(w, iss, store)
+	}
 	return nil, nil
 }
 
@@ -150,6 +160,34 @@ func showMap(w Writer, iss *issue.Issue, store *issue.Store) {
 	}
 }
 
+// relatedHeadings maps issue.Relations keys to their show section titles,
+// in display order.
+var relatedHeadings = []struct{ key, title string }{
+	{issue.RelatesTo, "RELATED"},
+	{issue.Duplicates, "DUPLICATE OF"},
+	{issue.Duplicates + "-by", "DUPLICATED BY"},
+	{issue.Supersedes, "SUPERSEDES"},
+	{issue.Supersedes + "-by", "SUPERSEDED BY"},
+}
+
+// showRelated renders one section per relates-to/duplicates/supersedes
+// list on the issue.
+func showRelated(w Writer, iss *issue.Issue, store *issue.Store) {
+	rel := issue.Relations(iss)
+	for _, h := range relatedHeadings {
+		var related []*issue.Issue
+		for _, id := range rel[h.key] {
+			if other, err := store.Get(id); err == nil {
+				related = append(related, other)
+			}
+		}
+		if len(related) > 0 {
+			fmt.Fprintln(w)
+			fmt.Fprintln(w, md.Related(h.title, related))
+		}
+	}
+}
+
 func showComments(w Writer, iss *issue.Issue) {
 	if len(iss.Comments) > 0 {
 		fmt.Fprintln(w)
@@ -157,6 +195,26 @@ func showComments(w Writer, iss *issue.Issue) {
 	}
 }
 
+// showCommits renders the code commits that mention the issue. It is
+// skipped for point-in-time stores, which have no repo to scan, and when
+// the scan fails: show must not fail over a side section.
+func showCommits(w Writer, iss *issue.Issue, store *issue.Store) {
+	r, ok := store.Committer.(*repo.Repo)
+	if !ok {
+		return
+	}
+	commits, err := r.CodeCommits(iss.ID)
+	if err != nil || len(commits) == 0 {
+		return
+	}
+	refs := make([]md.CommitRef, len(commits))
+	for i, c := range commits {
+		refs[i] = md.CommitRef{Hash: c.Hash, Subject: c.Subject, Time: c.Time, Closes: slices.Contains(c.Closes, iss.ID)}
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, md.Commits(refs))
+}
+
 // fprintIssueSummary renders the summary for use by start.go (Phase 3).
 func fprintIssueSummary(w Writer, iss *issue.Issue, now time.Time) {
 	fmt.Fprintln(w, md.IssueSummary(iss, now))
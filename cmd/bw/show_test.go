@@ -164,6 +164,32 @@ func TestCmdShowOnlyInvalid(t *testing.T) {
 	}
 }
 
+func TestCmdShowCommits(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	iss, _ := env.Store.Create("Linked", issue.CreateOpts{})
+	env.Repo.Commit("create " + iss.ID)
+	env.CodeCommit("fix.go", "package fix\n", "Fix the thing\n\nFixes: "+iss.ID)
+
+	var buf bytes.Buffer
+	if _, err := cmdShow(env.Store, []string{iss.ID}, PlainWriter(&buf), nil); err != nil {
+		t.Fatalf("cmdShow: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "COMMITS") || !strings.Contains(out, "Fix the thing (closes)") {
+		t.Errorf("output missing commits section: %q", out)
+	}
+
+	buf.Reset()
+	if _, err := cmdShow(env.Store, []string{iss.ID, "--only", "commits"}, PlainWriter(&buf), nil); err != nil {
+		t.Fatalf("cmdShow --only commits: %v", err)
+	}
+	if strings.Contains(buf.String(), "Linked") || !strings.Contains(buf.String(), "Fix the thing") {
+		t.Errorf("--only commits output = %q", buf.String())
+	}
+}
+
 func TestCmdShowShortRemoved(t *testing.T) {
 	env := testutil.NewEnv(t)
 	defer env.Cleanup()
@@ -217,6 +243,38 @@ func TestCmdShowRichDeps(t *testing.T) {
 	}
 }
 
+func TestCmdShowRelatedSections(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	a, _ := env.Store.Create("Canonical", issue.CreateOpts{})
+	b, _ := env.Store.Create("Copy", issue.CreateOpts{})
+	c, _ := env.Store.Create("Neighbour", issue.CreateOpts{})
+	env.Store.Relate(issue.Duplicates, b.ID, a.ID)
+	env.Store.Relate(issue.RelatesTo, c.ID, a.ID)
+	env.Repo.Commit("create and relate")
+
+	var buf bytes.Buffer
+	if _, err := cmdShow(env.Store, []string{a.ID}, PlainWriter(&buf), nil); err != nil {
+		t.Fatalf("cmdShow: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"RELATED", "Neighbour", "DUPLICATED BY", "Copy"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q: %q", want, out)
+		}
+	}
+
+	buf.Reset()
+	if _, err := cmdShow(env.Store, []string{b.ID, "--only", "related"}, PlainWriter(&buf), nil); err != nil {
+		t.Fatalf("cmdShow --only related: %v", err)
+	}
+	out = buf.String()
+	if !strings.Contains(out, "DUPLICATE OF") || strings.Contains(out, "Copy") {
+		t.Errorf("--only related output = %q", out)
+	}
+}
+
 func TestCmdShowTipsDeepChain(t *testing.T) {
 	env := testutil.NewEnv(t)
 	defer env.Cleanup()
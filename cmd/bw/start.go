@@ -5,7 +5,9 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/jallum/beadwork/internal/agent"
 	"github.com/jallum/beadwork/internal/config"
 
 	"github.com/jallum/beadwork/internal/issue"
@@ -14,25 +16,47 @@ import (
 	"github.com/jallum/beadwork/prompts"
 )
 
+// detectAgent identifies the coding agent recorded on a lease.
+var detectAgent = agent.Detect
+
 type StartArgs struct {
 	ID       string
 	Assignee string
+	Lease    time.Duration
+	Steal    bool
 	JSON     bool
 }
 
 func parseStartArgs(raw []string) (StartArgs, error) {
 	if len(raw) == 0 {
-		return StartArgs{}, fmt.Errorf("usage: bw start <id> [--assignee <name>]")
+		return StartArgs{}, fmt.Errorf("usage: bw start <id> [--assignee <name>] [--lease <duration>] [--steal]")
 	}
-	a, err := ParseArgs(raw[1:], []string{"--assignee"}, []string{"--json"})
+	a, err := ParseArgs(raw[1:], []string{"--assignee", "--lease"}, []string{"--steal", "--json"})
 	if err != nil {
 		return StartArgs{}, err
 	}
-	return StartArgs{
+	sa := StartArgs{
 		ID:       raw[0],
 		Assignee: a.String("--assignee"),
+		Steal:    a.Bool("--steal"),
 		JSON:     a.JSON(),
-	}, nil
+	}
+	if a.Has("--lease") {
+		sa.Lease, err = parseLease(a.String("--lease"))
+		if err != nil {
+			return sa, err
+		}
+	}
+	return sa, nil
+}
+
+// parseLease parses a --lease value such as 30m or 2h.
+func parseLease(v string) (time.Duration, error) {
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid lease %q (use a duration like 30m or 2h)", v)
+	}
+	return d, nil
 }
 
 type StartData struct {
@@ -57,20 +81,31 @@ func cmdStart(store *issue.Store, args []string, w Writer, _ *config.Config) (*c
 		assignee = r.UserName()
 	}
 
+	opts := issue.ClaimOpts{Assignee: assignee, Lease: sa.Lease, Steal: sa.Steal}
+	if sa.Lease > 0 {
+		if a := detectAgent(); a != nil {
+			opts.Agent = a.Name
+		}
+	}
+
 	var iss *issue.Issue
 	err = commitWithRetry(store, commitMaxRetries, func() (string, error) {
 		var serr error
-		iss, serr = store.Start(sa.ID, assignee)
+		iss, serr = store.Claim(sa.ID, opts)
 		if serr != nil {
+			var ce *issue.ClaimedError
+			if errors.As(serr, &ce) {
+				return "", fmt.Errorf("%w\nuse --steal to take it over", ce)
+			}
 			var be *issue.BlockedError
 			if errors.As(serr, &be) {
 				lines := []string{fmt.Sprintf("%s is blocked by:", be.ID)}
 				for _, id := range be.Blockers {
 					dep, derr := store.Get(id)
 					if derr != nil {
-						lines = append(lines, fmt.Sprintf("  %s %s", issue.StatusIcon("open"), id))
+						lines = append(lines, fmt.Sprintf("  %s %s", store.CurrentWorkflow().Icon("open"), id))
 					} else {
-						lines = append(lines, fmt.Sprintf("  %s %s: %s", issue.StatusIcon(dep.Status), id, dep.Title))
+						lines = append(lines, fmt.Sprintf("  %s %s: %s", store.CurrentWorkflow().Icon(dep.Status), id, dep.Title))
 					}
 				}
 				lines = append(lines, "\nuse bw ready to find available work")
@@ -78,7 +113,18 @@ func cmdStart(store *issue.Store, args []string, w Writer, _ *config.Config) (*c
 			}
 			return "", serr
 		}
-		return fmt.Sprintf("start %s assignee=%q", iss.ID, assignee), nil
+		intent := fmt.Sprintf("start %s assignee=%q", iss.ID, assignee)
+		if iss.Lease != nil {
+			// Record the expiry itself so replay reproduces the same lease.
+			intent += fmt.Sprintf(" lease=%s expires=%s", iss.Lease.Duration, iss.Lease.Expires)
+			if iss.Lease.Agent != "" {
+				intent += fmt.Sprintf(" agent=%q", iss.Lease.Agent)
+			}
+		}
+		if sa.Steal {
+			intent += " steal=true"
+		}
+		return intent, nil
 	})
 	if err != nil {
 		return nil, err
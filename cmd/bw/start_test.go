@@ -6,6 +6,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/jallum/beadwork/internal/agent"
 	"github.com/jallum/beadwork/internal/issue"
 	"github.com/jallum/beadwork/internal/testutil"
 )
@@ -317,3 +318,50 @@ func TestCmdStartTaskShowsMap(t *testing.T) {
 		t.Errorf("task output missing unblocked title: %q", out)
 	}
 }
+
+func TestCmdStartLease(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+	t.Setenv("BW_CLOCK", "2027-03-01T10:00:00Z")
+	detectAgent = func() *agent.Agent { return &agent.Agent{Name: "claude-code"} }
+	defer func() { detectAgent = agent.Detect }()
+
+	iss, _ := env.Store.Create("Lease me", issue.CreateOpts{})
+	env.Repo.Commit("create " + iss.ID)
+
+	var buf bytes.Buffer
+	if _, err := cmdStart(env.Store, []string{iss.ID, "--assignee", "alice", "--lease", "2h"}, PlainWriter(&buf), nil); err != nil {
+		t.Fatalf("cmdStart: %v", err)
+	}
+	got, _ := env.Store.Get(iss.ID)
+	want := issue.Lease{Agent: "claude-code", Duration: "2h", Expires: "2027-03-01T12:00:00Z"}
+	if got.Lease == nil || *got.Lease != want {
+		t.Errorf("lease = %+v, want %+v", got.Lease, want)
+	}
+	commits, _ := env.Repo.AllCommits()
+	if msg := commits[0].Message; !strings.Contains(msg, `lease=2h expires=2027-03-01T12:00:00Z agent="claude-code"`) {
+		t.Errorf("intent = %q", msg)
+	}
+
+	// A live claim is refused, then taken with --steal.
+	buf.Reset()
+	_, err := cmdStart(env.Store, []string{iss.ID, "--assignee", "bob"}, PlainWriter(&buf), nil)
+	if err == nil || !strings.Contains(err.Error(), "claimed by alice (claude-code)") || !strings.Contains(err.Error(), "--steal") {
+		t.Fatalf("expected claimed error, got %v", err)
+	}
+	if _, err := cmdStart(env.Store, []string{iss.ID, "--assignee", "bob", "--steal"}, PlainWriter(&buf), nil); err != nil {
+		t.Fatalf("cmdStart --steal: %v", err)
+	}
+	got, _ = env.Store.Get(iss.ID)
+	if got.Assignee != "bob" || got.Lease != nil {
+		t.Errorf("after steal: assignee=%s lease=%+v", got.Assignee, got.Lease)
+	}
+}
+
+func TestParseStartArgsBadLease(t *testing.T) {
+	for _, v := range []string{"soon", "0s", "-1h"} {
+		if _, err := parseStartArgs([]string{"bw-1", "--lease", v}); err == nil {
+			t.Errorf("--lease %s: expected error", v)
+		}
+	}
+}
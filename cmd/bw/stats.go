@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jallum/beadwork/internal/config"
+
+	"github.com/jallum/beadwork/internal/issue"
+	"github.com/jallum/beadwork/internal/recap"
+	"github.com/jallum/beadwork/internal/stats"
+)
+
+// statsDefaultWindow is used when bw stats is given no window.
+const statsDefaultWindow = "4w"
+
+// statsBarWidth is the width of the longest burndown bar.
+const statsBarWidth = 40
+
+type StatsArgs struct {
+	Tokens   []string
+	Since    string
+	By       string
+	Burndown string
+	JSON     bool
+}
+
+func parseStatsArgs(raw []string) (StatsArgs, error) {
+	a, err := ParseArgs(raw, []string{"--since", "--by", "--burndown"}, []string{"--json"})
+	if err != nil {
+		return StatsArgs{}, err
+	}
+	sa := StatsArgs{
+		Tokens:   a.Pos(),
+		Since:    a.String("--since"),
+		By:       a.String("--by"),
+		Burndown: a.String("--burndown"),
+		JSON:     a.JSON(),
+	}
+	if sa.By != "" && !slices.Contains(stats.GroupKeys, sa.By) {
+		return StatsArgs{}, fmt.Errorf("invalid --by %q (expected %s)", sa.By, strings.Join(stats.GroupKeys, ", "))
+	}
+	return sa, nil
+}
+
+// statsLookup adapts an *issue.Store to stats.IssueLookup.
+type statsLookup struct {
+	store *issue.Store
+}
+
+func (l statsLookup) Issue(id string) *issue.Issue {
+	iss, err := l.store.Get(id)
+	if err != nil {
+		return nil
+	}
+	return iss
+}
+
+func (l statsLookup) Category(status string) string {
+	return l.store.CurrentWorkflow().Category(status)
+}
+
+func cmdStats(store *issue.Store, args []string, w Writer, _ *config.Config) (*config.Config, error) {
+	sa, err := parseStatsArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	tokens := sa.Tokens
+	if len(tokens) == 0 {
+		tokens = []string{statsDefaultWindow}
+	}
+	window, err := recap.ParseWindow(tokens, sa.Since, store.Now())
+	if err != nil {
+		return nil, err
+	}
+	if sa.Burndown != "" {
+		root, err := store.Get(sa.Burndown)
+		if err != nil {
+			return nil, err
+		}
+		sa.Burndown = root.ID
+	}
+	commits, err := store.FS.AllCommits()
+	if err != nil {
+		return nil, fmt.Errorf("reading history: %w", err)
+	}
+
+	st := stats.Build(commits, window, statsLookup{store}, stats.Options{By: sa.By, Burndown: sa.Burndown})
+	if sa.JSON {
+		fprintJSON(w, st)
+		return nil, nil
+	}
+
+	day := "2006-01-02"
+	fmt.Fprintf(w, "Stats for %s (%s – %s)\n\n", window.Label, window.Start.Format(day), window.End.Format(day))
+	fmt.Fprintf(w, "Created: %d  Closed: %d\n", st.Created, st.Closed)
+	fmt.Fprintf(w, "Lead time:  %s\n", formatDurations(st.Lead))
+	fmt.Fprintf(w, "Cycle time: %s\n", formatDurations(st.Cycle))
+
+	fmt.Fprintf(w, "\n%-12s %7s %7s %5s\n", "Week of", "Created", "Closed", "WIP")
+	for _, wk := range st.Weeks {
+		fmt.Fprintf(w, "%-12s %7d %7d %5d\n", wk.Start.Format(day), wk.Created, wk.Closed, wk.WIP)
+	}
+
+	if st.By != "" {
+		fmt.Fprintf(w, "\nBy %s:\n", st.By)
+		w.Push(2)
+		if len(st.Groups) == 0 {
+			fmt.Fprintln(w, "nothing closed")
+		}
+		for _, g := range st.Groups {
+			fmt.Fprintf(w, "%-16s closed %d  lead %s  cycle %s\n", g.Key, g.Closed, formatDurations(g.Lead), formatDurations(g.Cycle))
+		}
+		w.Pop()
+	}
+
+	if st.Burndown != nil {
+		fmt.Fprintf(w, "\nBurndown for {id:%s}:\n", st.Burndown.Root)
+		most := 0
+		for _, p := range st.Burndown.Points {
+			most = max(most, p.Open)
+		}
+		w.Push(2)
+		for _, p := range st.Burndown.Points {
+			bar := 0
+			if most > 0 {
+				bar = (p.Open*statsBarWidth + most - 1) / most
+			}
+			fmt.Fprintf(w, "%s  %s %d\n", p.Time.Format(day), strings.Repeat("█", bar), p.Open)
+		}
+		w.Pop()
+	}
+	return nil, nil
+}
+
+func formatDurations(d stats.Durations) string {
+	if d.Count == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("median %.1fd, p85 %.1fd (%d)", d.Median, d.P85, d.Count)
+}
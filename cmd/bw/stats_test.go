@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/jallum/beadwork/internal/issue"
+	"github.com/jallum/beadwork/internal/stats"
+	"github.com/jallum/beadwork/internal/testutil"
+)
+
+func TestCmdStats(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	var buf bytes.Buffer
+	w := PlainWriter(&buf)
+	epic, _ := env.Store.Create("Epic", issue.CreateOpts{Type: "epic"})
+	env.Repo.Commit("create " + epic.ID)
+	bug, _ := env.Store.Create("Crash", issue.CreateOpts{Type: "bug", Parent: epic.ID})
+	env.Repo.Commit("create " + bug.ID)
+	cmdStart(env.Store, []string{bug.ID}, w, nil)
+	cmdClose(env.Store, []string{bug.ID}, w, nil)
+
+	buf.Reset()
+	if _, err := cmdStats(env.Store, []string{"--by", "type", "--burndown", epic.ID}, w, nil); err != nil {
+		t.Fatalf("stats: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"Stats for last 4w",
+		"Created: 2  Closed: 1",
+		"Lead time:  median 0.0d, p85 0.0d (1)",
+		"Week of",
+		"By type:",
+		"bug ",
+		"Burndown for " + epic.ID + ":",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	if _, err := cmdStats(env.Store, []string{"7d", "--json"}, w, nil); err != nil {
+		t.Fatalf("stats --json: %v", err)
+	}
+	var st stats.Stats
+	if err := json.Unmarshal(buf.Bytes(), &st); err != nil {
+		t.Fatalf("unmarshal: %v\n%s", err, buf.String())
+	}
+	if st.Closed != 1 || st.Cycle.Count != 1 || len(st.Weeks) != 1 {
+		t.Errorf("json = %+v", st)
+	}
+}
+
+func TestParseStatsArgs(t *testing.T) {
+	sa, err := parseStatsArgs([]string{"12w", "--by", "label"})
+	if err != nil || len(sa.Tokens) != 1 || sa.By != "label" {
+		t.Errorf("parse = %+v, %v", sa, err)
+	}
+	if _, err := parseStatsArgs([]string{"--by", "color"}); err == nil {
+		t.Error("expected error for unknown --by")
+	}
+}
@@ -55,6 +55,9 @@ func cmdSync(store *issue.Store, args []string, w Writer, _ *config.Config) (*co
 			r.ClearPreReplayHash()
 		}()
 
+		// Replayed intents already ran their hooks when first committed.
+		store.Hooks = nil
+
 		fmt.Fprintf(w, "rebase conflict — replaying %d intent(s)...\n", len(intents))
 		errs := intent.Replay(store, intents)
 		if len(errs) > 0 {
@@ -0,0 +1,275 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/jallum/beadwork/internal/config"
+
+	"github.com/jallum/beadwork/internal/issue"
+	"github.com/jallum/beadwork/internal/tmpl"
+)
+
+const templateUsage = "usage: bw template save|list|show|delete"
+
+// templateStdin is read by bw template save -; tests replace it.
+var templateStdin io.Reader = os.Stdin
+
+type TemplateArgs struct {
+	Subcmd string // "save", "list", "show", "delete"
+	Name   string // for save/show/delete
+	File   string // for save; "-" reads stdin
+	JSON   bool   // for list
+}
+
+func parseTemplateArgs(raw []string) (TemplateArgs, error) {
+	if len(raw) == 0 {
+		return TemplateArgs{}, errors.New(templateUsage)
+	}
+	ta := TemplateArgs{Subcmd: raw[0]}
+	switch ta.Subcmd {
+	case "save":
+		if len(raw) != 3 {
+			return ta, fmt.Errorf("usage: bw template save <name> <file>")
+		}
+		ta.Name, ta.File = raw[1], raw[2]
+	case "show", "delete":
+		if len(raw) != 2 {
+			return ta, fmt.Errorf("usage: bw template %s <name>", ta.Subcmd)
+		}
+		ta.Name = raw[1]
+	case "list":
+		a, err := ParseArgs(raw[1:], nil, []string{"--json"})
+		if err != nil {
+			return ta, err
+		}
+		ta.JSON = a.JSON()
+	default:
+		return ta, errors.New(templateUsage)
+	}
+	return ta, nil
+}
+
+func cmdTemplate(store *issue.Store, args []string, w Writer, _ *config.Config) (*config.Config, error) {
+	ta, err := parseTemplateArgs(args)
+	if err != nil {
+		return nil, err
+	}
+
+	switch ta.Subcmd {
+	case "save":
+		if err := issue.ValidateTemplateName(ta.Name); err != nil {
+			return nil, err
+		}
+		data, err := readTemplateFile(ta.File)
+		if err != nil {
+			return nil, err
+		}
+		t, err := issue.DecodeTemplate(ta.Name, data)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkTemplate(t); err != nil {
+			return nil, err
+		}
+		err = commitWithRetry(store, commitMaxRetries, func() (string, error) {
+			if err := store.SaveTemplate(ta.Name, t); err != nil {
+				return "", err
+			}
+			encoded, err := t.Encode()
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("template save %s %q", ta.Name, encoded), nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(w, "saved template %s\n", ta.Name)
+
+	case "delete":
+		err := commitWithRetry(store, commitMaxRetries, func() (string, error) {
+			if err := store.DeleteTemplate(ta.Name); err != nil {
+				return "", err
+			}
+			return "template delete " + ta.Name, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(w, "deleted template %s\n", ta.Name)
+
+	case "show":
+		t, err := store.GetTemplate(ta.Name)
+		if err != nil {
+			return nil, err
+		}
+		fprintJSON(w, t)
+
+	case "list":
+		templates, err := store.Templates()
+		if err != nil {
+			return nil, err
+		}
+		if ta.JSON {
+			fprintJSON(w, templates)
+			return nil, nil
+		}
+		if len(templates) == 0 {
+			fmt.Fprintln(w, "no templates")
+			return nil, nil
+		}
+		for _, t := range templates {
+			fmt.Fprintf(w, "%-16s %s\n", t.Name, templateSummary(t))
+		}
+	}
+	return nil, nil
+}
+
+func readTemplateFile(path string) ([]byte, error) {
+	if path == "-" {
+		return io.ReadAll(templateStdin)
+	}
+	return os.ReadFile(path)
+}
+
+// templateSummary describes a template on one line: its defaults and how
+// many issues it creates beneath the new one.
+func templateSummary(t *issue.Template) string {
+	var parts []string
+	if t.Type != "" {
+		parts = append(parts, t.Type)
+	}
+	if t.Priority != nil {
+		parts = append(parts, fmt.Sprintf("p%d", *t.Priority))
+	}
+	if len(t.Labels) > 0 {
+		parts = append(parts, "labels:"+strings.Join(t.Labels, ","))
+	}
+	if n := countTemplateChildren(t); n > 0 {
+		parts = append(parts, fmt.Sprintf("+%d children", n))
+	}
+	return strings.Join(parts, " ")
+}
+
+func countTemplateChildren(t *issue.Template) int {
+	n := 0
+	for _, c := range t.Children {
+		n += 1 + countTemplateChildren(c)
+	}
+	return n
+}
+
+// templateData is what template titles and descriptions are rendered
+// with.
+type templateData struct {
+	Title  string // the title given to bw create
+	Parent string // ID of the issue's parent, empty at the root unless --parent
+	Date   string // today, YYYY-MM-DD
+}
+
+func renderTemplateText(name, src string, data templateData) (string, error) {
+	if src == "" {
+		return "", nil
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, name, src, data, nil); err != nil {
+		return "", fmt.Errorf("template %s: %w", name, err)
+	}
+	return strings.TrimRight(b.String(), "\n"), nil
+}
+
+// checkTemplate renders every title and description of t against sample
+// data, so a broken template is refused when saved rather than when used.
+func checkTemplate(t *issue.Template) error {
+	if err := t.Validate(); err != nil {
+		return err
+	}
+	data := templateData{Title: "Title", Parent: "bw-0000", Date: "2006-01-02"}
+	var check func(node *issue.Template) error
+	check = func(node *issue.Template) error {
+		for _, src := range []string{node.Title, node.Description} {
+			if _, err := renderTemplateText(t.Name, src, data); err != nil {
+				return err
+			}
+		}
+		for _, c := range node.Children {
+			if err := check(c); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return check(t)
+}
+
+// createFromTemplate creates the issue described by ca with t's defaults
+// filled in, then t's children beneath it. Values given on the command
+// line win over the template's; the template's labels are added to any
+// given with --labels. It returns every issue created, parents first.
+func createFromTemplate(store *issue.Store, t *issue.Template, ca CreateArgs, opts issue.CreateOpts) ([]*issue.Issue, error) {
+	data := templateData{Title: ca.Title, Parent: opts.Parent, Date: store.Now().Format("2006-01-02")}
+	title := ca.Title
+	if t.Title != "" {
+		var err error
+		if title, err = renderTemplateText(t.Name, t.Title, data); err != nil {
+			return nil, err
+		}
+	}
+	if opts.Type == "" {
+		opts.Type = t.Type
+	}
+	if opts.Priority == nil {
+		opts.Priority = t.Priority
+	}
+	if opts.Description == "" {
+		var err error
+		if opts.Description, err = renderTemplateText(t.Name, t.Description, data); err != nil {
+			return nil, err
+		}
+	}
+	labels := append(append([]string(nil), t.Labels...), ca.Labels...)
+
+	root, err := createLabeled(store, title, opts, labels)
+	if err != nil {
+		return nil, err
+	}
+	created := []*issue.Issue{root}
+
+	var addChildren func(parent *issue.Issue, children []*issue.Template) error
+	addChildren = func(parent *issue.Issue, children []*issue.Template) error {
+		d := data
+		d.Parent = parent.ID
+		for _, c := range children {
+			title, err := renderTemplateText(t.Name, c.Title, d)
+			if err != nil {
+				return err
+			}
+			desc, err := renderTemplateText(t.Name, c.Description, d)
+			if err != nil {
+				return err
+			}
+			child, err := createLabeled(store, title, issue.CreateOpts{
+				Parent:      parent.ID,
+				Type:        c.Type,
+				Priority:    c.Priority,
+				Description: desc,
+			}, c.Labels)
+			if err != nil {
+				return err
+			}
+			created = append(created, child)
+			if err := addChildren(child, c.Children); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := addChildren(root, t.Children); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jallum/beadwork/internal/intent"
+	"github.com/jallum/beadwork/internal/issue"
+	"github.com/jallum/beadwork/internal/testutil"
+)
+
+const epicTemplate = `{
+  "type": "epic",
+  "priority": 1,
+  "labels": ["roadmap"],
+  "description": "Goal: {{.Title}}\n\nStarted {{.Date}}.",
+  "children": [
+    {"title": "Design {{.Title}}", "labels": ["design"]},
+    {"title": "Build {{.Title}}", "type": "task", "priority": 2, "children": [
+      {"title": "Tests for {{.Parent}}"}
+    ]}
+  ]
+}`
+
+func saveTestTemplate(t *testing.T, store *issue.Store, name, doc string) {
+	t.Helper()
+	orig := templateStdin
+	templateStdin = strings.NewReader(doc)
+	t.Cleanup(func() { templateStdin = orig })
+	var buf bytes.Buffer
+	if _, err := cmdTemplate(store, []string{"save", name, "-"}, PlainWriter(&buf), nil); err != nil {
+		t.Fatalf("template save: %v", err)
+	}
+}
+
+func TestCmdTemplateSaveShowListDelete(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	path := filepath.Join(t.TempDir(), "epic.json")
+	os.WriteFile(path, []byte(epicTemplate), 0644)
+	var buf bytes.Buffer
+	if _, err := cmdTemplate(env.Store, []string{"save", "epic", path}, PlainWriter(&buf), nil); err != nil {
+		t.Fatalf("template save: %v", err)
+	}
+	commits, _ := env.Repo.AllCommits()
+	if !strings.HasPrefix(commits[0].Message, `template save epic "{\"type\":\"epic\"`) {
+		t.Errorf("intent = %q", commits[0].Message)
+	}
+
+	buf.Reset()
+	cmdTemplate(env.Store, []string{"list"}, PlainWriter(&buf), nil)
+	if !strings.Contains(buf.String(), "epic") {
+		t.Errorf("output = %q, want %q", buf.String(), "epic")
+	}
+	if !strings.Contains(buf.String(), "p1 labels:roadmap +3 children") {
+		t.Errorf("output = %q, want %q", buf.String(), "p1 labels:roadmap +3 children")
+	}
+
+	buf.Reset()
+	cmdTemplate(env.Store, []string{"show", "epic"}, PlainWriter(&buf), nil)
+	var shown issue.Template
+	if err := json.Unmarshal(buf.Bytes(), &shown); err != nil {
+		t.Fatalf("show output is not JSON: %v\n%s", err, buf.String())
+	}
+	if shown.Name != "epic" || len(shown.Children) != 2 {
+		t.Errorf("show = %+v", shown)
+	}
+
+	buf.Reset()
+	if _, err := cmdTemplate(env.Store, []string{"delete", "epic"}, PlainWriter(&buf), nil); err != nil {
+		t.Fatalf("template delete: %v", err)
+	}
+	buf.Reset()
+	cmdTemplate(env.Store, []string{"list"}, PlainWriter(&buf), nil)
+	if !strings.Contains(buf.String(), "no templates") {
+		t.Errorf("output = %q, want %q", buf.String(), "no templates")
+	}
+}
+
+func TestCmdTemplateSaveRejectsBrokenTemplate(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	for _, doc := range []string{
+		`{"description": "{{ .Title"}`,
+		`{"children": [{"title": "{{ .Nope }}"}]}`,
+		`{"children": [{"type": "task"}]}`,
+		`not json`,
+	} {
+		orig := templateStdin
+		templateStdin = strings.NewReader(doc)
+		var buf bytes.Buffer
+		_, err := cmdTemplate(env.Store, []string{"save", "broken", "-"}, PlainWriter(&buf), nil)
+		templateStdin = orig
+		if err == nil {
+			t.Errorf("template save %s: expected error", doc)
+		}
+	}
+}
+
+func TestCmdCreateFromTemplate(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+	t.Setenv("BW_CLOCK", "2027-03-10T12:00:00Z")
+	saveTestTemplate(t, env.Store, "epic", epicTemplate)
+
+	var buf bytes.Buffer
+	if _, err := cmdCreate(env.Store, []string{"Search", "--template", "epic", "--labels", "q3"}, PlainWriter(&buf), nil); err != nil {
+		t.Fatalf("cmdCreate --template: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("output = %q, want 4 created lines", buf.String())
+	}
+
+	commits, _ := env.Repo.AllCommits()
+	intents := strings.Split(commits[0].Message, "\n")
+	if len(intents) != 4 {
+		t.Fatalf("intent = %q, want one create line per issue in a single commit", commits[0].Message)
+	}
+
+	root, _ := env.Store.Get(strings.Fields(intents[0])[1])
+	if root.Title != "Search" || root.Type != "epic" || root.Priority != 1 {
+		t.Errorf("root = %q %s p%d", root.Title, root.Type, root.Priority)
+	}
+	if root.Description != "Goal: Search\n\nStarted 2027-03-10." {
+		t.Errorf("description = %q", root.Description)
+	}
+	if strings.Join(root.Labels, ",") != "q3,roadmap" {
+		t.Errorf("labels = %v, want [q3 roadmap]", root.Labels)
+	}
+
+	design, _ := env.Store.Get(strings.Fields(intents[1])[1])
+	build, _ := env.Store.Get(strings.Fields(intents[2])[1])
+	tests, _ := env.Store.Get(strings.Fields(intents[3])[1])
+	if design.Title != "Design Search" || design.Parent != root.ID || design.Labels[0] != "design" {
+		t.Errorf("design = %q parent=%s labels=%v", design.Title, design.Parent, design.Labels)
+	}
+	if build.Title != "Build Search" || build.Parent != root.ID || build.Priority != 2 {
+		t.Errorf("build = %q parent=%s p%d", build.Title, build.Parent, build.Priority)
+	}
+	if tests.Title != "Tests for "+build.ID || tests.Parent != build.ID {
+		t.Errorf("tests = %q parent=%s", tests.Title, tests.Parent)
+	}
+
+	// Replaying the intent into a fresh repo recreates the same tree.
+	fresh := testutil.NewEnv(t)
+	defer fresh.Cleanup()
+	if errs := intent.Replay(fresh.Store, []string{commits[0].Message}); len(errs) > 0 {
+		t.Fatalf("Replay: %v", errs)
+	}
+	for _, want := range []*issue.Issue{root, design, build, tests} {
+		got, err := fresh.Store.Get(want.ID)
+		if err != nil {
+			t.Errorf("replayed %s: %v", want.ID, err)
+			continue
+		}
+		if got.Title != want.Title || got.Parent != want.Parent || got.Description != want.Description ||
+			strings.Join(got.Labels, ",") != strings.Join(want.Labels, ",") {
+			t.Errorf("replayed %s = %+v, want %+v", want.ID, got, want)
+		}
+	}
+}
+
+func TestCmdCreateTemplateFlagsWin(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+	saveTestTemplate(t, env.Store, "bug", `{"type": "bug", "priority": 1, "description": "Steps:"}`)
+
+	var buf bytes.Buffer
+	_, err := cmdCreate(env.Store, []string{"Crash", "--template", "bug", "-p", "0", "--description", "Just crashes", "--json"}, PlainWriter(&buf), nil)
+	if err != nil {
+		t.Fatalf("cmdCreate: %v", err)
+	}
+	var got issue.Issue
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("JSON parse: %v", err)
+	}
+	if got.Type != "bug" || got.Priority != 0 || got.Description != "Just crashes" {
+		t.Errorf("got %s p%d %q", got.Type, got.Priority, got.Description)
+	}
+}
+
+func TestCmdCreateUnknownTemplate(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	var buf bytes.Buffer
+	_, err := cmdCreate(env.Store, []string{"X", "--template", "nope"}, PlainWriter(&buf), nil)
+	if err == nil || !strings.Contains(err.Error(), "no such template: nope") {
+		t.Errorf("err = %v", err)
+	}
+}
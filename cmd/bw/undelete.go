@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/jallum/beadwork/internal/config"
+
+	"github.com/jallum/beadwork/internal/issue"
+)
+
+type UndeleteArgs struct {
+	ID   string
+	JSON bool
+}
+
+func parseUndeleteArgs(raw []string) (UndeleteArgs, error) {
+	if len(raw) == 0 {
+		return UndeleteArgs{}, fmt.Errorf("usage: bw undelete <id>")
+	}
+	a, err := ParseArgs(raw[1:], nil, []string{"--json"})
+	if err != nil {
+		return UndeleteArgs{}, err
+	}
+	return UndeleteArgs{ID: raw[0], JSON: a.JSON()}, nil
+}
+
+func cmdUndelete(store *issue.Store, args []string, w Writer, _ *config.Config) (*config.Config, error) {
+	ua, err := parseUndeleteArgs(args)
+	if err != nil {
+		return nil, err
+	}
+
+	var iss *issue.Issue
+	var dropped []string
+	err = commitWithRetry(store, commitMaxRetries, func() (string, error) {
+		var uerr error
+		iss, dropped, uerr = store.Undelete(ua.ID)
+		if uerr != nil {
+			return "", uerr
+		}
+		return fmt.Sprintf("undelete %s", iss.ID), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if ua.JSON {
+		fprintJSON(w, iss)
+		return nil, nil
+	}
+	fmt.Fprintf(w, "restored %s: %s\n", w.Style(iss.ID, Cyan), iss.Title)
+	if len(dropped) > 0 {
+		fmt.Fprintf(w, "\nLinks not restored: %d\n", len(dropped))
+		w.Push(2)
+		for _, d := range dropped {
+			fmt.Fprintln(w, d)
+		}
+		w.Pop()
+	}
+	return nil, nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/jallum/beadwork/internal/issue"
+	"github.com/jallum/beadwork/internal/testutil"
+)
+
+func TestParseUndeleteArgs(t *testing.T) {
+	ua, err := parseUndeleteArgs([]string{"bw-1234", "--json"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ua.ID != "bw-1234" || !ua.JSON {
+		t.Errorf("args = %+v", ua)
+	}
+	if _, err := parseUndeleteArgs(nil); err == nil {
+		t.Error("expected error for missing id")
+	}
+}
+
+func TestCmdUndelete(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	iss, _ := env.Store.Create("Bring me back", issue.CreateOpts{})
+	env.Repo.Commit("create " + iss.ID)
+	env.Store.Delete(iss.ID)
+	env.Repo.Commit("delete " + iss.ID)
+
+	var buf bytes.Buffer
+	if _, err := cmdUndelete(env.Store, []string{iss.ID}, PlainWriter(&buf), nil); err != nil {
+		t.Fatalf("cmdUndelete: %v", err)
+	}
+	if !strings.Contains(buf.String(), "restored "+iss.ID) {
+		t.Errorf("output = %q", buf.String())
+	}
+	if _, err := env.Store.Get(iss.ID); err != nil {
+		t.Errorf("issue not restored: %v", err)
+	}
+	commits, _ := env.Repo.AllCommits()
+	if got := commits[0].Message; got != "undelete "+iss.ID {
+		t.Errorf("commit message = %q", got)
+	}
+}
+
+func TestCmdListDeleted(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	a, _ := env.Store.Create("Deleted one", issue.CreateOpts{})
+	b, _ := env.Store.Create("Still here", issue.CreateOpts{})
+	env.Repo.Commit("create " + a.ID + "\ncreate " + b.ID)
+	env.Store.Delete(a.ID)
+	env.Repo.Commit("delete " + a.ID)
+
+	var buf bytes.Buffer
+	if _, err := cmdList(env.Store, []string{"--deleted"}, PlainWriter(&buf), nil); err != nil {
+		t.Fatalf("cmdList: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, a.ID) || !strings.Contains(out, "deleted ") {
+		t.Errorf("output should list %s as deleted: %q", a.ID, out)
+	}
+	if strings.Contains(out, b.ID) {
+		t.Errorf("output should not list %s: %q", b.ID, out)
+	}
+
+	buf.Reset()
+	if _, err := cmdList(env.Store, []string{"--deleted", "--json"}, PlainWriter(&buf), nil); err != nil {
+		t.Fatalf("cmdList --json: %v", err)
+	}
+	var got []map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(got) != 1 || got[0]["id"] != a.ID || got[0]["deleted_at"] == nil {
+		t.Errorf("JSON = %v", got)
+	}
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/jallum/beadwork/internal/config"
+	"github.com/jallum/beadwork/internal/intent"
+	"github.com/jallum/beadwork/internal/issue"
+	"github.com/jallum/beadwork/internal/repo"
+	"github.com/jallum/beadwork/internal/treefs"
+)
+
+// UndoArgs holds parsed arguments for the undo command.
+type UndoArgs struct {
+	Count  int    // number of most recent commits to undo (when Commit is empty)
+	Commit string // commit hash or prefix to undo
+	JSON   bool
+}
+
+func parseUndoArgs(raw []string) (UndoArgs, error) {
+	a, err := ParseArgs(raw, nil, []string{"--json"})
+	if err != nil {
+		return UndoArgs{}, err
+	}
+	ua := UndoArgs{Count: 1, JSON: a.JSON()}
+	target := a.PosFirst()
+	if target == "" {
+		return ua, nil
+	}
+	// Short all-digit arguments are counts; anything else is a commit.
+	if n, err := strconv.Atoi(target); err == nil && len(target) < 7 {
+		if n < 1 {
+			return UndoArgs{}, fmt.Errorf("undo count must be at least 1")
+		}
+		ua.Count = n
+		return ua, nil
+	}
+	if len(target) < 4 {
+		return UndoArgs{}, fmt.Errorf("commit %q is too short (need at least 4 characters)", target)
+	}
+	ua.Commit = strings.ToLower(target)
+	ua.Count = 0
+	return ua, nil
+}
+
+type undoEntry struct {
+	Commit  string   `json:"commit"`
+	Intent  string   `json:"intent"`
+	Inverse []string `json:"inverse"`
+}
+
+func cmdUndo(store *issue.Store, args []string, w Writer, _ *config.Config) (*config.Config, error) {
+	ua, err := parseUndoArgs(args)
+	if err != nil {
+		return nil, err
+	}
+
+	var undone []undoEntry
+	err = commitWithRetry(store, commitMaxRetries, func() (string, error) {
+		r := store.Committer.(*repo.Repo)
+		commits, cerr := r.AllCommits()
+		if cerr != nil {
+			return "", fmt.Errorf("reading history: %w", cerr)
+		}
+		targets, cerr := undoTargets(commits, ua)
+		if cerr != nil {
+			return "", cerr
+		}
+
+		undone = nil
+		var lines []string
+		for _, c := range targets {
+			inv, cerr := inverseOf(store.FS, c)
+			if cerr != nil {
+				return "", cerr
+			}
+			undone = append(undone, undoEntry{Commit: c.Hash, Intent: firstLine(c.Message), Inverse: inv})
+			lines = append(lines, inv...)
+		}
+		if len(lines) == 0 {
+			return "", fmt.Errorf("nothing to undo: commit(s) made no reversible change")
+		}
+		for _, line := range lines {
+			if aerr := intent.Apply(store, line); aerr != nil {
+				return "", fmt.Errorf("applying %q: %w", line, aerr)
+			}
+		}
+		return strings.Join(lines, "\n"), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if ua.JSON {
+		fprintJSON(w, undone)
+		return nil, nil
+	}
+	for _, u := range undone {
+		fmt.Fprintf(w, "undid %s %s\n", shortHash(u.Commit), u.Intent)
+		w.Push(2)
+		for _, line := range u.Inverse {
+			fmt.Fprintln(w, line)
+		}
+		w.Pop()
+	}
+	return nil, nil
+}
+
+// undoTargets selects the commits to undo, newest first. For a commit
+// target it refuses when a later commit touched any of the same issues,
+// since the inverse would silently clobber that later work.
+func undoTargets(commits []treefs.CommitInfo, ua UndoArgs) ([]treefs.CommitInfo, error) {
+	if ua.Commit == "" {
+		if ua.Count > len(commits) {
+			return nil, fmt.Errorf("only %d commit(s) in history", len(commits))
+		}
+		for _, c := range commits[:ua.Count] {
+			if strings.HasPrefix(c.Message, "init ") {
+				return nil, fmt.Errorf("cannot undo past %s %q", shortHash(c.Hash), firstLine(c.Message))
+			}
+		}
+		return commits[:ua.Count], nil
+	}
+
+	idx := -1
+	for i, c := range commits {
+		if strings.HasPrefix(c.Hash, ua.Commit) {
+			if idx != -1 {
+				return nil, fmt.Errorf("commit %q is ambiguous", ua.Commit)
+			}
+			idx = i
+		}
+	}
+	if idx == -1 {
+		return nil, fmt.Errorf("no commit matching %q on the beadwork branch", ua.Commit)
+	}
+	target := commits[idx]
+	ids := intent.IssueIDs(target.Message)
+	for i := idx - 1; i >= 0; i-- {
+		later := intent.IssueIDs(commits[i].Message)
+		for _, id := range ids {
+			for _, l := range later {
+				if id == l {
+					return nil, fmt.Errorf("cannot undo %s: later commit %s %q also touched %s; undo that first",
+						shortHash(target.Hash), shortHash(commits[i].Hash), firstLine(commits[i].Message), id)
+				}
+			}
+		}
+	}
+	return []treefs.CommitInfo{target}, nil
+}
+
+// inverseOf computes the inverse intent lines for c, reading prior
+// values from c's parent tree.
+func inverseOf(fs *treefs.TreeFS, c treefs.CommitInfo) ([]string, error) {
+	parent, err := fs.ParentHash(plumbing.NewHash(c.Hash))
+	if err != nil {
+		return nil, err
+	}
+	prior := func(path string) ([]byte, error) {
+		if parent.IsZero() {
+			return nil, fmt.Errorf("%s: not found", path)
+		}
+		return fs.ReadFileAt(parent, path)
+	}
+	inv, err := intent.Inverse(c.Message, prior)
+	if err != nil {
+		return nil, fmt.Errorf("cannot undo %s: %w", shortHash(c.Hash), err)
+	}
+	return inv, nil
+}
+
+func shortHash(h string) string {
+	if len(h) > 7 {
+		return h[:7]
+	}
+	return h
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/jallum/beadwork/internal/issue"
+	"github.com/jallum/beadwork/internal/testutil"
+)
+
+// --- parseUndoArgs ---
+
+func TestParseUndoArgsDefault(t *testing.T) {
+	ua, err := parseUndoArgs(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ua.Count != 1 || ua.Commit != "" {
+		t.Errorf("got %+v, want Count=1", ua)
+	}
+}
+
+func TestParseUndoArgsCount(t *testing.T) {
+	ua, err := parseUndoArgs([]string{"3", "--json"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ua.Count != 3 || !ua.JSON {
+		t.Errorf("got %+v, want Count=3 JSON", ua)
+	}
+}
+
+func TestParseUndoArgsCommit(t *testing.T) {
+	ua, err := parseUndoArgs([]string{"4F2A9C1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ua.Commit != "4f2a9c1" || ua.Count != 0 {
+		t.Errorf("got %+v, want Commit=4f2a9c1", ua)
+	}
+}
+
+func TestParseUndoArgsRejects(t *testing.T) {
+	for _, args := range [][]string{{"0"}, {"abc"}} {
+		if _, err := parseUndoArgs(args); err == nil {
+			t.Errorf("parseUndoArgs(%v): expected error", args)
+		}
+	}
+}
+
+// --- cmdUndo ---
+
+func createForUndo(t *testing.T, env *testutil.Env, title string) *issue.Issue {
+	t.Helper()
+	iss, err := env.Store.Create(title, issue.CreateOpts{})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	env.CommitIntent("create " + iss.ID + " p2 task \"" + title + "\"")
+	return iss
+}
+
+func TestCmdUndoClose(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	iss := createForUndo(t, env, "Epic")
+	var buf bytes.Buffer
+	if _, err := cmdStart(env.Store, []string{iss.ID, "--assignee", "alice"}, PlainWriter(&buf), nil); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if _, err := cmdClose(env.Store, []string{iss.ID, "--reason", "oops"}, PlainWriter(&buf), nil); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	buf.Reset()
+	if _, err := cmdUndo(env.Store, nil, PlainWriter(&buf), nil); err != nil {
+		t.Fatalf("undo: %v", err)
+	}
+	if !strings.Contains(buf.String(), "undid") || !strings.Contains(buf.String(), "reopen "+iss.ID) {
+		t.Errorf("output = %q", buf.String())
+	}
+
+	got, _ := env.Store.Get(iss.ID)
+	if got.Status != "in_progress" || got.Assignee != "alice" {
+		t.Errorf("status=%q assignee=%q, want in_progress/alice", got.Status, got.Assignee)
+	}
+}
+
+func TestCmdUndoCount(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	iss := createForUndo(t, env, "Original")
+	var buf bytes.Buffer
+	if _, err := cmdUpdate(env.Store, []string{iss.ID, "--title", "Renamed", "--priority", "0"}, PlainWriter(&buf), nil); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if _, err := cmdLabel(env.Store, []string{iss.ID, "+bug"}, PlainWriter(&buf), nil); err != nil {
+		t.Fatalf("label: %v", err)
+	}
+
+	if _, err := cmdUndo(env.Store, []string{"2"}, PlainWriter(&buf), nil); err != nil {
+		t.Fatalf("undo: %v", err)
+	}
+	got, _ := env.Store.Get(iss.ID)
+	if got.Title != "Original" || got.Priority != iss.Priority {
+		t.Errorf("title=%q priority=%d, want Original/%d", got.Title, got.Priority, iss.Priority)
+	}
+	if len(got.Labels) != 0 {
+		t.Errorf("labels = %v, want none", got.Labels)
+	}
+}
+
+func TestCmdUndoCommitRefusesWhenLaterCommitTouchedIssue(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	iss := createForUndo(t, env, "Target")
+	var buf bytes.Buffer
+	if _, err := cmdLabel(env.Store, []string{iss.ID, "+bug"}, PlainWriter(&buf), nil); err != nil {
+		t.Fatalf("label: %v", err)
+	}
+	commits, _ := env.Repo.AllCommits()
+	labelHash := commits[0].Hash
+	if _, err := cmdUpdate(env.Store, []string{iss.ID, "--priority", "0"}, PlainWriter(&buf), nil); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	_, err := cmdUndo(env.Store, []string{labelHash[:8]}, PlainWriter(&buf), nil)
+	if err == nil {
+		t.Fatal("expected refusal")
+	}
+	if !strings.Contains(err.Error(), "later commit") || !strings.Contains(err.Error(), iss.ID) {
+		t.Errorf("error = %q", err)
+	}
+}
+
+func TestCmdUndoCommitUnrelatedLaterCommit(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	a := createForUndo(t, env, "A")
+	b := createForUndo(t, env, "B")
+	var buf bytes.Buffer
+	if _, err := cmdDep(env.Store, []string{"add", a.ID, "blocks", b.ID}, PlainWriter(&buf), nil); err != nil {
+		t.Fatalf("dep add: %v", err)
+	}
+	commits, _ := env.Repo.AllCommits()
+	linkHash := commits[0].Hash
+	c := createForUndo(t, env, "C")
+	if _, err := cmdLabel(env.Store, []string{c.ID, "+later"}, PlainWriter(&buf), nil); err != nil {
+		t.Fatalf("label: %v", err)
+	}
+
+	if _, err := cmdUndo(env.Store, []string{linkHash}, PlainWriter(&buf), nil); err != nil {
+		t.Fatalf("undo: %v", err)
+	}
+	got, _ := env.Store.Get(b.ID)
+	if len(got.BlockedBy) != 0 {
+		t.Errorf("blocked_by = %v, want none", got.BlockedBy)
+	}
+}
+
+func TestCmdUndoRefusesComment(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	iss := createForUndo(t, env, "Commented")
+	var buf bytes.Buffer
+	if _, err := cmdComment(env.Store, []string{iss.ID, "hello"}, PlainWriter(&buf), nil); err != nil {
+		t.Fatalf("comment: %v", err)
+	}
+	if _, err := cmdUndo(env.Store, nil, PlainWriter(&buf), nil); err == nil {
+		t.Error("expected error undoing a comment")
+	}
+}
+
+func TestCmdUndoRefusesInit(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	var buf bytes.Buffer
+	if _, err := cmdUndo(env.Store, nil, PlainWriter(&buf), nil); err == nil {
+		t.Error("expected error undoing init")
+	}
+}
@@ -28,6 +28,9 @@ type UpdateArgs struct {
 	DueSet      bool
 	Parent      string
 	ParentSet   bool
+	Every       string
+	EverySet    bool
+	Fields      map[string]string
 	JSON        bool
 }
 
@@ -36,7 +39,7 @@ func parseUpdateArgs(raw []string) (UpdateArgs, error) {
 		return UpdateArgs{}, fmt.Errorf("usage: bw update <id> [flags]")
 	}
 	a, err := ParseArgs(raw[1:],
-		[]string{"--title", "--description", "--priority", "--assignee", "--type", "--status", "--defer", "--due", "--parent"},
+		[]string{"--title", "--description", "--priority", "--assignee", "--type", "--status", "--defer", "--due", "--parent", "--every", "--field"},
 		[]string{"--json"},
 	)
 	if err != nil {
@@ -83,6 +86,14 @@ func parseUpdateArgs(raw []string) (UpdateArgs, error) {
 		ua.Parent = a.String("--parent")
 		ua.ParentSet = true
 	}
+	if a.Has("--every") {
+		ua.Every = a.String("--every")
+		ua.EverySet = true
+	}
+	ua.Fields, err = parseFieldArgs("--field", a.Strings("--field"))
+	if err != nil {
+		return ua, err
+	}
 	return ua, nil
 }
 
@@ -107,6 +118,11 @@ func cmdUpdate(store *issue.Store, args []string, w Writer, _ *config.Config) (*
 		}
 		ua.Due = resolved
 	}
+	if ua.EverySet && ua.Every != "" {
+		if _, err := nextOccurrence(ua.Every, now); err != nil {
+			return nil, err
+		}
+	}
 
 	opts := issue.UpdateOpts{}
 	var changes []string
@@ -149,6 +165,11 @@ func cmdUpdate(store *issue.Store, args []string, w Writer, _ *config.Config) (*
 		opts.Parent = &ua.Parent
 		changes = append(changes, "parent="+ua.Parent)
 	}
+	if ua.EverySet {
+		opts.Every = &ua.Every
+		changes = append(changes, fmt.Sprintf("every=%q", ua.Every))
+	}
+	opts.Fields = ua.Fields
 
 	var iss *issue.Issue
 	err = commitWithRetry(store, commitMaxRetries, func() (string, error) {
@@ -157,7 +178,13 @@ func cmdUpdate(store *issue.Store, args []string, w Writer, _ *config.Config) (*
 		if uerr != nil {
 			return "", uerr
 		}
-		return fmt.Sprintf("update %s %s", iss.ID, strings.Join(changes, " ")), nil
+		// Record canonical field values (e.g. full IDs for refs) so the
+		// intent replays identically.
+		kvs := append([]string(nil), changes...)
+		for _, name := range issue.SortedFieldNames(ua.Fields) {
+			kvs = append(kvs, fmt.Sprintf("field.%s=%q", name, iss.Fields[name]))
+		}
+		return fmt.Sprintf("update %s %s", iss.ID, strings.Join(kvs, " ")), nil
 	})
 	if err != nil {
 		return nil, err
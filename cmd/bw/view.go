@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jallum/beadwork/internal/config"
+
+	"github.com/jallum/beadwork/internal/issue"
+)
+
+const viewUsage = "usage: bw view save|list|run|delete"
+
+type ViewArgs struct {
+	Subcmd string   // "save", "list", "run", "delete"
+	Name   string   // for save/run/delete
+	Args   []string // list arguments for save; extra arguments for run
+	JSON   bool     // for list
+}
+
+func parseViewArgs(raw []string) (ViewArgs, error) {
+	if len(raw) == 0 {
+		return ViewArgs{}, fmt.Errorf(viewUsage)
+	}
+	va := ViewArgs{Subcmd: raw[0]}
+	switch va.Subcmd {
+	case "save", "run":
+		if len(raw) < 2 {
+			return va, fmt.Errorf("usage: bw view %s <name> [list flags]", va.Subcmd)
+		}
+		va.Name = raw[1]
+		va.Args = raw[2:]
+	case "delete":
+		if len(raw) < 2 {
+			return va, fmt.Errorf("usage: bw view delete <name>")
+		}
+		va.Name = raw[1]
+	case "list":
+		a, err := ParseArgs(raw[1:], nil, []string{"--json"})
+		if err != nil {
+			return va, err
+		}
+		va.JSON = a.JSON()
+	default:
+		return va, fmt.Errorf(viewUsage)
+	}
+	return va, nil
+}
+
+func cmdView(store *issue.Store, args []string, w Writer, _ *config.Config) (*config.Config, error) {
+	va, err := parseViewArgs(args)
+	if err != nil {
+		return nil, err
+	}
+
+	switch va.Subcmd {
+	case "save":
+		if err := issue.ValidateViewName(va.Name); err != nil {
+			return nil, err
+		}
+		for _, a := range va.Args {
+			if strings.HasPrefix(a, "@") {
+				return nil, fmt.Errorf("views cannot include other views (%s)", a)
+			}
+		}
+		if _, err := parseListArgs(va.Args); err != nil {
+			return nil, err
+		}
+		err := commitWithRetry(store, commitMaxRetries, func() (string, error) {
+			if err := store.SaveView(va.Name, va.Args); err != nil {
+				return "", err
+			}
+			intent := "view save " + va.Name
+			for _, a := range va.Args {
+				intent += fmt.Sprintf(" %q", a)
+			}
+			return intent, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(w, "saved view %s\n", va.Name)
+
+	case "delete":
+		err := commitWithRetry(store, commitMaxRetries, func() (string, error) {
+			if err := store.DeleteView(va.Name); err != nil {
+				return "", err
+			}
+			return "view delete " + va.Name, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(w, "deleted view %s\n", va.Name)
+
+	case "list":
+		views, err := store.Views()
+		if err != nil {
+			return nil, err
+		}
+		if va.JSON {
+			fprintJSON(w, views)
+			return nil, nil
+		}
+		if len(views) == 0 {
+			fmt.Fprintln(w, "no saved views")
+			return nil, nil
+		}
+		for _, v := range views {
+			fmt.Fprintf(w, "@%-16s %s\n", v.Name, shellJoin(v.Args))
+		}
+
+	case "run":
+		return cmdList(store, append([]string{"@" + va.Name}, va.Args...), w, nil)
+	}
+	return nil, nil
+}
+
+// expandViews replaces each positional @name argument with the saved
+// view's arguments. Flags given after the view override the view's own.
+func expandViews(store *issue.Store, raw []string) ([]string, error) {
+	var out []string
+	for i, a := range raw {
+		name, ok := strings.CutPrefix(a, "@")
+		if !ok || name == "" || (i > 0 && isListValueFlag(raw[i-1])) {
+			out = append(out, a)
+			continue
+		}
+		v, err := store.GetView(name)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v.Args...)
+	}
+	return out, nil
+}
+
+func isListValueFlag(tok string) bool {
+	if long, ok := aliases[tok]; ok {
+		tok = long
+	}
+	for _, f := range listValueFlags {
+		if f == tok {
+			return true
+		}
+	}
+	return false
+}
+
+// shellJoin renders args for display, quoting any that need it.
+func shellJoin(args []string) string {
+	parts := make([]string, len(args))
+	for i, a := range args {
+		if a == "" || strings.ContainsAny(a, " \t\"'()<>!*?$&|;") {
+			parts[i] = "'" + strings.ReplaceAll(a, "'", `'\''`) + "'"
+		} else {
+			parts[i] = a
+		}
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/jallum/beadwork/internal/issue"
+	"github.com/jallum/beadwork/internal/testutil"
+)
+
+func TestCmdViewSaveRunList(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	bug, _ := env.Store.Create("Crash", issue.CreateOpts{Type: "bug"})
+	env.Store.Create("Chore", issue.CreateOpts{Type: "task"})
+	env.Repo.Commit("create issues")
+
+	var buf bytes.Buffer
+	if _, err := cmdView(env.Store, []string{"save", "bugs", "--type", "bug"}, PlainWriter(&buf), nil); err != nil {
+		t.Fatalf("view save: %v", err)
+	}
+	if !strings.Contains(buf.String(), "saved view bugs") {
+		t.Errorf("output = %q", buf.String())
+	}
+
+	buf.Reset()
+	if _, err := cmdView(env.Store, []string{"run", "bugs"}, PlainWriter(&buf), nil); err != nil {
+		t.Fatalf("view run: %v", err)
+	}
+	if !strings.Contains(buf.String(), bug.ID) || strings.Contains(buf.String(), "Chore") {
+		t.Errorf("view run output = %q", buf.String())
+	}
+
+	buf.Reset()
+	if _, err := cmdList(env.Store, []string{"@bugs", "--type", "task"}, PlainWriter(&buf), nil); err != nil {
+		t.Fatalf("list @bugs: %v", err)
+	}
+	if !strings.Contains(buf.String(), "Chore") {
+		t.Errorf("flags after a view should override it: %q", buf.String())
+	}
+
+	buf.Reset()
+	cmdView(env.Store, []string{"save", "urgent", "-q", "priority<=1 type:bug"}, PlainWriter(&buf), nil)
+	buf.Reset()
+	if _, err := cmdView(env.Store, []string{"list"}, PlainWriter(&buf), nil); err != nil {
+		t.Fatalf("view list: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "@bugs") || !strings.Contains(out, "--type bug") || !strings.Contains(out, "-q 'priority<=1 type:bug'") {
+		t.Errorf("view list output = %q", out)
+	}
+}
+
+func TestCmdViewSaveValidates(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	var buf bytes.Buffer
+	for _, args := range [][]string{
+		{"save", "bad", "--bogus"},
+		{"save", "bad", "--query", "prio<1"},
+		{"save", "Bad Name", "--all"},
+		{"save", "nested", "@other"},
+	} {
+		if _, err := cmdView(env.Store, args, PlainWriter(&buf), nil); err == nil {
+			t.Errorf("view %q: expected error", args)
+		}
+	}
+	if views, _ := env.Store.Views(); len(views) != 0 {
+		t.Errorf("nothing should be saved, got %v", views)
+	}
+}
+
+func TestCmdViewDelete(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	var buf bytes.Buffer
+	cmdView(env.Store, []string{"save", "mine", "--assignee", "alice"}, PlainWriter(&buf), nil)
+	if _, err := cmdView(env.Store, []string{"delete", "mine"}, PlainWriter(&buf), nil); err != nil {
+		t.Fatalf("view delete: %v", err)
+	}
+	if _, err := cmdList(env.Store, []string{"@mine"}, PlainWriter(&buf), nil); err == nil || !strings.Contains(err.Error(), "no such view: mine") {
+		t.Errorf("list @mine err = %v", err)
+	}
+}
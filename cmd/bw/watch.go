@@ -0,0 +1,209 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/jallum/beadwork/internal/config"
+
+	"github.com/jallum/beadwork/internal/issue"
+	"github.com/jallum/beadwork/internal/recap"
+)
+
+// defaultWatchInterval is how often bw watch checks the beadwork ref.
+const defaultWatchInterval = time.Second
+
+type WatchArgs struct {
+	Query    *issue.Query
+	Interval time.Duration
+	JSON     bool
+}
+
+func parseWatchArgs(raw []string) (WatchArgs, error) {
+	a, err := ParseArgs(raw, []string{"--query", "--interval"}, []string{"--json"})
+	if err != nil {
+		return WatchArgs{}, err
+	}
+	wa := WatchArgs{Interval: defaultWatchInterval, JSON: a.JSON()}
+	if a.Has("--query") {
+		wa.Query, err = issue.ParseQuery(a.String("--query"))
+		if err != nil {
+			return wa, err
+		}
+	}
+	if a.Has("--interval") {
+		wa.Interval, err = time.ParseDuration(a.String("--interval"))
+		if err != nil || wa.Interval <= 0 {
+			return wa, fmt.Errorf("invalid interval %q (use e.g. 500ms, 5s)", a.String("--interval"))
+		}
+	}
+	return wa, nil
+}
+
+// watchEventNames maps recap event types to the names bw watch prints.
+var watchEventNames = map[string]string{
+	"create":    "created",
+	"close":     "closed",
+	"start":     "started",
+	"update":    "updated",
+	"reopen":    "reopened",
+	"defer":     "deferred",
+	"undefer":   "undeferred",
+	"comment":   "commented",
+	"link":      "linked",
+	"unlink":    "unlinked",
+	"unblocked": "unblocked",
+	"delete":    "deleted",
+	"undelete":  "undeleted",
+	"label":     "labeled",
+}
+
+var watchEventStyles = map[string]Style{
+	"created":   Cyan,
+	"closed":    Green,
+	"unblocked": Green,
+	"reopened":  Yellow,
+	"deleted":   Red,
+	"undeleted": Cyan,
+}
+
+type watchEvent struct {
+	Type   string `json:"type"`
+	ID     string `json:"id"`
+	Title  string `json:"title,omitempty"`
+	Detail string `json:"detail,omitempty"`
+	Time   string `json:"time"`
+	Commit string `json:"commit"`
+	Author string `json:"author,omitempty"`
+}
+
+func cmdWatch(store *issue.Store, args []string, w Writer, _ *config.Config) (*config.Config, error) {
+	wa, err := parseWatchArgs(args)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	wt := newWatcher(store, wa)
+	ticker := time.NewTicker(wa.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, nil
+		case <-ticker.C:
+		}
+		if err := wt.poll(w); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// watcher reports the commits that land on the beadwork ref after it was
+// created.
+type watcher struct {
+	store *issue.Store
+	args  WatchArgs
+	ref   plumbing.Hash   // last ref reported up to
+	seen  map[string]bool // commits already reported
+}
+
+func newWatcher(store *issue.Store, wa WatchArgs) *watcher {
+	return &watcher{
+		store: store,
+		args:  wa,
+		ref:   store.FS.RefHash(),
+		seen:  make(map[string]bool),
+	}
+}
+
+// poll prints an event for every commit reachable from the ref that was
+// not reachable from it at the last poll, oldest first, whenever it was
+// authored. When the ref was rewritten rather than advanced (a sync that
+// rebased local commits), the rewritten copies of commits already
+// reported are recognised by their message and skipped.
+func (wt *watcher) poll(w Writer) error {
+	if err := wt.store.Refresh(); err != nil {
+		return err
+	}
+	ref := wt.store.FS.RefHash()
+	if ref == wt.ref {
+		return nil
+	}
+	commits, err := wt.store.FS.CommitsBetween(ref, wt.ref)
+	if err != nil {
+		return err
+	}
+	// Commits the old ref had and the new one lacks were reported already;
+	// a rebase brings them back under new hashes.
+	rewritten := make(map[string]int)
+	if !wt.ref.IsZero() {
+		dropped, err := wt.store.FS.CommitsBetween(wt.ref, ref)
+		if err != nil {
+			return err
+		}
+		for _, c := range dropped {
+			rewritten[c.Message]++
+		}
+	}
+	wt.ref = ref
+
+	for _, c := range commits {
+		if wt.seen[c.Hash] {
+			continue
+		}
+		wt.seen[c.Hash] = true
+		if rewritten[c.Message] > 0 {
+			rewritten[c.Message]--
+			continue
+		}
+		for _, ev := range recap.ParseIntent(c.Message, c.Time) {
+			e := watchEvent{
+				Type:   watchEventNames[ev.Type],
+				ID:     ev.ID,
+				Detail: ev.Detail,
+				Time:   c.Time.UTC().Format(time.RFC3339),
+				Commit: c.Hash,
+				Author: c.Author,
+			}
+			iss, err := wt.store.Get(ev.ID)
+			if err == nil {
+				e.Title = iss.Title
+			}
+			if wt.args.Query != nil && (err != nil || !wt.args.Query.Match(iss, wt.store.QueryEnv())) {
+				continue
+			}
+			printWatchEvent(w, e, wt.args.JSON)
+		}
+	}
+	return nil
+}
+
+// printWatchEvent writes one event per line: a compact JSON object with
+// --json, otherwise "time type id title (detail)".
+func printWatchEvent(w Writer, e watchEvent, asJSON bool) {
+	if asJSON {
+		json.NewEncoder(w).Encode(e)
+		return
+	}
+	typ := fmt.Sprintf("%-10s", e.Type)
+	if style, ok := watchEventStyles[e.Type]; ok {
+		typ = w.Style(typ, style)
+	}
+	line := fmt.Sprintf("%s %s %s", w.Style(e.Time, Dim), typ, e.ID)
+	if e.Title != "" {
+		line += " " + e.Title
+	}
+	if e.Detail != "" && e.Type != "created" {
+		line += " " + w.Style("("+e.Detail+")", Dim)
+	}
+	fmt.Fprintln(w, line)
+}
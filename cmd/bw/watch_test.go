@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jallum/beadwork/internal/intent"
+	"github.com/jallum/beadwork/internal/issue"
+	"github.com/jallum/beadwork/internal/testutil"
+)
+
+func TestWatcherPoll(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+	t.Setenv("BW_CLOCK", "2027-03-01T10:00:00Z")
+
+	old, _ := env.Store.Create("Before the watch", issue.CreateOpts{})
+	env.Repo.Commit("create " + old.ID)
+
+	wt := newWatcher(env.Store, WatchArgs{})
+	var buf bytes.Buffer
+	if err := wt.poll(PlainWriter(&buf)); err != nil || buf.Len() != 0 {
+		t.Fatalf("poll without commits: err=%v out=%q", err, buf.String())
+	}
+
+	blocker, _ := env.Store.Create("Blocker", issue.CreateOpts{})
+	env.Repo.Commit("create " + blocker.ID + ` p2 task "Blocker"`)
+	env.Store.Link(blocker.ID, old.ID)
+	env.Repo.Commit("link " + blocker.ID + " blocks " + old.ID)
+	env.Store.Close(blocker.ID, "done")
+	env.Repo.Commit("close " + blocker.ID + ` reason="done"` + "\nunblocked " + old.ID)
+
+	if err := wt.poll(PlainWriter(&buf)); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	want := []string{
+		"created    " + blocker.ID + " Blocker",
+		"linked     " + blocker.ID + " Blocker (blocks " + old.ID + ")",
+		"closed     " + blocker.ID + ` Blocker (reason="done")`,
+		"unblocked  " + old.ID + " Before the watch",
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("lines = %q", lines)
+	}
+	for i, line := range lines {
+		if line != "2027-03-01T10:00:00Z "+want[i] {
+			t.Errorf("line %d = %q, want %q", i, line, want[i])
+		}
+	}
+
+	// Nothing new: nothing printed twice.
+	buf.Reset()
+	wt.poll(PlainWriter(&buf))
+	if buf.Len() != 0 {
+		t.Errorf("repeat poll printed %q", buf.String())
+	}
+}
+
+func TestWatcherBatch(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+	t.Setenv("BW_CLOCK", "2027-03-01T10:00:00Z")
+
+	a, _ := env.Store.Create("First", issue.CreateOpts{})
+	env.Repo.Commit("create " + a.ID)
+	b, _ := env.Store.Create("Second", issue.CreateOpts{})
+	env.Repo.Commit("create " + b.ID)
+
+	wt := newWatcher(env.Store, WatchArgs{})
+	var buf bytes.Buffer
+	wt.poll(PlainWriter(&buf))
+
+	if _, err := runTestBatch(t, env.Store, "update "+a.ID+" --priority 1\nlabel "+b.ID+" +urgent\nclose "+b.ID+"\n"); err != nil {
+		t.Fatalf("batch: %v", err)
+	}
+	if err := wt.poll(PlainWriter(&buf)); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{"updated    " + a.ID, "labeled    " + b.ID, "closed     " + b.ID} {
+		if !strings.Contains(out, want) {
+			t.Errorf("watch output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestWatcherSyncedCommitsAuthoredEarlier(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+	bare := env.NewBareRemote()
+
+	t.Setenv("BW_CLOCK", "2027-03-01T10:00:00Z")
+	blocker, _ := env.Store.Create("Blocker", issue.CreateOpts{})
+	env.CommitIntent("create " + blocker.ID)
+	env.Repo.Sync(nil)
+
+	// A teammate closes the blocker before the watch starts, but it only
+	// arrives with a later sync.
+	env2 := env.CloneEnv(bare)
+	defer env2.Cleanup()
+	env2.SwitchTo()
+	t.Setenv("BW_CLOCK", "2027-03-01T10:05:00Z")
+	env2.Store.Close(blocker.ID, "")
+	env2.CommitIntent("close " + blocker.ID)
+	env2.Repo.Sync(nil)
+
+	env.SwitchTo()
+	t.Setenv("BW_CLOCK", "2027-03-01T10:10:00Z")
+	wt := newWatcher(env.Store, WatchArgs{})
+	local, _ := env.Store.Create("Local work", issue.CreateOpts{})
+	env.CommitIntent("create " + local.ID)
+	var buf bytes.Buffer
+	if err := wt.poll(PlainWriter(&buf)); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "created    "+local.ID) {
+		t.Fatalf("local commit not reported: %q", buf.String())
+	}
+
+	status, intents, err := env.Repo.Sync(nil)
+	if err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	env.Store.ClearCache()
+	if status == "needs replay" {
+		intent.Replay(env.Store, intents)
+	}
+	buf.Reset()
+	if err := wt.poll(PlainWriter(&buf)); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "closed     "+blocker.ID) {
+		t.Errorf("synced close not reported: %q", out)
+	}
+	if strings.Contains(out, local.ID) {
+		t.Errorf("rebased local commit reported twice: %q", out)
+	}
+}
+
+func TestWatcherQueryAndJSON(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	q, _ := issue.ParseQuery("label:backend")
+	wt := newWatcher(env.Store, WatchArgs{Query: q, JSON: true})
+
+	a, _ := env.Store.Create("Backend work", issue.CreateOpts{})
+	env.Store.Label(a.ID, []string{"backend"}, nil)
+	env.Repo.Commit("create " + a.ID)
+	b, _ := env.Store.Create("Docs work", issue.CreateOpts{})
+	env.Repo.Commit("create " + b.ID)
+	env.Store.Comment(a.ID, "looking", "alice")
+	env.Repo.Commit("comment " + a.ID)
+
+	var buf bytes.Buffer
+	if err := wt.poll(PlainWriter(&buf)); err != nil {
+		t.Fatal(err)
+	}
+	var events []watchEvent
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var e watchEvent
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Fatalf("not one JSON object per line: %q", buf.String())
+		}
+		events = append(events, e)
+	}
+	if len(events) != 2 || events[0].Type != "created" || events[1].Type != "commented" || events[1].ID != a.ID {
+		t.Errorf("events = %+v", events)
+	}
+	if events[0].Commit == "" || events[0].Title != "Backend work" {
+		t.Errorf("event fields = %+v", events[0])
+	}
+}
+
+func TestParseWatchArgs(t *testing.T) {
+	wa, err := parseWatchArgs([]string{"--interval", "250ms", "-q", "p<=1", "--json"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wa.Interval != 250*time.Millisecond || wa.Query == nil || !wa.JSON {
+		t.Errorf("args = %+v", wa)
+	}
+	if wa, _ := parseWatchArgs(nil); wa.Interval != defaultWatchInterval {
+		t.Errorf("default interval = %v", wa.Interval)
+	}
+	if _, err := parseWatchArgs([]string{"--interval", "soon"}); err == nil {
+		t.Error("expected error for bad interval")
+	}
+}
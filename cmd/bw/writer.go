@@ -5,6 +5,7 @@ import (
 	"io"
 	"strings"
 
+	"github.com/jallum/beadwork/internal/issue"
 	"github.com/jallum/beadwork/internal/md"
 )
 
@@ -172,15 +173,16 @@ func (w *writer) rebuildPrefix() {
 // markdown, and raw mode passes tokens through unchanged.
 type resolvingWriter struct {
 	Writer
+	icons md.StatusIcons // the repo's status icons; nil for the built-ins
 }
 
 func (rw *resolvingWriter) Write(p []byte) (int, error) {
 	s := string(p)
 	if !rw.IsRaw() {
 		if rw.IsTTY() {
-			s = md.ResolveTTY(s, rw.Width())
+			s = rw.icons.ResolveTTY(s, rw.Width())
 		} else {
-			s = md.ResolveMarkdown(s)
+			s = rw.icons.ResolveMarkdown(s)
 		}
 	}
 	_, err := rw.Writer.Write([]byte(s))
@@ -197,6 +199,16 @@ func ResolvingWriter(w Writer) Writer {
 	return &resolvingWriter{Writer: w}
 }
 
+// withStatusIcons returns w rendering {status:...} tokens with the icons
+// of workflow wf. Writers that do not resolve tokens are returned as is.
+func withStatusIcons(w Writer, wf *issue.Workflow) Writer {
+	rw, ok := w.(*resolvingWriter)
+	if !ok {
+		return w
+	}
+	return &resolvingWriter{Writer: rw.Writer, icons: wf.Icons()}
+}
+
 // PlainWriter returns a Writer that resolves tokenized markdown to plain text.
 func PlainWriter(out io.Writer) Writer {
 	return ResolvingWriter(plainWriter(out))
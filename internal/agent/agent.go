@@ -72,3 +72,12 @@ func DetectFrom(lookupEnv func(string) (string, bool)) *Agent {
 	}
 	return nil
 }
+
+// EnvVars lists the environment variables Detect consults.
+func EnvVars() []string {
+	vars := make([]string, len(probes))
+	for i, p := range probes {
+		vars[i] = p.envVar
+	}
+	return vars
+}
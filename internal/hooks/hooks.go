@@ -0,0 +1,178 @@
+// Package hooks runs user-configured executables around beadwork commits.
+// Hooks come from the global config (hooks.<name> in ~/.bw) and from the
+// clone's git config (beadwork.hooks.<name>); when both name one, the
+// global hook runs first.
+package hooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/jallum/beadwork/internal/config"
+	"github.com/jallum/beadwork/internal/intent"
+	"github.com/jallum/beadwork/internal/issue"
+)
+
+// Hook names. Pre hooks run before the commit and veto it by exiting
+// non-zero; the others run after it has landed.
+const (
+	PreCommitIntent = "pre-commit-intent" // every commit
+	PostCreate      = "post-create"       // an issue was created
+	PostClose       = "post-close"        // an issue was closed
+	OnUnblocked     = "on-unblocked"      // closing a blocker left an issue unblocked
+)
+
+// Names lists every hook in the order they are documented.
+var Names = []string{PreCommitIntent, PostCreate, PostClose, OnUnblocked}
+
+// postHook is one post hook to run for one issue.
+type postHook struct {
+	name string
+	id   string
+}
+
+// postHooks lists the post hooks an intent triggers, one per intent line
+// that creates, closes or unblocks an issue, in the order of the lines. A
+// recur line creates the next instance of a recurring issue.
+func postHooks(message string) []postHook {
+	var out []postHook
+	for _, line := range strings.Split(message, "\n") {
+		parts := intent.ParseIntent(strings.TrimSpace(line))
+		if len(parts) < 2 {
+			continue
+		}
+		switch parts[0] {
+		case "create":
+			out = append(out, postHook{PostCreate, parts[1]})
+		case "close":
+			out = append(out, postHook{PostClose, parts[1]})
+		case "unblocked":
+			out = append(out, postHook{OnUnblocked, parts[1]})
+		case "recur":
+			if len(parts) >= 3 {
+				out = append(out, postHook{PostCreate, parts[2]})
+			}
+		}
+	}
+	return out
+}
+
+// Runner implements issue.CommitHooks by running the configured
+// executables with the issue as JSON on stdin.
+type Runner struct {
+	Dir    string              // working directory, normally the repo root
+	Stderr io.Writer           // hook output and post-hook warnings; nil means os.Stderr
+	hooks  map[string][]string // hook name to executables, in run order
+}
+
+// Load collects the hooks configured in cfg and in the clone's git config
+// (repoHooks, see repo.Repo.Hooks). It returns nil when none are set.
+// Relative paths in repoHooks are resolved against dir.
+func Load(cfg *config.Config, repoHooks map[string]string, dir string) *Runner {
+	r := &Runner{Dir: dir, hooks: make(map[string][]string)}
+	for _, name := range Names {
+		if cfg != nil {
+			if path := cfg.String("hooks." + name); path != "" {
+				r.hooks[name] = append(r.hooks[name], path)
+			}
+		}
+		if path := repoHooks[name]; path != "" {
+			if !filepath.IsAbs(path) && strings.ContainsRune(path, filepath.Separator) {
+				path = filepath.Join(dir, path)
+			}
+			r.hooks[name] = append(r.hooks[name], path)
+		}
+	}
+	if len(r.hooks) == 0 {
+		return nil
+	}
+	return r
+}
+
+// BeforeCommit runs pre-commit-intent with the intent's primary issue as
+// it will be committed. The first hook to fail vetoes the commit; its
+// stderr becomes part of the error.
+func (r *Runner) BeforeCommit(s *issue.Store, message string) error {
+	paths := r.hooks[PreCommitIntent]
+	if len(paths) == 0 {
+		return nil
+	}
+	var id string
+	if parts := intent.ParseIntent(strings.SplitN(message, "\n", 2)[0]); len(parts) >= 2 {
+		id = parts[1]
+	}
+	stdin := issueJSON(s, id)
+	for _, path := range paths {
+		var stderr bytes.Buffer
+		if err := r.run(path, PreCommitIntent, message, id, stdin, &stderr); err != nil {
+			msg := fmt.Sprintf("%s hook %s: %v", PreCommitIntent, path, err)
+			if out := strings.TrimSpace(stderr.String()); out != "" {
+				msg += "\n" + out
+			}
+			return errors.New(msg)
+		}
+	}
+	return nil
+}
+
+// AfterCommit runs post-create, post-close and on-unblocked for each
+// issue the intent's lines name. Failures are reported as warnings: the
+// commit has already landed.
+func (r *Runner) AfterCommit(s *issue.Store, message string) {
+	for _, h := range postHooks(message) {
+		paths := r.hooks[h.name]
+		if len(paths) == 0 {
+			continue
+		}
+		stdin := issueJSON(s, h.id)
+		for _, path := range paths {
+			if err := r.run(path, h.name, message, h.id, stdin, r.stderr()); err != nil {
+				fmt.Fprintf(r.stderr(), "warning: %s hook %s: %v\n", h.name, path, err)
+			}
+		}
+	}
+}
+
+// run executes one hook. Its stdout goes to stderr so it never mixes
+// with bw's own output.
+func (r *Runner) run(path, name, message, id string, stdin []byte, stderr io.Writer) error {
+	cmd := exec.Command(path)
+	cmd.Dir = r.Dir
+	cmd.Stdin = bytes.NewReader(stdin)
+	cmd.Stdout = stderr
+	cmd.Stderr = stderr
+	cmd.Env = append(os.Environ(),
+		"BW_HOOK="+name,
+		"BW_INTENT="+message,
+		"BW_ISSUE_ID="+id,
+	)
+	return cmd.Run()
+}
+
+func (r *Runner) stderr() io.Writer {
+	if r.Stderr != nil {
+		return r.Stderr
+	}
+	return os.Stderr
+}
+
+// issueJSON is the hook's stdin: the issue as stored, or nothing when
+// the intent names no issue or it no longer exists.
+func issueJSON(s *issue.Store, id string) []byte {
+	if id == "" {
+		return nil
+	}
+	iss, err := s.Get(id)
+	if err != nil {
+		return nil
+	}
+	data, _ := json.MarshalIndent(iss, "", "  ")
+	return append(data, '\n')
+}
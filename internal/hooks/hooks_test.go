@@ -0,0 +1,159 @@
+package hooks_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jallum/beadwork/internal/config"
+	"github.com/jallum/beadwork/internal/hooks"
+	"github.com/jallum/beadwork/internal/issue"
+	"github.com/jallum/beadwork/internal/testutil"
+)
+
+// script writes an executable shell script into dir.
+func script(t *testing.T, dir, name, body string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func userConfig(t *testing.T, hooks map[string]any) *config.Config {
+	t.Helper()
+	cfg, _ := config.Load(filepath.Join(t.TempDir(), ".bw"))
+	return cfg.Set("hooks", hooks)
+}
+
+func TestLoad(t *testing.T) {
+	if r := hooks.Load(userConfig(t, nil), nil, "/repo"); r != nil {
+		t.Error("Load with no hooks should return nil")
+	}
+	if r := hooks.Load(nil, map[string]string{"post-close": "notify"}, "/repo"); r == nil {
+		t.Error("Load ignored a repo hook")
+	}
+}
+
+func TestPostHooks(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+	bin := t.TempDir()
+	log := filepath.Join(bin, "log")
+	record := func(who string) string {
+		return `echo "$BW_HOOK $BW_ISSUE_ID $(grep -c '"title"') ` + who + `" >> ` + log
+	}
+	user := script(t, bin, "user", record("user"))
+	clone := script(t, bin, "clone", record("clone"))
+
+	r := hooks.Load(
+		userConfig(t, map[string]any{"post-close": user, "on-unblocked": user}),
+		map[string]string{"post-close": clone, "post-create": clone},
+		env.Dir,
+	)
+	env.Store.Hooks = r
+
+	a, _ := env.Store.Create("Blocker", issue.CreateOpts{})
+	if err := env.Store.Commit("create " + a.ID + ` p2 task "Blocker"`); err != nil {
+		t.Fatal(err)
+	}
+	b, _ := env.Store.Create("Blocked", issue.CreateOpts{})
+	env.Store.Link(a.ID, b.ID)
+	env.Store.Hooks = nil
+	env.Store.Commit("link " + a.ID + " blocks " + b.ID)
+	env.Store.Hooks = r
+	env.Store.Close(a.ID, "")
+	if err := env.Store.Commit("close " + a.ID + "\nunblocked " + b.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ := os.ReadFile(log)
+	want := strings.Join([]string{
+		"post-create " + a.ID + " 1 clone",
+		"post-close " + a.ID + " 1 user",
+		"post-close " + a.ID + " 1 clone",
+		"on-unblocked " + b.ID + " 1 user",
+	}, "\n") + "\n"
+	if string(data) != want {
+		t.Errorf("hook log:\n%s\nwant:\n%s", data, want)
+	}
+}
+
+func TestPreCommitIntentVeto(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+	bin := t.TempDir()
+	check := script(t, bin, "check", `
+if grep -q '"type": "bug"' && [ "${BW_INTENT#create}" != "$BW_INTENT" ]; then
+  echo "bugs need a description" >&2
+  exit 1
+fi`)
+	env.Store.Hooks = hooks.Load(nil, map[string]string{"pre-commit-intent": check}, env.Dir)
+
+	iss, _ := env.Store.Create("Crash", issue.CreateOpts{Type: "bug"})
+	err := env.Store.Commit("create " + iss.ID + ` p2 bug "Crash"`)
+	if err == nil || !strings.Contains(err.Error(), "bugs need a description") {
+		t.Fatalf("err = %v, want veto with hook stderr", err)
+	}
+	commits, _ := env.Repo.AllCommits()
+	if strings.HasPrefix(commits[0].Message, "create "+iss.ID) {
+		t.Error("vetoed intent was committed")
+	}
+	if _, err := env.Store.Get(iss.ID); err == nil {
+		t.Error("vetoed issue still readable from the store")
+	}
+
+	task, _ := env.Store.Create("Chore", issue.CreateOpts{})
+	if err := env.Store.Commit("create " + task.ID + ` p2 task "Chore"`); err != nil {
+		t.Errorf("task vetoed: %v", err)
+	}
+}
+
+func TestPreCommitIntentVetoDiscardsChanges(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+	iss, _ := env.Store.Create("Original title", issue.CreateOpts{})
+	env.Store.Commit("create " + iss.ID)
+
+	reject := script(t, t.TempDir(), "reject", `
+case "$BW_INTENT" in update*) exit 1 ;; esac`)
+	env.Store.Hooks = hooks.Load(nil, map[string]string{"pre-commit-intent": reject}, env.Dir)
+
+	title := "Vetoed title"
+	env.Store.Update(iss.ID, issue.UpdateOpts{Title: &title})
+	if err := env.Store.Commit("update " + iss.ID + " title=" + title); err == nil {
+		t.Fatal("update was not vetoed")
+	}
+	got, err := env.Store.Get(iss.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Title != "Original title" {
+		t.Errorf("Title = %q after veto, want the committed title", got.Title)
+	}
+	all, _ := env.Store.List(issue.Filter{})
+	if len(all) != 1 || all[0].Title != "Original title" {
+		t.Errorf("List after veto = %+v", all)
+	}
+}
+
+func TestPostHookFailureWarns(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+	fail := script(t, t.TempDir(), "fail", "exit 3")
+	r := hooks.Load(nil, map[string]string{"post-create": fail}, env.Dir)
+	var stderr bytes.Buffer
+	r.Stderr = &stderr
+	env.Store.Hooks = r
+
+	iss, _ := env.Store.Create("Still lands", issue.CreateOpts{})
+	if err := env.Store.Commit("create " + iss.ID); err != nil {
+		t.Fatalf("post hook failure failed the commit: %v", err)
+	}
+	if !strings.Contains(stderr.String(), "warning: post-create hook") {
+		t.Errorf("stderr = %q", stderr.String())
+	}
+}
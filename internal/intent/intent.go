@@ -2,7 +2,10 @@ package intent
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/jallum/beadwork/internal/issue"
 	"github.com/jallum/beadwork/internal/repo"
@@ -37,45 +40,80 @@ func Replay(store *issue.Store, intents []string) []error {
 }
 
 func replayOne(store *issue.Store, raw string) error {
+	known, err := apply(store, raw)
+	if err != nil || !known {
+		return err
+	}
+	return store.Commit(raw)
+}
+
+// Apply stages a single intent line against the store without committing.
+// Callers that fold several intents into one commit (e.g. bw undo) Apply
+// each line and then Commit the joined message, which Replay can later
+// re-run line by line. Unknown verbs are ignored.
+func Apply(store *issue.Store, raw string) error {
+	_, err := apply(store, raw)
+	return err
+}
+
+// apply dispatches raw to its verb handler. Reports false for empty,
+// skipped, or unknown intents so the caller knows there is nothing to
+// commit.
+func apply(store *issue.Store, raw string) (bool, error) {
 	parts := ParseIntent(raw)
 	if len(parts) == 0 {
-		return nil // skip empty or unparseable
+		return false, nil // skip empty or unparseable
 	}
 
 	verb := parts[0]
 	switch verb {
 	case "create":
-		return replayCreate(store, parts[1:], raw)
+		return true, replayCreate(store, parts[1:], raw)
 	case "close":
-		return replayClose(store, parts[1:], raw)
+		return true, replayClose(store, parts[1:], raw)
 	case "reopen":
-		return replayReopen(store, parts[1:], raw)
+		return true, replayReopen(store, parts[1:], raw)
 	case "update":
-		return replayUpdate(store, parts[1:], raw)
+		return true, replayUpdate(store, parts[1:], raw)
 	case "link":
-		return replayLink(store, parts[1:], raw)
+		return true, replayLink(store, parts[1:], raw)
 	case "unlink":
-		return replayUnlink(store, parts[1:], raw)
+		return true, replayUnlink(store, parts[1:], raw)
 	case "label":
-		return replayLabel(store, parts[1:], raw)
+		return true, replayLabel(store, parts[1:], raw)
 	case "delete":
-		return replayDelete(store, parts[1:], raw)
+		return true, replayDelete(store, parts[1:], raw)
+	case "undelete":
+		return true, replayUndelete(store, parts[1:], raw)
 	case "config":
-		return replayConfig(store, parts[1:], raw)
+		return true, replayConfig(store, parts[1:], raw)
 	case "comment":
-		return replayComment(store, parts[1:], raw)
+		return true, replayComment(store, parts[1:], raw)
 	case "start":
-		return replayStart(store, parts[1:], raw)
+		return true, replayStart(store, parts[1:], raw)
+	case "heartbeat":
+		return true, replayHeartbeat(store, parts[1:], raw)
 	case "defer":
-		return replayDefer(store, parts[1:], raw)
+		return true, replayDefer(store, parts[1:], raw)
 	case "undefer":
-		return replayUndefer(store, parts[1:], raw)
+		return true, replayUndefer(store, parts[1:], raw)
+	case "recur":
+		return true, replayRecur(store, parts[1:], raw)
 	case "attach":
-		return replayAttach(store, parts[1:], raw)
+		return true, replayAttach(store, parts[1:], raw)
+	case "view":
+		return true, replayView(store, parts[1:], raw)
+	case "template":
+		return true, replayTemplate(store, parts[1:], raw)
+	case "repair":
+		// Re-run the repair against the replayed tree rather than
+		// recording individual fixes.
+		_, err := store.Repair()
+		return true, err
 	case "init":
-		return nil // skip init intents
+		return false, nil // skip init intents
 	default:
-		return nil // unknown intent, skip
+		return false, nil // unknown intent, skip
 	}
 }
 
@@ -110,10 +148,7 @@ func replayAttach(store *issue.Store, parts []string, raw string) error {
 	if err != nil {
 		return err
 	}
-	if err := store.Attach(ticketID, storedPath, data); err != nil {
-		return err
-	}
-	return store.Commit(raw)
+	return store.Attach(ticketID, storedPath, data)
 }
 
 func replayCreate(store *issue.Store, parts []string, raw string) error {
@@ -141,6 +176,7 @@ func replayCreate(store *issue.Store, parts []string, raw string) error {
 		Priority: priority,
 		Type:     issueType,
 	}
+	var labels []string
 
 	// Parse optional key=value pairs after the title.
 	for _, kv := range parts[3:] {
@@ -148,19 +184,33 @@ func replayCreate(store *issue.Store, parts []string, raw string) error {
 		if eqIdx == -1 {
 			continue
 		}
-		switch kv[:eqIdx] {
+		switch key := kv[:eqIdx]; key {
 		case "description":
 			opts.Description = kv[eqIdx+1:]
 		case "due":
 			opts.Due = kv[eqIdx+1:]
+		case "every":
+			opts.Every = kv[eqIdx+1:]
+		case "parent":
+			opts.Parent = kv[eqIdx+1:]
+		case "labels":
+			labels = strings.Split(kv[eqIdx+1:], ",")
+		default:
+			if name, ok := strings.CutPrefix(key, "field."); ok {
+				if opts.Fields == nil {
+					opts.Fields = make(map[string]string)
+				}
+				opts.Fields[name] = kv[eqIdx+1:]
+			}
 		}
 	}
 
-	_, err := store.Create(title, opts)
-	if err != nil {
+	iss, err := store.Create(title, opts)
+	if err != nil || len(labels) == 0 {
 		return err
 	}
-	return store.Commit(raw)
+	_, err = store.Label(iss.ID, labels, nil)
+	return err
 }
 
 func replayClose(store *issue.Store, parts []string, raw string) error {
@@ -178,10 +228,7 @@ func replayClose(store *issue.Store, parts []string, raw string) error {
 		}
 	}
 	_, err := store.Close(parts[0], reason)
-	if err != nil {
-		return err
-	}
-	return store.Commit(raw)
+	return err
 }
 
 func replayReopen(store *issue.Store, parts []string, raw string) error {
@@ -189,10 +236,7 @@ func replayReopen(store *issue.Store, parts []string, raw string) error {
 		return fmt.Errorf("malformed reopen intent")
 	}
 	_, err := store.Reopen(parts[0])
-	if err != nil {
-		return err
-	}
-	return store.Commit(raw)
+	return err
 }
 
 func replayUpdate(store *issue.Store, parts []string, raw string) error {
@@ -232,36 +276,48 @@ func replayUpdate(store *issue.Store, parts []string, raw string) error {
 			opts.DeferUntil = &val
 		case "due":
 			opts.Due = &val
+		case "every":
+			opts.Every = &val
+		default:
+			if name, ok := strings.CutPrefix(key, "field."); ok {
+				if opts.Fields == nil {
+					opts.Fields = make(map[string]string)
+				}
+				opts.Fields[name] = val
+			}
 		}
 	}
 
 	_, err := store.Update(id, opts)
-	if err != nil {
-		return err
-	}
-	return store.Commit(raw)
+	return err
 }
 
 func replayLink(store *issue.Store, parts []string, raw string) error {
-	// link <id1> blocks <id2>
-	if len(parts) < 3 || parts[1] != "blocks" {
+	// link <id1> <kind> <id2>, kind is blocks or a relation kind
+	if len(parts) < 3 {
 		return fmt.Errorf("malformed link intent")
 	}
-	if err := store.Link(parts[0], parts[2]); err != nil {
-		return err
+	switch kind := parts[1]; {
+	case kind == "blocks":
+		return store.Link(parts[0], parts[2])
+	case issue.IsRelationKind(kind):
+		return store.Relate(kind, parts[0], parts[2])
 	}
-	return store.Commit(raw)
+	return fmt.Errorf("malformed link intent: unknown kind %q", parts[1])
 }
 
 func replayUnlink(store *issue.Store, parts []string, raw string) error {
-	// unlink <id1> blocks <id2>
-	if len(parts) < 3 || parts[1] != "blocks" {
+	// unlink <id1> <kind> <id2>
+	if len(parts) < 3 {
 		return fmt.Errorf("malformed unlink intent")
 	}
-	if err := store.Unlink(parts[0], parts[2]); err != nil {
-		return err
+	switch kind := parts[1]; {
+	case kind == "blocks":
+		return store.Unlink(parts[0], parts[2])
+	case issue.IsRelationKind(kind):
+		return store.Unrelate(kind, parts[0], parts[2])
 	}
-	return store.Commit(raw)
+	return fmt.Errorf("malformed unlink intent: unknown kind %q", parts[1])
 }
 
 func replayLabel(store *issue.Store, parts []string, raw string) error {
@@ -279,10 +335,7 @@ func replayLabel(store *issue.Store, parts []string, raw string) error {
 		}
 	}
 	_, err := store.Label(id, add, remove)
-	if err != nil {
-		return err
-	}
-	return store.Commit(raw)
+	return err
 }
 
 func replayDelete(store *issue.Store, parts []string, raw string) error {
@@ -290,10 +343,15 @@ func replayDelete(store *issue.Store, parts []string, raw string) error {
 		return fmt.Errorf("malformed delete intent")
 	}
 	_, err := store.Delete(parts[0])
-	if err != nil {
-		return err
+	return err
+}
+
+func replayUndelete(store *issue.Store, parts []string, raw string) error {
+	if len(parts) < 1 {
+		return fmt.Errorf("malformed undelete intent")
 	}
-	return store.Commit(raw)
+	_, _, err := store.Undelete(parts[0])
+	return err
 }
 
 func replayConfig(store *issue.Store, parts []string, raw string) error {
@@ -312,7 +370,22 @@ func replayConfig(store *issue.Store, parts []string, raw string) error {
 	if err := r.SetConfig(key, value); err != nil {
 		return err
 	}
-	return store.Commit(raw)
+	// Later intents in the same replay must see workflow and field changes.
+	if strings.HasPrefix(key, "status.") || strings.HasPrefix(key, "transitions.") {
+		wf, err := issue.ParseWorkflow(r.ListConfig())
+		if err != nil {
+			return err
+		}
+		store.Workflow = wf
+	}
+	if strings.HasPrefix(key, "field.") {
+		fields, err := issue.ParseFields(r.ListConfig())
+		if err != nil {
+			return err
+		}
+		store.Fields = fields
+	}
+	return nil
 }
 
 func replayComment(store *issue.Store, parts []string, raw string) error {
@@ -324,32 +397,79 @@ func replayComment(store *issue.Store, parts []string, raw string) error {
 		text = strings.Join(parts[1:], " ")
 	}
 	_, err := store.Comment(parts[0], text, "")
-	if err != nil {
-		return err
-	}
-	return store.Commit(raw)
+	return err
 }
 
 func replayStart(store *issue.Store, parts []string, raw string) error {
-	// start <id> assignee="<name>"
+	// start <id> assignee="<name>" [lease=<dur> expires=<time> [agent="<name>"]] [steal=true]
 	if len(parts) < 1 {
 		return fmt.Errorf("malformed start intent")
 	}
 	id := parts[0]
-	var assignee string
+	var opts issue.ClaimOpts
 	for _, kv := range parts[1:] {
 		eqIdx := strings.Index(kv, "=")
 		if eqIdx == -1 {
 			continue
 		}
-		if kv[:eqIdx] == "assignee" {
-			assignee = kv[eqIdx+1:]
+		key, val := kv[:eqIdx], kv[eqIdx+1:]
+		switch key {
+		case "assignee":
+			opts.Assignee = val
+		case "lease":
+			d, err := time.ParseDuration(val)
+			if err != nil {
+				return fmt.Errorf("malformed start intent: %w", err)
+			}
+			opts.Lease = d
+		case "expires":
+			t, err := time.Parse(time.RFC3339, val)
+			if err != nil {
+				return fmt.Errorf("malformed start intent: %w", err)
+			}
+			opts.Expires = t
+		case "agent":
+			opts.Agent = val
+		case "steal":
+			opts.Steal = val == "true"
 		}
 	}
-	if _, err := store.Start(id, assignee); err != nil {
-		return err
+	_, err := store.Claim(id, opts)
+	return err
+}
+
+func replayHeartbeat(store *issue.Store, parts []string, raw string) error {
+	// heartbeat <id> assignee="<name>" lease=<dur> expires=<time>
+	if len(parts) < 1 {
+		return fmt.Errorf("malformed heartbeat intent")
 	}
-	return store.Commit(raw)
+	id := parts[0]
+	var opts issue.HeartbeatOpts
+	for _, kv := range parts[1:] {
+		eqIdx := strings.Index(kv, "=")
+		if eqIdx == -1 {
+			continue
+		}
+		key, val := kv[:eqIdx], kv[eqIdx+1:]
+		switch key {
+		case "assignee":
+			opts.Assignee = val
+		case "lease":
+			d, err := time.ParseDuration(val)
+			if err != nil {
+				return fmt.Errorf("malformed heartbeat intent: %w", err)
+			}
+			opts.Lease = d
+		case "expires":
+			t, err := time.Parse(time.RFC3339, val)
+			if err != nil {
+				return fmt.Errorf("malformed heartbeat intent: %w", err)
+			}
+			opts.Expires = t
+		}
+	}
+	_, err := store.Heartbeat(id, opts)
+	return err
 }
 
 func replayDefer(store *issue.Store, parts []string, raw string) error {
@@ -364,10 +484,17 @@ func replayDefer(store *issue.Store, parts []string, raw string) error {
 		Status:     &status,
 		DeferUntil: &date,
 	}
-	if _, err := store.Update(id, opts); err != nil {
-		return err
+	_, err := store.Update(id, opts)
+	return err
+}
+
+func replayRecur(store *issue.Store, parts []string, raw string) error {
+	// recur <prev-id> <next-id> until <date>
+	if len(parts) < 4 || parts[2] != "until" {
+		return fmt.Errorf("malformed recur intent")
 	}
-	return store.Commit(raw)
+	_, err := store.Recur(parts[0], issue.RecurOpts{ID: parts[1], DeferUntil: parts[3]})
+	return err
 }
 
 func replayUndefer(store *issue.Store, parts []string, raw string) error {
@@ -382,27 +509,60 @@ func replayUndefer(store *issue.Store, parts []string, raw string) error {
 		Status:     &status,
 		DeferUntil: &empty,
 	}
-	if _, err := store.Update(id, opts); err != nil {
-		return err
+	_, err := store.Update(id, opts)
+	return err
+}
+
+func replayView(store *issue.Store, parts []string, raw string) error {
+	// view save <name> "<arg>"... | view delete <name>
+	if len(parts) < 2 {
+		return fmt.Errorf("malformed view intent")
 	}
-	return store.Commit(raw)
+	switch parts[0] {
+	case "save":
+		return store.SaveView(parts[1], parts[2:])
+	case "delete":
+		return store.DeleteView(parts[1])
+	}
+	return fmt.Errorf("malformed view intent: unknown action %q", parts[0])
+}
+
+func replayTemplate(store *issue.Store, parts []string, raw string) error {
+	// template save <name> "<json>" | template delete <name>
+	if len(parts) < 2 {
+		return fmt.Errorf("malformed template intent")
+	}
+	switch parts[0] {
+	case "save":
+		if len(parts) < 3 {
+			return fmt.Errorf("malformed template intent")
+		}
+		t, err := issue.DecodeTemplate(parts[1], []byte(parts[2]))
+		if err != nil {
+			return err
+		}
+		return store.SaveTemplate(parts[1], t)
+	case "delete":
+		return store.DeleteTemplate(parts[1])
+	}
+	return fmt.Errorf("malformed template intent: unknown action %q", parts[0])
 }
 
 // ParseIntent splits an intent string respecting quoted strings.
-// Backslash-escaped quotes (\") inside quoted regions are treated as
-// literal quote characters, matching Go's %q output format.
+// Escape sequences inside quoted regions (\", \n, \\ and the rest of
+// Go's %q output format) are decoded.
 func ParseIntent(raw string) []string {
 	var parts []string
 	var current strings.Builder
 	inQuote := false
-	runes := []rune(raw)
 
-	for i := 0; i < len(runes); i++ {
-		ch := runes[i]
-		if ch == '\\' && inQuote && i+1 < len(runes) && runes[i+1] == '"' {
-			current.WriteRune('"')
-			i++ // skip the escaped quote
-			continue
+	for i := 0; i < len(raw); i++ {
+		ch := raw[i]
+		if ch == '\\' && inQuote {
+			if n := writeEscape(&current, raw[i:]); n > 0 {
+				i += n - 1 // skip the rest of the escape
+				continue
+			}
 		}
 		if ch == '"' {
 			inQuote = !inQuote
@@ -415,7 +575,7 @@ func ParseIntent(raw string) []string {
 			}
 			continue
 		}
-		current.WriteRune(ch)
+		current.WriteByte(ch)
 	}
 	if current.Len() > 0 {
 		parts = append(parts, current.String())
@@ -423,8 +583,8 @@ func ParseIntent(raw string) []string {
 	return parts
 }
 
-// ExtractQuoted extracts the first quoted string from a raw intent.
-// Handles backslash-escaped quotes (\") inside the quoted region.
+// ExtractQuoted extracts the first quoted string from a raw intent,
+// decoding escape sequences as ParseIntent does.
 func ExtractQuoted(raw string) string {
 	start := strings.Index(raw, "\"")
 	if start == -1 {
@@ -432,10 +592,11 @@ func ExtractQuoted(raw string) string {
 	}
 	var result strings.Builder
 	for i := start + 1; i < len(raw); i++ {
-		if raw[i] == '\\' && i+1 < len(raw) && raw[i+1] == '"' {
-			result.WriteByte('"')
-			i++ // skip the escaped quote
-			continue
+		if raw[i] == '\\' {
+			if n := writeEscape(&result, raw[i:]); n > 0 {
+				i += n - 1 // skip the rest of the escape
+				continue
+			}
 		}
 		if raw[i] == '"' {
 			return result.String()
@@ -444,3 +605,18 @@ func ExtractQuoted(raw string) string {
 	}
 	return ""
 }
+
+// writeEscape decodes the escape sequence at the start of s into b and
+// returns its length, or 0 when s does not start with a valid one.
+func writeEscape(b *strings.Builder, s string) int {
+	r, multibyte, tail, err := strconv.UnquoteChar(s, '"')
+	if err != nil {
+		return 0
+	}
+	if multibyte || r < utf8.RuneSelf {
+		b.WriteRune(r)
+	} else {
+		b.WriteByte(byte(r)) // \xNN: a raw byte
+	}
+	return len(s) - len(tail)
+}
@@ -1,7 +1,10 @@
 package intent_test
 
 import (
+	"fmt"
 	"os"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/jallum/beadwork/internal/intent"
@@ -431,6 +434,7 @@ func TestExtractQuoted(t *testing.T) {
 		{`create test-0000 p1 bug "Unmatched opening`, ""},
 		{`create test-0000 p1 bug ""`, ""},
 		{`create test-0000 p1 bug "one" extra "two"`, "one"},
+		{`create test-0000 p1 bug "Tab\there \"quoted\""`, "Tab\there \"quoted\""},
 	}
 	for _, tt := range tests {
 		got := intent.ExtractQuoted(tt.input)
@@ -449,6 +453,8 @@ func TestParseIntent(t *testing.T) {
 		{`close test-1234`, []string{"close", "test-1234"}},
 		{``, nil},
 		{`link a blocks b`, []string{"link", "a", "blocks", "b"}},
+		{`update a description="line one\nsaid \"hi\"\\"`, []string{"update", "a", "description=line one\nsaid \"hi\"\\"}},
+		{`comment a "caf\u00e9 \q"`, []string{"comment", "a", "café \\q"}},
 	}
 	for _, tt := range tests {
 		got := intent.ParseIntent(tt.input)
@@ -536,6 +542,28 @@ func TestReplayDeleteNonexistent(t *testing.T) {
 	}
 }
 
+func TestReplayUndelete(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	iss, _ := env.Store.Create("Comes back", issue.CreateOpts{})
+	env.CommitIntent("create " + iss.ID)
+	env.Store.Delete(iss.ID)
+	env.CommitIntent("delete " + iss.ID)
+
+	errs := intent.Replay(env.Store, []string{"undelete " + iss.ID})
+	if len(errs) > 0 {
+		t.Fatalf("Replay errors: %v", errs)
+	}
+	got, err := env.Store.Get(iss.ID)
+	if err != nil {
+		t.Fatalf("issue should exist after replay undelete: %v", err)
+	}
+	if got.Title != "Comes back" {
+		t.Errorf("Title = %q", got.Title)
+	}
+}
+
 func TestReplayComment(t *testing.T) {
 	env := testutil.NewEnv(t)
 	defer env.Cleanup()
@@ -666,3 +694,215 @@ func TestReplayCreateWithDue(t *testing.T) {
 		t.Errorf("Status = %q, want open (due should not change status)", got.Status)
 	}
 }
+
+func TestReplayEnforcesWorkflowFromConfigIntent(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	errs := intent.Replay(env.Store, []string{
+		`create test-0000 p1 task "Reviewed work"`,
+		"config status.review=active",
+		"config transitions.review=in_progress",
+		"update test-0000 status=review",
+		"close test-0000",
+	})
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "cannot move from review to closed") {
+		t.Fatalf("Replay errors = %v, want one refused close", errs)
+	}
+	iss, _ := env.Store.Get("test-0000")
+	if iss.Status != "review" {
+		t.Errorf("status = %q, want review", iss.Status)
+	}
+}
+
+func TestReplayCustomFields(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	errs := intent.Replay(env.Store, []string{
+		"config field.severity=enum:low,high",
+		"config field.estimate=int",
+		`create test-0000 p2 bug "Crash" field.severity="high"`,
+		`update test-0000 field.estimate="5" field.severity=""`,
+		`update test-0000 field.severity="urgent"`,
+	})
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), `"urgent" is not one of low, high`) {
+		t.Fatalf("Replay errors = %v, want one rejected enum value", errs)
+	}
+	iss, _ := env.Store.Get("test-0000")
+	if len(iss.Fields) != 1 || iss.Fields["estimate"] != "5" {
+		t.Errorf("Fields = %v, want estimate=5", iss.Fields)
+	}
+}
+
+func TestReplayRelationLinks(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	errs := intent.Replay(env.Store, []string{
+		`create test-0000 p2 task "Canonical"`,
+		`create test-0001 p2 task "Copy"`,
+		"link test-0001 duplicates test-0000\nclose test-0001 reason=\"duplicate of test-0000\"",
+		"link test-0000 relates-to test-0001",
+		"unlink test-0001 relates-to test-0000",
+		"link test-0000 mentions test-0001",
+	})
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), `unknown kind "mentions"`) {
+		t.Fatalf("Replay errors = %v, want one unknown kind", errs)
+	}
+	dup, _ := env.Store.Get("test-0001")
+	if dup.Status != "closed" || len(dup.Duplicates) != 1 || len(dup.RelatesTo) != 0 {
+		t.Errorf("dup = status %s, duplicates %v, relates_to %v", dup.Status, dup.Duplicates, dup.RelatesTo)
+	}
+}
+
+func TestReplayViews(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	errs := intent.Replay(env.Store, []string{
+		`view save triage "--query" "label:\"needs triage\" -assignee:none" "--all"`,
+		`view save stale "--status" "open"`,
+		"view delete stale",
+		"view delete missing",
+	})
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "no such view: missing") {
+		t.Fatalf("Replay errors = %v", errs)
+	}
+	v, err := env.Store.GetView("triage")
+	if err != nil {
+		t.Fatalf("GetView: %v", err)
+	}
+	want := []string{"--query", `label:"needs triage" -assignee:none`, "--all"}
+	if !reflect.DeepEqual(v.Args, want) {
+		t.Errorf("Args = %q, want %q", v.Args, want)
+	}
+	if _, err := env.Store.GetView("stale"); err == nil {
+		t.Error("stale view should be deleted")
+	}
+}
+
+func TestReplayRepair(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	env.Store.Create("A", issue.CreateOpts{})
+	env.Repo.Commit("setup")
+	env.Store.FS.WriteFile("status/closed/test-gone", []byte{})
+	env.Repo.Commit("damage")
+
+	if errs := intent.Replay(env.Store, []string{"repair"}); len(errs) != 0 {
+		t.Fatalf("Replay errors = %v", errs)
+	}
+	if env.MarkerExists("status/closed/test-gone") {
+		t.Error("repair intent did not remove the stray marker")
+	}
+}
+
+func TestReplayLeases(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	iss, _ := env.Store.Create("Leased", issue.CreateOpts{})
+	env.Repo.Commit("create " + iss.ID)
+
+	errs := intent.Replay(env.Store, []string{
+		fmt.Sprintf(`start %s assignee="bob" lease=2h expires=2099-01-01T12:00:00Z agent="claude-code"`, iss.ID),
+		fmt.Sprintf(`heartbeat %s assignee="bob" lease=3h expires=2099-01-01T15:00:00Z`, iss.ID),
+	})
+	if len(errs) != 0 {
+		t.Fatalf("Replay errors = %v", errs)
+	}
+	got, _ := env.Store.Get(iss.ID)
+	want := issue.Lease{Agent: "claude-code", Duration: "3h", Expires: "2099-01-01T15:00:00Z"}
+	if got.Status != "in_progress" || got.Assignee != "bob" || got.Lease == nil || *got.Lease != want {
+		t.Errorf("after replay: status=%s assignee=%s lease=%+v", got.Status, got.Assignee, got.Lease)
+	}
+
+	// A second start against the live lease conflicts unless it steals.
+	if errs := intent.Replay(env.Store, []string{fmt.Sprintf(`start %s assignee="carol"`, iss.ID)}); len(errs) != 1 {
+		t.Errorf("start over a live lease: errors = %v, want 1", errs)
+	}
+	if errs := intent.Replay(env.Store, []string{fmt.Sprintf(`start %s assignee="carol" steal=true`, iss.ID)}); len(errs) != 0 {
+		t.Fatalf("steal: errors = %v", errs)
+	}
+	got, _ = env.Store.Get(iss.ID)
+	if got.Assignee != "carol" || got.Lease != nil {
+		t.Errorf("after steal: assignee=%s lease=%+v", got.Assignee, got.Lease)
+	}
+}
+
+func TestReplayRecur(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	errs := intent.Replay(env.Store, []string{
+		`create test-0000 p1 task "Weekly report" every="monday 9am"`,
+		"close test-0000\nrecur test-0000 test-0001 until 2027-03-15T09:00:00Z",
+	})
+	if len(errs) > 0 {
+		t.Fatalf("Replay errors: %v", errs)
+	}
+
+	next, err := env.Store.Get("test-0001")
+	if err != nil {
+		t.Fatalf("next instance not created: %v", err)
+	}
+	if next.Title != "Weekly report" || next.Every != "monday 9am" || next.Priority != 1 {
+		t.Errorf("next = %q every=%q p%d", next.Title, next.Every, next.Priority)
+	}
+	if next.Status != "deferred" || next.DeferUntil != "2027-03-15T09:00:00Z" {
+		t.Errorf("next status=%s defer=%s, want deferred until 2027-03-15T09:00:00Z", next.Status, next.DeferUntil)
+	}
+	if len(next.Supersedes) != 1 || next.Supersedes[0] != "test-0000" {
+		t.Errorf("supersedes = %v, want [test-0000]", next.Supersedes)
+	}
+
+	if errs := intent.Replay(env.Store, []string{"recur test-0000"}); len(errs) == 0 {
+		t.Error("expected error for malformed recur intent")
+	}
+}
+
+func TestReplayTemplate(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	errs := intent.Replay(env.Store, []string{
+		`template save bug "{\"type\":\"bug\",\"description\":\"Steps:\\n1.\"}"`,
+		"template save spike \"{}\"",
+		"template delete spike",
+	})
+	if len(errs) > 0 {
+		t.Fatalf("Replay errors: %v", errs)
+	}
+	tpl, err := env.Store.GetTemplate("bug")
+	if err != nil {
+		t.Fatalf("GetTemplate: %v", err)
+	}
+	if tpl.Type != "bug" || tpl.Description != "Steps:\n1." {
+		t.Errorf("template = %+v", tpl)
+	}
+	if _, err := env.Store.GetTemplate("spike"); err == nil {
+		t.Error("deleted template still present")
+	}
+}
+
+func TestReplayCreateParentAndLabels(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	errs := intent.Replay(env.Store, []string{
+		"create test-0000 p1 epic \"Epic\" labels=roadmap\ncreate test-0001 p2 task \"Child\" parent=test-0000 labels=design,ux",
+	})
+	if len(errs) > 0 {
+		t.Fatalf("Replay errors: %v", errs)
+	}
+	child, _ := env.Store.Get("test-0001")
+	if child.Parent != "test-0000" || strings.Join(child.Labels, ",") != "design,ux" {
+		t.Errorf("child parent=%s labels=%v", child.Parent, child.Labels)
+	}
+	root, _ := env.Store.Get("test-0000")
+	if len(root.Labels) != 1 || root.Labels[0] != "roadmap" {
+		t.Errorf("root labels = %v", root.Labels)
+	}
+}
@@ -0,0 +1,96 @@
+package intent
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jallum/beadwork/internal/issue"
+)
+
+// RevertFields lists the fields Revert can restore. "deps" covers blocks
+// links in both directions.
+var RevertFields = []string{"title", "description", "priority", "type", "due", "parent", "labels", "deps"}
+
+// Revert returns the intents that set cur's fields back to their values in
+// old, an earlier revision of the same issue. Only the named fields are
+// restored, or all of RevertFields when fields is empty. A parent or blocks
+// link to an issue that no longer exists, per exists, cannot be restored
+// and is skipped. Unlinks come before links so a restored link is not
+// refused as a cycle through one that is about to go.
+func Revert(cur, old *issue.Issue, fields []string, exists func(id string) bool) []string {
+	if len(fields) == 0 {
+		fields = RevertFields
+	}
+	want := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		want[f] = true
+	}
+
+	var out, kvs []string
+	if want["title"] && old.Title != cur.Title {
+		kvs = append(kvs, fmt.Sprintf("title=%q", old.Title))
+	}
+	if want["description"] && old.Description != cur.Description {
+		kvs = append(kvs, fmt.Sprintf("description=%q", old.Description))
+	}
+	if want["priority"] && old.Priority != cur.Priority {
+		kvs = append(kvs, fmt.Sprintf("priority=%d", old.Priority))
+	}
+	if want["type"] && old.Type != cur.Type {
+		kvs = append(kvs, "type="+old.Type)
+	}
+	if want["due"] && old.Due != cur.Due {
+		kvs = append(kvs, "due="+old.Due)
+	}
+	if want["parent"] && old.Parent != cur.Parent && (old.Parent == "" || exists(old.Parent)) {
+		kvs = append(kvs, "parent="+old.Parent)
+	}
+	if len(kvs) > 0 {
+		out = append(out, fmt.Sprintf("update %s %s", cur.ID, strings.Join(kvs, " ")))
+	}
+
+	if want["labels"] {
+		var ops []string
+		for _, l := range without(old.Labels, cur.Labels) {
+			ops = append(ops, "+"+l)
+		}
+		for _, l := range without(cur.Labels, old.Labels) {
+			ops = append(ops, "-"+l)
+		}
+		if len(ops) > 0 {
+			out = append(out, fmt.Sprintf("label %s %s", cur.ID, strings.Join(ops, " ")))
+		}
+	}
+
+	if want["deps"] {
+		for _, b := range without(cur.Blocks, old.Blocks) {
+			out = append(out, fmt.Sprintf("unlink %s blocks %s", cur.ID, b))
+		}
+		for _, b := range without(cur.BlockedBy, old.BlockedBy) {
+			out = append(out, fmt.Sprintf("unlink %s blocks %s", b, cur.ID))
+		}
+		for _, b := range without(old.Blocks, cur.Blocks) {
+			if exists(b) {
+				out = append(out, fmt.Sprintf("link %s blocks %s", cur.ID, b))
+			}
+		}
+		for _, b := range without(old.BlockedBy, cur.BlockedBy) {
+			if exists(b) {
+				out = append(out, fmt.Sprintf("link %s blocks %s", b, cur.ID))
+			}
+		}
+	}
+	return out
+}
+
+// without returns the values of a that are not in b, in a's order.
+func without(a, b []string) []string {
+	var out []string
+	for _, v := range a {
+		if !slices.Contains(b, v) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
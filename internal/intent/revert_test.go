@@ -0,0 +1,37 @@
+package intent_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jallum/beadwork/internal/intent"
+	"github.com/jallum/beadwork/internal/issue"
+)
+
+func TestRevert(t *testing.T) {
+	old := &issue.Issue{ID: "test-a", Title: "Old", Description: "line one\nline two", Priority: 1, Type: "task",
+		Parent: "test-p", Labels: []string{"api", "bug"}, Blocks: []string{"test-b"}, BlockedBy: []string{"test-gone"}}
+	cur := &issue.Issue{ID: "test-a", Title: "New", Description: "rewritten", Priority: 1, Type: "task",
+		Labels: []string{"bug", "ui"}, BlockedBy: []string{"test-c"}}
+	exists := func(id string) bool { return id != "test-gone" }
+
+	got := intent.Revert(cur, old, nil, exists)
+	want := []string{
+		`update test-a title="Old" description="line one\nline two" parent=test-p`,
+		"label test-a +api -ui",
+		"unlink test-c blocks test-a",
+		"link test-a blocks test-b",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Revert =\n%q\nwant\n%q", got, want)
+	}
+
+	got = intent.Revert(cur, old, []string{"title"}, exists)
+	if want := []string{`update test-a title="Old"`}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Revert title only = %q, want %q", got, want)
+	}
+
+	if got := intent.Revert(cur, cur, nil, exists); len(got) != 0 {
+		t.Errorf("Revert of identical revisions = %q, want none", got)
+	}
+}
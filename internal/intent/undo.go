@@ -0,0 +1,342 @@
+package intent
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/jallum/beadwork/internal/issue"
+)
+
+// PriorReader reads a file from the beadwork tree as it was immediately
+// before the commit being undone (typically TreeFS.ReadFileAt on the
+// commit's parent).
+type PriorReader func(path string) ([]byte, error)
+
+// Inverse computes the intent lines that undo a committed intent message.
+// Lines are returned in the order they must be applied: the last line of
+// the message is undone first. Prior field values (titles, statuses,
+// assignees, labels, config values) are read through prior, so the
+// inverse restores exactly what the commit overwrote.
+//
+// Informational lines ("unblocked <id>") are skipped. Verbs whose effect
+// cannot be expressed as an intent (comment, delete, attach, init,
+// repair) are refused with an error naming the verb.
+func Inverse(msg string, prior PriorReader) ([]string, error) {
+	lines := strings.Split(strings.TrimSpace(msg), "\n")
+	var out []string
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := strings.TrimRight(lines[i], " \t\r")
+		if line == "" {
+			continue
+		}
+		inv, err := inverseOne(line, prior)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, inv...)
+	}
+	return out, nil
+}
+
+// IssueIDs returns the issue IDs an intent message refers to, in first-seen
+// order. Used to detect later commits that touched the same issues.
+func IssueIDs(msg string) []string {
+	seen := make(map[string]bool)
+	var ids []string
+	add := func(id string) {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	for _, line := range strings.Split(strings.TrimSpace(msg), "\n") {
+		parts := ParseIntent(strings.TrimSpace(line))
+		if len(parts) < 2 {
+			continue
+		}
+		switch parts[0] {
+		case "config", "init", "view", "template":
+			continue
+		case "link", "unlink":
+			add(parts[1])
+			if len(parts) >= 4 {
+				add(parts[3])
+			}
+		case "recur":
+			add(parts[1])
+			if len(parts) >= 3 {
+				add(parts[2])
+			}
+		default:
+			add(parts[1])
+		}
+	}
+	return ids
+}
+
+func inverseOne(raw string, prior PriorReader) ([]string, error) {
+	parts := ParseIntent(raw)
+	if len(parts) == 0 {
+		return nil, nil
+	}
+	verb := parts[0]
+	args := parts[1:]
+	if verb == "repair" {
+		return nil, fmt.Errorf("cannot undo %q: repair has no inverse intent", raw)
+	}
+	if verb != "unblocked" && len(args) == 0 {
+		return nil, fmt.Errorf("malformed %s intent", verb)
+	}
+
+	switch verb {
+	case "unblocked":
+		return nil, nil
+	case "create", "undelete":
+		return []string{"delete " + args[0]}, nil
+	case "recur":
+		if len(args) < 2 {
+			return nil, fmt.Errorf("malformed recur intent")
+		}
+		return []string{"delete " + args[1]}, nil
+	case "link", "unlink":
+		if len(args) < 3 {
+			return nil, fmt.Errorf("malformed %s intent", verb)
+		}
+		opposite := "unlink"
+		if verb == "unlink" {
+			opposite = "link"
+		}
+		return []string{fmt.Sprintf("%s %s %s %s", opposite, args[0], args[1], args[2])}, nil
+	case "close":
+		return inverseClose(args[0], prior)
+	case "reopen":
+		return inverseReopen(args[0], prior)
+	case "update":
+		return inverseUpdate(args[0], args[1:], prior)
+	case "start":
+		return inverseStart(args[0], prior)
+	case "heartbeat":
+		return inverseHeartbeat(args[0], prior)
+	case "defer", "undefer":
+		return inverseStatus(args[0], prior)
+	case "label":
+		return inverseLabel(args[0], args[1:], prior)
+	case "config":
+		return inverseConfig(args[0], prior)
+	case "view":
+		if len(args) < 2 {
+			return nil, fmt.Errorf("malformed view intent")
+		}
+		return inverseView(args[1], prior)
+	case "template":
+		if len(args) < 2 {
+			return nil, fmt.Errorf("malformed template intent")
+		}
+		return inverseTemplate(args[1], prior)
+	case "comment", "delete", "attach", "init":
+		return nil, fmt.Errorf("cannot undo %q: %s has no inverse intent", raw, verb)
+	}
+	return nil, fmt.Errorf("cannot undo %q: unknown intent %q", raw, verb)
+}
+
+func priorIssue(id string, prior PriorReader) (*issue.Issue, error) {
+	data, err := prior("issues/" + id + ".json")
+	if err != nil {
+		return nil, fmt.Errorf("%s did not exist before this commit", id)
+	}
+	var iss issue.Issue
+	if err := json.Unmarshal(data, &iss); err != nil {
+		return nil, fmt.Errorf("corrupt issue %s: %w", id, err)
+	}
+	return &iss, nil
+}
+
+// restoreStatus returns an update intent that puts id back into prior's
+// status along with the fields status transitions overwrite (assignee
+// and defer date).
+func restoreStatus(prev *issue.Issue) string {
+	return fmt.Sprintf("update %s status=%s assignee=%q defer=%s", prev.ID, prev.Status, prev.Assignee, prev.DeferUntil)
+}
+
+func inverseClose(id string, prior PriorReader) ([]string, error) {
+	prev, err := priorIssue(id, prior)
+	if err != nil {
+		return nil, err
+	}
+	out := []string{"reopen " + id}
+	if prev.Status != "open" || prev.Assignee != "" {
+		out = append(out, restoreStatus(prev))
+	}
+	return out, nil
+}
+
+func inverseReopen(id string, prior PriorReader) ([]string, error) {
+	prev, err := priorIssue(id, prior)
+	if err != nil {
+		return nil, err
+	}
+	if prev.Status == "closed" {
+		line := "close " + id
+		if prev.CloseReason != "" {
+			line += fmt.Sprintf(" reason=%q", prev.CloseReason)
+		}
+		return []string{line}, nil
+	}
+	return []string{restoreStatus(prev)}, nil
+}
+
+func inverseStatus(id string, prior PriorReader) ([]string, error) {
+	prev, err := priorIssue(id, prior)
+	if err != nil {
+		return nil, err
+	}
+	return []string{restoreStatus(prev)}, nil
+}
+
+// inverseStart reverts a start from open. Taking over someone else's
+// claim cannot be undone: the update that restores the status would not
+// restore their lease.
+func inverseStart(id string, prior PriorReader) ([]string, error) {
+	prev, err := priorIssue(id, prior)
+	if err != nil {
+		return nil, err
+	}
+	if prev.Status == "in_progress" {
+		return nil, fmt.Errorf("cannot undo start of %s: it took over %s's claim", id, prev.Assignee)
+	}
+	return []string{restoreStatus(prev)}, nil
+}
+
+func inverseHeartbeat(id string, prior PriorReader) ([]string, error) {
+	prev, err := priorIssue(id, prior)
+	if err != nil {
+		return nil, err
+	}
+	if prev.Lease == nil {
+		return nil, fmt.Errorf("%s had no lease before this commit", id)
+	}
+	return []string{fmt.Sprintf("heartbeat %s assignee=%q lease=%s expires=%s", id, prev.Assignee, prev.Lease.Duration, prev.Lease.Expires)}, nil
+}
+
+func inverseUpdate(id string, kvs []string, prior PriorReader) ([]string, error) {
+	prev, err := priorIssue(id, prior)
+	if err != nil {
+		return nil, err
+	}
+	var restore []string
+	touchedStatus := false
+	for _, kv := range kvs {
+		eqIdx := strings.Index(kv, "=")
+		if eqIdx == -1 {
+			continue
+		}
+		switch key := kv[:eqIdx]; key {
+		case "status":
+			touchedStatus = true
+		case "defer":
+			// bw update --defer also moves the issue to deferred.
+			touchedStatus = true
+		case "assignee":
+			restore = append(restore, fmt.Sprintf("assignee=%q", prev.Assignee))
+		case "priority":
+			restore = append(restore, fmt.Sprintf("priority=%d", prev.Priority))
+		case "type":
+			restore = append(restore, "type="+prev.Type)
+		case "title":
+			restore = append(restore, fmt.Sprintf("title=%q", prev.Title))
+		case "parent":
+			restore = append(restore, "parent="+prev.Parent)
+		case "description":
+			restore = append(restore, fmt.Sprintf("description=%q", prev.Description))
+		case "due":
+			restore = append(restore, "due="+prev.Due)
+		case "every":
+			restore = append(restore, fmt.Sprintf("every=%q", prev.Every))
+		default:
+			if name, ok := strings.CutPrefix(key, "field."); ok {
+				restore = append(restore, fmt.Sprintf("field.%s=%q", name, prev.Fields[name]))
+			}
+		}
+	}
+	if touchedStatus {
+		restore = append(restore, "status="+prev.Status, "defer="+prev.DeferUntil)
+	}
+	if len(restore) == 0 {
+		return nil, nil
+	}
+	return []string{fmt.Sprintf("update %s %s", id, strings.Join(restore, " "))}, nil
+}
+
+func inverseLabel(id string, ops []string, prior PriorReader) ([]string, error) {
+	prev, err := priorIssue(id, prior)
+	if err != nil {
+		return nil, err
+	}
+	had := make(map[string]bool, len(prev.Labels))
+	for _, l := range prev.Labels {
+		had[l] = true
+	}
+	var inv []string
+	for _, op := range ops {
+		switch {
+		case strings.HasPrefix(op, "+"):
+			if name := op[1:]; !had[name] {
+				inv = append(inv, "-"+name)
+			}
+		case strings.HasPrefix(op, "-"):
+			if name := op[1:]; had[name] {
+				inv = append(inv, "+"+name)
+			}
+		}
+	}
+	if len(inv) == 0 {
+		return nil, nil
+	}
+	return []string{fmt.Sprintf("label %s %s", id, strings.Join(inv, " "))}, nil
+}
+
+func inverseConfig(kv string, prior PriorReader) ([]string, error) {
+	eqIdx := strings.Index(kv, "=")
+	if eqIdx == -1 {
+		return nil, fmt.Errorf("malformed config intent: missing '='")
+	}
+	key := kv[:eqIdx]
+	data, err := prior(".bwconfig")
+	if err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			if strings.HasPrefix(line, key+"=") {
+				return []string{"config " + line}, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("cannot undo config %s: it was not set before", key)
+}
+
+// inverseTemplate restores the template name as it was before the commit:
+// saved with its prior JSON, or deleted if it did not exist.
+func inverseTemplate(name string, prior PriorReader) ([]string, error) {
+	data, err := prior("templates/" + name)
+	if err != nil {
+		return []string{"template delete " + name}, nil
+	}
+	return []string{fmt.Sprintf("template save %s %q", name, strings.TrimSpace(string(data)))}, nil
+}
+
+// inverseView restores the view name as it was before the commit: saved
+// with its prior arguments, or deleted if it did not exist.
+func inverseView(name string, prior PriorReader) ([]string, error) {
+	data, err := prior("views/" + name)
+	if err != nil {
+		return []string{"view delete " + name}, nil
+	}
+	var args []string
+	if err := json.Unmarshal(data, &args); err != nil {
+		return nil, fmt.Errorf("cannot undo view %s: %w", name, err)
+	}
+	line := "view save " + name
+	for _, a := range args {
+		line += fmt.Sprintf(" %q", a)
+	}
+	return []string{line}, nil
+}
@@ -0,0 +1,139 @@
+package intent_test
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/jallum/beadwork/internal/intent"
+	"github.com/jallum/beadwork/internal/issue"
+	"github.com/jallum/beadwork/internal/testutil"
+)
+
+// priorFiles returns a PriorReader over an in-memory snapshot.
+func priorFiles(t *testing.T, issues ...issue.Issue) intent.PriorReader {
+	t.Helper()
+	files := make(map[string][]byte)
+	for _, iss := range issues {
+		data, err := json.Marshal(iss)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files["issues/"+iss.ID+".json"] = data
+	}
+	files[".bwconfig"] = []byte("prefix=test\ndefault.priority=2\n")
+	files["views/mine"] = []byte(`["--assignee","alice","-q","p<=1"]`)
+	files["templates/bug"] = []byte(`{"type":"bug","description":"Steps:\n1."}` + "\n")
+	return func(path string) ([]byte, error) {
+		if data, ok := files[path]; ok {
+			return data, nil
+		}
+		return nil, os.ErrNotExist
+	}
+}
+
+func TestInverse(t *testing.T) {
+	prior := priorFiles(t,
+		issue.Issue{ID: "test-a", Title: "Old title", Status: "in_progress", Assignee: "alice", Priority: 2, Type: "task", Labels: []string{"bug"}, Fields: map[string]string{"severity": "low"}},
+		issue.Issue{ID: "test-b", Title: "Done", Status: "closed", CloseReason: "shipped", Priority: 1, Type: "task"},
+		issue.Issue{ID: "test-c", Title: "Waiting", Status: "deferred", DeferUntil: "2027-01-01", Priority: 3, Type: "bug"},
+		issue.Issue{ID: "test-d", Title: "Leased", Status: "in_progress", Assignee: "carol", Lease: &issue.Lease{Duration: "1h", Expires: "2027-01-01T10:00:00Z"}},
+	)
+
+	tests := []struct {
+		name string
+		msg  string
+		want []string
+	}{
+		{"create", `create test-z p1 task "New"`, []string{"delete test-z"}},
+		{"undelete", "undelete test-z", []string{"delete test-z"}},
+		{"close", "close test-a reason=\"oops\"\nunblocked test-b", []string{"reopen test-a", `update test-a status=in_progress assignee="alice" defer=`}},
+		{"reopen closed", "reopen test-b", []string{`close test-b reason="shipped"`}},
+		{"reopen in_progress", "reopen test-a", []string{`update test-a status=in_progress assignee="alice" defer=`}},
+		{"update", `update test-a title="New" priority=0`, []string{`update test-a title="Old title" priority=2`}},
+		{"update fields", `update test-a field.severity="high" field.estimate="3"`, []string{`update test-a field.severity="low" field.estimate=""`}},
+		{"update defer", "update test-c defer=2028-01-01", []string{"update test-c status=deferred defer=2027-01-01"}},
+		{"update every", `update test-a every="2w"`, []string{`update test-a every=""`}},
+		{"close recurring", "close test-a\nrecur test-a test-n until 2027-01-08", []string{"delete test-n", "reopen test-a", `update test-a status=in_progress assignee="alice" defer=`}},
+		{"start", `start test-c assignee="bob"`, []string{`update test-c status=deferred assignee="" defer=2027-01-01`}},
+		{"start with lease", `start test-c assignee="bob" lease=2h expires=2027-01-01T12:00:00Z`, []string{`update test-c status=deferred assignee="" defer=2027-01-01`}},
+		{"heartbeat", `heartbeat test-d assignee="carol" lease=1h expires=2027-01-01T11:00:00Z`, []string{`heartbeat test-d assignee="carol" lease=1h expires=2027-01-01T10:00:00Z`}},
+		{"undefer", "undefer test-c", []string{`update test-c status=deferred assignee="" defer=2027-01-01`}},
+		{"link", "link test-a blocks test-b", []string{"unlink test-a blocks test-b"}},
+		{"unlink", "unlink test-a blocks test-b", []string{"link test-a blocks test-b"}},
+		{"link relation", "link test-a duplicates test-b", []string{"unlink test-a duplicates test-b"}},
+		{"label", "label test-a +bug +ui -wontfix", []string{"label test-a -ui"}},
+		{"label noop", "label test-a +bug", nil},
+		{"config", "config default.priority=1", []string{"config default.priority=2"}},
+		{"view save new", `view save triage "--all"`, []string{"view delete triage"}},
+		{"view save existing", `view save mine "--all"`, []string{`view save mine "--assignee" "alice" "-q" "p<=1"`}},
+		{"view delete", "view delete mine", []string{`view save mine "--assignee" "alice" "-q" "p<=1"`}},
+		{"template save new", `template save spike "{}"`, []string{"template delete spike"}},
+		{"template delete", "template delete bug", []string{`template save bug "{\"type\":\"bug\",\"description\":\"Steps:\\n1.\"}"`}},
+		{"create from template", "create test-y p1 epic \"Epic\"\ncreate test-z p2 task \"Child\" parent=test-y", []string{"delete test-z", "delete test-y"}},
+		{"multi-line order", "close test-a\nclose test-c", []string{
+			"reopen test-c", `update test-c status=deferred assignee="" defer=2027-01-01`,
+			"reopen test-a", `update test-a status=in_progress assignee="alice" defer=`,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := intent.Inverse(tt.msg, prior)
+			if err != nil {
+				t.Fatalf("Inverse: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Inverse(%q)\n got  %q\n want %q", tt.msg, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInverseRefusals(t *testing.T) {
+	prior := priorFiles(t,
+		issue.Issue{ID: "test-a", Status: "open"},
+		issue.Issue{ID: "test-b", Status: "in_progress", Assignee: "carol", Lease: &issue.Lease{Duration: "1h", Expires: "2027-01-01T10:00:00Z"}},
+	)
+	for _, msg := range []string{
+		`comment test-a "hi"`,
+		"delete test-a",
+		"attach test-a notes.md",
+		"init beadwork",
+		"repair",
+		`start test-b assignee="bob" steal=true`,
+		`heartbeat test-a assignee="bob" lease=1h expires=2027-01-01T10:00:00Z`,
+		"config brand.new=1",
+		"close test-missing",
+		"frobnicate test-a",
+	} {
+		if _, err := intent.Inverse(msg, prior); err == nil {
+			t.Errorf("Inverse(%q): expected error", msg)
+		}
+	}
+}
+
+func TestIssueIDs(t *testing.T) {
+	got := intent.IssueIDs("close test-a reason=\"x\"\nclose test-b\nunblocked test-c\nlink test-a blocks test-d\nrecur test-b test-e until 2027-01-08\nconfig x=1")
+	want := []string{"test-a", "test-b", "test-c", "test-d", "test-e"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("IssueIDs = %v, want %v", got, want)
+	}
+}
+
+func TestApplyDoesNotCommit(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	before, _ := env.Repo.AllCommits()
+	if err := intent.Apply(env.Store, `create test-0000 p1 task "Staged"`); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	after, _ := env.Repo.AllCommits()
+	if len(after) != len(before) {
+		t.Errorf("Apply committed: %d commits, want %d", len(after), len(before))
+	}
+	if _, err := env.Store.Get("test-0000"); err != nil {
+		t.Errorf("staged issue not visible: %v", err)
+	}
+}
@@ -140,6 +140,19 @@ func (s *Store) LoadEdges() (forward, reverse map[string][]string) {
 	forward = make(map[string][]string)
 	reverse = make(map[string][]string)
 
+	if idx := s.index(); idx != nil {
+		for blockerID, blocked := range idx.Blocks {
+			forward[blockerID] = append([]string(nil), blocked...)
+			for _, blockedID := range blocked {
+				reverse[blockedID] = append(reverse[blockedID], blockerID)
+			}
+		}
+		for _, blockers := range reverse {
+			sort.Strings(blockers)
+		}
+		return forward, reverse
+	}
+
 	entries, err := s.FS.ReadDir("blocks")
 	if err != nil {
 		return forward, reverse
@@ -241,6 +254,20 @@ func (s *Store) Ready() ([]*Issue, error) {
 		}
 	}
 
+	// In-progress issues whose lease ran out are offered again.
+	for _, id := range s.IDsWithStatus("in_progress") {
+		if overlay.descendants[id] {
+			continue
+		}
+		iss, err := s.readIssue(id)
+		if err != nil {
+			continue
+		}
+		if IsReclaimable(iss, now) && allResolved(s, overlay.effectiveBlockedBy(iss)) {
+			ready = append(ready, iss)
+		}
+	}
+
 	sortIssues(ready, now)
 	return ready, nil
 }
@@ -258,7 +285,7 @@ func (s *Store) ReadyScoped(parentID string) ([]*Issue, error) {
 
 	// Load all non-closed issues and build children map.
 	var allIDs []string
-	for _, status := range []string{"open", "in_progress", "in_review", "deferred"} {
+	for _, status := range s.unresolvedStatuses() {
 		allIDs = append(allIDs, s.IDsWithStatus(status)...)
 	}
 
@@ -297,6 +324,10 @@ func (s *Store) ReadyScoped(parentID string) ([]*Issue, error) {
 			if IsDeferralExpired(iss.DeferUntil, now) && allResolved(s, iss.BlockedBy) {
 				ready = append(ready, iss)
 			}
+		case "in_progress":
+			if IsReclaimable(iss, now) && allResolved(s, iss.BlockedBy) {
+				ready = append(ready, iss)
+			}
 		}
 	}
 
@@ -323,6 +354,7 @@ type BlockedIssue struct {
 type CloseResult struct {
 	Issue     *Issue   `json:"issue"`
 	Unblocked []*Issue `json:"unblocked"`
+	Next      *Issue   `json:"next,omitempty"` // next instance of a recurring issue
 }
 
 // Blocked returns non-closed issues that have at least one open blocker.
@@ -330,8 +362,9 @@ func (s *Store) Blocked() ([]BlockedIssue, error) {
 	overlay := s.buildSubtreeOverlay()
 
 	var ids []string
-	ids = append(ids, s.IDsWithStatus("open")...)
-	ids = append(ids, s.IDsWithStatus("in_progress")...)
+	for _, status := range s.CurrentWorkflow().NamesIn(CategoryActive) {
+		ids = append(ids, s.IDsWithStatus(status)...)
+	}
 
 	var blocked []BlockedIssue
 	for _, id := range ids {
@@ -379,7 +412,7 @@ func (s *Store) NewlyUnblocked(id string) ([]*Issue, error) {
 		if err != nil {
 			continue
 		}
-		if iss.Status == "closed" {
+		if s.isDone(iss.Status) {
 			continue
 		}
 		allResolved := true
@@ -389,7 +422,7 @@ func (s *Store) NewlyUnblocked(id string) ([]*Issue, error) {
 				allResolved = false
 				break
 			}
-			if dep.Status != "closed" {
+			if !s.isDone(dep.Status) {
 				allResolved = false
 				break
 			}
@@ -407,7 +440,7 @@ func (s *Store) ClosedBlockerSet(issues []*Issue) map[string]bool {
 	set := make(map[string]bool)
 	for _, iss := range issues {
 		for _, bid := range iss.BlockedBy {
-			if dep, err := s.Get(bid); err == nil && dep.Status == "closed" {
+			if dep, err := s.Get(bid); err == nil && s.isDone(dep.Status) {
 				set[bid] = true
 			}
 		}
@@ -466,7 +499,7 @@ func (s *Store) buildSubtreeOverlay() *subtreeOverlay {
 
 	// Load all non-closed issues
 	var allIDs []string
-	for _, status := range []string{"open", "in_progress", "in_review", "deferred"} {
+	for _, status := range s.unresolvedStatuses() {
 		allIDs = append(allIDs, s.IDsWithStatus(status)...)
 	}
 
@@ -492,7 +525,7 @@ func (s *Store) buildSubtreeOverlay() *subtreeOverlay {
 			if ci == nil {
 				continue
 			}
-			if ci.Status == "in_progress" || ci.Status == "in_review" {
+			if s.isClaimed(ci.Status) {
 				return true
 			}
 			if subtreeHasClaimedWork(c) {
@@ -511,7 +544,7 @@ func (s *Store) buildSubtreeOverlay() *subtreeOverlay {
 			return
 		}
 		// Claimed work: drill past to surface the frontier beneath it.
-		if iss.Status == "in_progress" || iss.Status == "in_review" {
+		if s.isClaimed(iss.Status) {
 			for _, c := range children[id] {
 				walk(c)
 			}
@@ -47,6 +47,7 @@ func (s *Store) Create(title string, opts CreateOpts) (*Issue, error) {
 		DeferUntil:  opts.DeferUntil,
 		Description: opts.Description,
 		Due:         opts.Due,
+		Every:       opts.Every,
 		ID:          id,
 		Labels:      []string{},
 		Parent:      parentID,
@@ -58,6 +59,9 @@ func (s *Store) Create(title string, opts CreateOpts) (*Issue, error) {
 	if issue.Type == "" {
 		issue.Type = "task"
 	}
+	if err := s.applyFields(issue, opts.Fields); err != nil {
+		return nil, err
+	}
 	if opts.Priority != nil {
 		issue.Priority = *opts.Priority
 	} else if s.DefaultPriority != nil {
@@ -79,6 +83,9 @@ func (s *Store) Create(title string, opts CreateOpts) (*Issue, error) {
 // Import writes an issue with a caller-provided ID and fields.
 // Used for importing from external sources where the ID is already known.
 func (s *Store) Import(iss *Issue) error {
+	if err := s.checkImportedFields(iss); err != nil {
+		return err
+	}
 	if err := s.writeIssue(iss); err != nil {
 		return err
 	}
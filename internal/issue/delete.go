@@ -6,6 +6,9 @@ type DeletePlan struct {
 	Blocks    []string `json:"blocks"`
 	BlockedBy []string `json:"blocked_by"`
 	Children  []string `json:"children"`
+	// Relations lists relates-to/duplicates/supersedes links to remove,
+	// keyed as in Relations.
+	Relations map[string][]string `json:"relations,omitempty"`
 }
 
 // DeletePreview returns a plan describing what would happen if the issue
@@ -32,12 +35,13 @@ func (s *Store) DeletePreview(id string) (*DeletePlan, error) {
 		Blocks:    iss.Blocks,
 		BlockedBy: iss.BlockedBy,
 		Children:  children,
+		Relations: Relations(iss),
 	}, nil
 }
 
 // Delete permanently removes an issue, cleaning up all references:
-// blocks/blocked_by marker files, related issue JSON, status markers,
-// and orphaning any children.
+// blocks/blocked_by and relation marker files, related issue JSON,
+// status markers, and orphaning any children.
 func (s *Store) Delete(id string) (*Issue, error) {
 	id, err := s.resolveID(id)
 	if err != nil {
@@ -77,6 +81,9 @@ func (s *Store) Delete(id string) (*Issue, error) {
 		}
 	}
 
+	// Drop relates-to/duplicates/supersedes links in both directions.
+	s.dropRelations(iss)
+
 	// Orphan children: clear Parent field on child issues.
 	if ch, err := s.Children(id); err == nil {
 		for _, child := range ch {
@@ -0,0 +1,249 @@
+package issue
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// FieldChange is one difference between two versions of an issue. Scalar
+// fields set Old and New; list fields set Added and Removed; the
+// description carries a unified diff.
+type FieldChange struct {
+	Field   string   `json:"field"`
+	Old     string   `json:"old,omitempty"`
+	New     string   `json:"new,omitempty"`
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+	Diff    string   `json:"diff,omitempty"`
+}
+
+// Diff lists the fields that differ from before to after, in a fixed
+// order. A nil side stands for an issue that does not exist, so creation
+// and deletion show every field set. Bookkeeping timestamps (created,
+// updated_at) are left out.
+func Diff(before, after *Issue) []FieldChange {
+	if before == nil {
+		before = &Issue{}
+	}
+	if after == nil {
+		after = &Issue{}
+	}
+	var changes []FieldChange
+	scalar := func(field, old, new string) {
+		if old != new {
+			changes = append(changes, FieldChange{Field: field, Old: old, New: new})
+		}
+	}
+	list := func(field string, old, new []string) {
+		added, removed := setDiff(old, new)
+		if len(added) > 0 || len(removed) > 0 {
+			changes = append(changes, FieldChange{Field: field, Added: added, Removed: removed})
+		}
+	}
+
+	scalar("title", before.Title, after.Title)
+	scalar("status", before.Status, after.Status)
+	scalar("priority", priorityLabel(before), priorityLabel(after))
+	scalar("type", before.Type, after.Type)
+	scalar("assignee", before.Assignee, after.Assignee)
+	scalar("parent", before.Parent, after.Parent)
+	if before.Description != after.Description {
+		changes = append(changes, FieldChange{Field: "description", Diff: UnifiedDiff(before.Description, after.Description)})
+	}
+	scalar("due", before.Due, after.Due)
+	scalar("defer_until", before.DeferUntil, after.DeferUntil)
+	scalar("every", before.Every, after.Every)
+	scalar("lease", leaseLabel(before.Lease), leaseLabel(after.Lease))
+	scalar("closed_at", before.ClosedAt, after.ClosedAt)
+	scalar("close_reason", before.CloseReason, after.CloseReason)
+	list("labels", before.Labels, after.Labels)
+	list("blocks", before.Blocks, after.Blocks)
+	list("blocked_by", before.BlockedBy, after.BlockedBy)
+	list("relates_to", before.RelatesTo, after.RelatesTo)
+	list("duplicates", before.Duplicates, after.Duplicates)
+	list("duplicated_by", before.DuplicatedBy, after.DuplicatedBy)
+	list("supersedes", before.Supersedes, after.Supersedes)
+	list("superseded_by", before.SupersededBy, after.SupersededBy)
+
+	names := make(map[string]bool)
+	for name := range before.Fields {
+		names[name] = true
+	}
+	for name := range after.Fields {
+		names[name] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+	for _, name := range sorted {
+		scalar("field."+name, before.Fields[name], after.Fields[name])
+	}
+
+	list("comments", commentLines(before.Comments), commentLines(after.Comments))
+	return changes
+}
+
+func priorityLabel(iss *Issue) string {
+	if iss.ID == "" {
+		return ""
+	}
+	return fmt.Sprintf("P%d", iss.Priority)
+}
+
+func leaseLabel(l *Lease) string {
+	if l == nil {
+		return ""
+	}
+	return "until " + l.Expires
+}
+
+// commentLines renders comments as "author: text" so added and removed
+// ones read naturally in a diff.
+func commentLines(comments []Comment) []string {
+	lines := make([]string, len(comments))
+	for i, c := range comments {
+		if c.Author != "" {
+			lines[i] = c.Author + ": " + c.Text
+		} else {
+			lines[i] = c.Text
+		}
+	}
+	return lines
+}
+
+// setDiff returns the values of new missing from old and those of old
+// missing from new, each in its original order.
+func setDiff(old, new []string) (added, removed []string) {
+	count := make(map[string]int)
+	for _, v := range old {
+		count[v]++
+	}
+	for _, v := range new {
+		if count[v] > 0 {
+			count[v]--
+			continue
+		}
+		added = append(added, v)
+	}
+	for _, v := range old {
+		if count[v] > 0 {
+			count[v]--
+			removed = append(removed, v)
+		}
+	}
+	return added, removed
+}
+
+// diffContext is how many unchanged lines UnifiedDiff keeps around each
+// change.
+const diffContext = 3
+
+// UnifiedDiff returns a line diff of a and b in unified format, hunk
+// headers included but without file headers. It is empty when a == b.
+func UnifiedDiff(a, b string) string {
+	if a == b {
+		return ""
+	}
+	al, bl := splitLines(a), splitLines(b)
+
+	// lcs[i][j] is the length of the longest common subsequence of
+	// al[i:] and bl[j:].
+	lcs := make([][]int, len(al)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(bl)+1)
+	}
+	for i := len(al) - 1; i >= 0; i-- {
+		for j := len(bl) - 1; j >= 0; j-- {
+			if al[i] == bl[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	type op struct {
+		kind   byte // ' ', '-' or '+'
+		text   string
+		ai, bi int // lines of a and b before this one
+	}
+	var ops []op
+	for i, j := 0, 0; i < len(al) || j < len(bl); {
+		switch {
+		case i < len(al) && j < len(bl) && al[i] == bl[j]:
+			ops = append(ops, op{' ', al[i], i, j})
+			i, j = i+1, j+1
+		case i < len(al) && (j == len(bl) || lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, op{'-', al[i], i, j})
+			i++
+		default:
+			ops = append(ops, op{'+', bl[j], i, j})
+			j++
+		}
+	}
+
+	var out strings.Builder
+	for k := 0; k < len(ops); {
+		if ops[k].kind == ' ' {
+			k++
+			continue
+		}
+		start := max(0, k-diffContext)
+		end := k
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+			run := end
+			for run < len(ops) && ops[run].kind == ' ' {
+				run++
+			}
+			if run < len(ops) && run-end <= 2*diffContext {
+				end = run
+				continue
+			}
+			end = min(len(ops), end+diffContext)
+			break
+		}
+		var na, nb int
+		for _, o := range ops[start:end] {
+			if o.kind != '+' {
+				na++
+			}
+			if o.kind != '-' {
+				nb++
+			}
+		}
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n", hunkRange(ops[start].ai, na), hunkRange(ops[start].bi, nb))
+		for _, o := range ops[start:end] {
+			out.WriteByte(o.kind)
+			out.WriteString(o.text)
+			out.WriteByte('\n')
+		}
+		k = end
+	}
+	return out.String()
+}
+
+// hunkRange formats one side of a hunk header the way diff -u does: an
+// empty side names the line before it.
+func hunkRange(before, n int) string {
+	if n == 0 {
+		return fmt.Sprintf("%d,0", before)
+	}
+	if n == 1 {
+		return fmt.Sprintf("%d", before+1)
+	}
+	return fmt.Sprintf("%d,%d", before+1, n)
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
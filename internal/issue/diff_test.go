@@ -0,0 +1,86 @@
+package issue
+
+import (
+	"testing"
+)
+
+func TestDiffScalarsAndLists(t *testing.T) {
+	before := &Issue{ID: "t-1", Title: "a", Status: "open", Priority: 2, Labels: []string{"x", "y"}}
+	after := &Issue{ID: "t-1", Title: "b", Status: "open", Priority: 1, Labels: []string{"y", "z"},
+		Comments: []Comment{{Text: "hi", Author: "me"}}, UpdatedAt: "2026-01-01T00:00:00Z"}
+
+	changes := Diff(before, after)
+	got := make(map[string]FieldChange)
+	for _, c := range changes {
+		got[c.Field] = c
+	}
+	if len(changes) != 4 {
+		t.Fatalf("changes = %+v, want title, priority, labels, comments", changes)
+	}
+	if c := got["title"]; c.Old != "a" || c.New != "b" {
+		t.Errorf("title = %+v", c)
+	}
+	if c := got["priority"]; c.Old != "P2" || c.New != "P1" {
+		t.Errorf("priority = %+v", c)
+	}
+	if c := got["labels"]; len(c.Added) != 1 || c.Added[0] != "z" || len(c.Removed) != 1 || c.Removed[0] != "x" {
+		t.Errorf("labels = %+v", c)
+	}
+	if c := got["comments"]; len(c.Added) != 1 || c.Added[0] != "me: hi" {
+		t.Errorf("comments = %+v", c)
+	}
+}
+
+func TestDiffCreatedAndCustomFields(t *testing.T) {
+	after := &Issue{ID: "t-1", Title: "new", Status: "open", Fields: map[string]string{"estimate": "3"}}
+	changes := Diff(nil, after)
+	var fields []string
+	for _, c := range changes {
+		fields = append(fields, c.Field)
+	}
+	want := []string{"title", "status", "priority", "field.estimate"}
+	if len(fields) != len(want) {
+		t.Fatalf("fields = %v, want %v", fields, want)
+	}
+	for i := range want {
+		if fields[i] != want[i] {
+			t.Errorf("fields[%d] = %q, want %q", i, fields[i], want[i])
+		}
+	}
+	if len(Diff(after, after)) != 0 {
+		t.Error("identical issues should have no changes")
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	a := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n"
+	b := "1\n2\n3\n4\nfive\n6\n7\n8\n9\n10\n11\n"
+	want := "@@ -2,9 +2,10 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n 9\n 10\n+11\n"
+	if got := UnifiedDiff(a, b); got != want {
+		t.Errorf("UnifiedDiff =\n%s\nwant\n%s", got, want)
+	}
+	if got := UnifiedDiff("", "x"); got != "@@ -0,0 +1 @@\n+x\n" {
+		t.Errorf("UnifiedDiff from empty = %q", got)
+	}
+	if UnifiedDiff("same", "same") != "" {
+		t.Error("UnifiedDiff of equal text should be empty")
+	}
+}
+
+func TestUnifiedDiffSeparateHunks(t *testing.T) {
+	var a, b string
+	for i := 1; i <= 20; i++ {
+		line := string(rune('a' + i - 1))
+		a += line + "\n"
+		if i == 2 || i == 18 {
+			line = "X"
+		}
+		b += line + "\n"
+	}
+	got := UnifiedDiff(a, b)
+	want := "@@ -1,5 +1,5 @@\n a\n-b\n+X\n c\n d\n e\n" +
+		"@@ -15,6 +15,6 @@\n o\n p\n q\n-r\n+X\n s\n t\n"
+	if got != want {
+		t.Errorf("UnifiedDiff =\n%s\nwant\n%s", got, want)
+	}
+}
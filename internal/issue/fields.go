@@ -0,0 +1,202 @@
+package issue
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Custom field types.
+const (
+	FieldString = "string"
+	FieldInt    = "int"
+	FieldEnum   = "enum"
+	FieldDate   = "date"
+	FieldRef    = "issue-ref" // "ref" is accepted as an alias
+)
+
+// FieldDef declares one custom field. Options lists the allowed values of
+// an enum field.
+type FieldDef struct {
+	Name    string
+	Type    string
+	Options []string
+}
+
+// FieldSchema is the set of custom fields a repo declares in .bwconfig:
+//
+//	field.severity=enum:low,medium,high
+//	field.estimate=int
+//	field.customer=string
+//	field.target=date
+//	field.spec=issue-ref
+//
+// Issues may only carry declared fields, and every value is checked
+// against its declared type when it is written.
+type FieldSchema map[string]FieldDef
+
+var fieldNameRe = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// ParseFields builds a FieldSchema from repo config key/value pairs.
+// Unrelated keys are ignored.
+func ParseFields(cfg map[string]string) (FieldSchema, error) {
+	schema := FieldSchema{}
+	for key, val := range cfg {
+		name, ok := strings.CutPrefix(key, "field.")
+		if !ok {
+			continue
+		}
+		if !fieldNameRe.MatchString(name) {
+			return nil, fmt.Errorf("%s: invalid field name %q", key, name)
+		}
+		def := FieldDef{Name: name, Type: strings.TrimSpace(val)}
+		if opts, ok := strings.CutPrefix(def.Type, FieldEnum+":"); ok {
+			def.Type = FieldEnum
+			for _, o := range strings.Split(opts, ",") {
+				if o = strings.TrimSpace(o); o != "" {
+					def.Options = append(def.Options, o)
+				}
+			}
+		}
+		if def.Type == "ref" {
+			def.Type = FieldRef
+		}
+		switch def.Type {
+		case FieldString, FieldInt, FieldDate, FieldRef:
+		case FieldEnum:
+			if len(def.Options) == 0 {
+				return nil, fmt.Errorf("%s: enum needs at least one option (enum:a,b,c)", key)
+			}
+		default:
+			return nil, fmt.Errorf("%s: type must be string, int, date, issue-ref, or enum:a,b,c", key)
+		}
+		schema[name] = def
+	}
+	return schema, nil
+}
+
+// Names returns the declared field names, sorted.
+func (fs FieldSchema) Names() []string {
+	names := make([]string, 0, len(fs))
+	for name := range fs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SortedFieldNames returns the keys of fields, sorted. Used wherever
+// fields are rendered so output and intents are deterministic.
+func SortedFieldNames(fields map[string]string) []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NormalizeField checks value against the declared type of field name and
+// returns its canonical form: ints in plain decimal, dates as given
+// (YYYY-MM-DD or RFC3339), and refs as full issue IDs.
+func (s *Store) NormalizeField(name, value string) (string, error) {
+	def, ok := s.Fields[name]
+	if !ok {
+		if len(s.Fields) == 0 {
+			return "", fmt.Errorf("unknown field %q (no fields declared; see bw config set field.<name>)", name)
+		}
+		return "", fmt.Errorf("unknown field %q (declared: %s)", name, strings.Join(s.Fields.Names(), ", "))
+	}
+	switch def.Type {
+	case FieldInt:
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return "", fmt.Errorf("field %s: %q is not an integer", name, value)
+		}
+		return strconv.Itoa(n), nil
+	case FieldDate:
+		if _, err := time.Parse("2006-01-02", value); err == nil {
+			return value, nil
+		}
+		if _, err := time.Parse(time.RFC3339, value); err == nil {
+			return value, nil
+		}
+		return "", fmt.Errorf("field %s: %q is not a date (YYYY-MM-DD or RFC3339)", name, value)
+	case FieldEnum:
+		if !containsStr(def.Options, value) {
+			return "", fmt.Errorf("field %s: %q is not one of %s", name, value, strings.Join(def.Options, ", "))
+		}
+	case FieldRef:
+		id, err := s.resolveID(value)
+		if err != nil {
+			return "", fmt.Errorf("field %s: %w", name, err)
+		}
+		return id, nil
+	}
+	return value, nil
+}
+
+// applyFields merges changes into iss.Fields after validating each value.
+// An empty value removes the field.
+func (s *Store) applyFields(iss *Issue, changes map[string]string) error {
+	for _, name := range SortedFieldNames(changes) {
+		value := changes[name]
+		if value == "" {
+			if _, ok := s.Fields[name]; !ok {
+				if _, had := iss.Fields[name]; !had {
+					return fmt.Errorf("unknown field %q", name)
+				}
+			}
+			delete(iss.Fields, name)
+			continue
+		}
+		norm, err := s.NormalizeField(name, value)
+		if err != nil {
+			return err
+		}
+		if iss.Fields == nil {
+			iss.Fields = make(map[string]string)
+		}
+		iss.Fields[name] = norm
+	}
+	if len(iss.Fields) == 0 {
+		iss.Fields = nil
+	}
+	return nil
+}
+
+// checkImportedFields validates an imported issue's field values. Refs
+// are only checked for shape: the issues they name may be imported later
+// in the same batch.
+func (s *Store) checkImportedFields(iss *Issue) error {
+	for _, name := range SortedFieldNames(iss.Fields) {
+		if def, ok := s.Fields[name]; ok && def.Type == FieldRef {
+			if iss.Fields[name] == "" {
+				return fmt.Errorf("field %s: empty ref", name)
+			}
+			continue
+		}
+		norm, err := s.NormalizeField(name, iss.Fields[name])
+		if err != nil {
+			return err
+		}
+		iss.Fields[name] = norm
+	}
+	if len(iss.Fields) == 0 {
+		iss.Fields = nil
+	}
+	return nil
+}
+
+// matchFields reports whether iss carries every field value in want.
+func matchFields(iss *Issue, want map[string]string) bool {
+	for name, value := range want {
+		if iss.Fields[name] != value {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,129 @@
+package issue_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jallum/beadwork/internal/issue"
+	"github.com/jallum/beadwork/internal/testutil"
+)
+
+func teamFields(t *testing.T) issue.FieldSchema {
+	t.Helper()
+	fs, err := issue.ParseFields(map[string]string{
+		"prefix":         "test",
+		"field.severity": "enum:low, medium ,high",
+		"field.estimate": "int",
+		"field.customer": "string",
+		"field.target":   "date",
+		"field.spec":     "issue-ref",
+	})
+	if err != nil {
+		t.Fatalf("ParseFields: %v", err)
+	}
+	return fs
+}
+
+func TestParseFields(t *testing.T) {
+	fs := teamFields(t)
+	if got := fs.Names(); !reflect.DeepEqual(got, []string{"customer", "estimate", "severity", "spec", "target"}) {
+		t.Errorf("Names = %v", got)
+	}
+	if got := fs["severity"]; got.Type != issue.FieldEnum || !reflect.DeepEqual(got.Options, []string{"low", "medium", "high"}) {
+		t.Errorf("severity = %+v", got)
+	}
+	if got := fs["spec"]; got.Type != issue.FieldRef {
+		t.Errorf("spec = %+v", got)
+	}
+}
+
+func TestParseFieldsRefAlias(t *testing.T) {
+	fs, err := issue.ParseFields(map[string]string{"field.spec": "ref"})
+	if err != nil {
+		t.Fatalf("ParseFields: %v", err)
+	}
+	if got := fs["spec"].Type; got != issue.FieldRef {
+		t.Errorf("ref type = %q, want %q", got, issue.FieldRef)
+	}
+}
+
+func TestParseFieldsErrors(t *testing.T) {
+	for _, cfg := range []map[string]string{
+		{"field.Bad Name": "string"},
+		{"field.size": "float"},
+		{"field.size": "enum:"},
+	} {
+		if _, err := issue.ParseFields(cfg); err == nil {
+			t.Errorf("ParseFields(%v): expected error", cfg)
+		}
+	}
+}
+
+func TestFieldsValidatedByStore(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+	env.Store.Fields = teamFields(t)
+
+	spec, _ := env.Store.Create("Spec", issue.CreateOpts{})
+	iss, err := env.Store.Create("Feature", issue.CreateOpts{Fields: map[string]string{
+		"severity": "high",
+		"estimate": "007",
+		"spec":     spec.ID[len("test-"):],
+	}})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	want := map[string]string{"severity": "high", "estimate": "7", "spec": spec.ID}
+	if !reflect.DeepEqual(iss.Fields, want) {
+		t.Errorf("Fields = %v, want %v", iss.Fields, want)
+	}
+
+	for _, bad := range []map[string]string{
+		{"severity": "urgent"},
+		{"estimate": "lots"},
+		{"target": "next week"},
+		{"spec": "test-zzzz"},
+		{"color": "red"},
+	} {
+		if _, err := env.Store.Update(iss.ID, issue.UpdateOpts{Fields: bad}); err == nil {
+			t.Errorf("Update(%v): expected error", bad)
+		}
+	}
+
+	got, err := env.Store.Update(iss.ID, issue.UpdateOpts{Fields: map[string]string{"spec": "", "target": "2027-03-01"}})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	want = map[string]string{"severity": "high", "estimate": "7", "target": "2027-03-01"}
+	if !reflect.DeepEqual(got.Fields, want) {
+		t.Errorf("Fields after update = %v, want %v", got.Fields, want)
+	}
+}
+
+func TestListFilterByFields(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+	env.Store.Fields = teamFields(t)
+
+	high, _ := env.Store.Create("High", issue.CreateOpts{Fields: map[string]string{"severity": "high", "estimate": "3"}})
+	env.Store.Create("Low", issue.CreateOpts{Fields: map[string]string{"severity": "low", "estimate": "3"}})
+	bare, _ := env.Store.Create("Bare", issue.CreateOpts{})
+
+	issues, err := env.Store.List(issue.Filter{Fields: map[string]string{"severity": "high", "estimate": "03"}})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(issues) != 1 || issues[0].ID != high.ID {
+		t.Errorf("List severity=high = %v, want [%s]", issues, high.ID)
+	}
+
+	issues, _ = env.Store.List(issue.Filter{Fields: map[string]string{"severity": ""}})
+	if len(issues) != 1 || issues[0].ID != bare.ID {
+		t.Errorf("List severity unset = %v, want [%s]", issues, bare.ID)
+	}
+
+	if _, err := env.Store.List(issue.Filter{Fields: map[string]string{"severity": "urgent"}}); err == nil || !strings.Contains(err.Error(), "not one of") {
+		t.Errorf("List with invalid enum: err = %v", err)
+	}
+}
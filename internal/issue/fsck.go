@@ -0,0 +1,347 @@
+package issue
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Problem is one inconsistency found by Check or Repair. Path names the
+// file (or directory) at fault.
+type Problem struct {
+	Path       string `json:"path"`
+	Problem    string `json:"problem"`
+	Repairable bool   `json:"repairable"`
+}
+
+// Check reports every inconsistency between issues/<id>.json and the
+// marker directories without changing anything.
+func (s *Store) Check() ([]Problem, error) {
+	return s.fsck(false)
+}
+
+// Repair reports the same problems as Check and stages a fix for every
+// repairable one. The caller commits the result. Each relationship has
+// one source of truth that the other side is rebuilt from:
+//
+//   - status, labels, parent: the fields in issues/<id>.json
+//   - blocks and relations: the <kind>/<from>/<to> marker files
+//
+// Markers and attachments that name a missing issue are removed, and a
+// parent that names a missing issue is cleared. Issues whose JSON cannot
+// be read are reported but left alone, as are markers that refer to them.
+func (s *Store) Repair() ([]Problem, error) {
+	return s.fsck(true)
+}
+
+type fsckRun struct {
+	s          *Store
+	repair     bool
+	issues     map[string]*Issue
+	unreadable map[string]bool
+	dirty      map[string]bool // issues whose JSON needs rewriting
+	problems   []Problem
+}
+
+func (f *fsckRun) report(path string, repairable bool, format string, args ...any) {
+	f.problems = append(f.problems, Problem{Path: path, Problem: fmt.Sprintf(format, args...), Repairable: repairable})
+}
+
+// missing reports whether id names no issue at all. Unreadable issues
+// exist, so their markers are not touched.
+func (f *fsckRun) missing(id string) bool {
+	return f.issues[id] == nil && !f.unreadable[id]
+}
+
+func (s *Store) fsck(repair bool) ([]Problem, error) {
+	f := &fsckRun{
+		s:          s,
+		repair:     repair,
+		issues:     make(map[string]*Issue),
+		unreadable: make(map[string]bool),
+		dirty:      make(map[string]bool),
+	}
+	f.loadIssues()
+	f.checkStatus()
+	f.checkLabels()
+	f.checkParents()
+	f.checkEdges("blocks", "blocks", "blocked_by", func(iss *Issue) (out, in *[]string) { return &iss.Blocks, &iss.BlockedBy })
+	f.checkEdges(RelatesTo, "relates_to", "", func(iss *Issue) (out, in *[]string) { return relationLists(iss, RelatesTo) })
+	f.checkEdges(Duplicates, "duplicates", "duplicated_by", func(iss *Issue) (out, in *[]string) { return relationLists(iss, Duplicates) })
+	f.checkEdges(Supersedes, "supersedes", "superseded_by", func(iss *Issue) (out, in *[]string) { return relationLists(iss, Supersedes) })
+	f.checkAttachments()
+
+	if repair {
+		ids := make([]string, 0, len(f.dirty))
+		for id := range f.dirty {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			if err := s.writeIssue(f.issues[id]); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	sort.Slice(f.problems, func(i, j int) bool {
+		a, b := f.problems[i], f.problems[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Problem < b.Problem
+	})
+	return f.problems, nil
+}
+
+func (f *fsckRun) loadIssues() {
+	entries, err := f.s.FS.ReadDir("issues")
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		name := e.Name()
+		if name == ".gitkeep" {
+			continue
+		}
+		path := "issues/" + name
+		id, ok := strings.CutSuffix(name, ".json")
+		if !ok || e.IsDir() {
+			f.report(path, false, "not an issue file")
+			continue
+		}
+		data, err := f.s.FS.ReadFile(path)
+		if err != nil {
+			f.unreadable[id] = true
+			f.report(path, false, "unreadable: %v", err)
+			continue
+		}
+		var iss Issue
+		if err := json.Unmarshal(data, &iss); err != nil {
+			f.unreadable[id] = true
+			f.report(path, false, "corrupt JSON: %v", err)
+			continue
+		}
+		if iss.ID != id {
+			f.unreadable[id] = true
+			f.report(path, false, "id %q does not match file name", iss.ID)
+			continue
+		}
+		f.issues[id] = &iss
+	}
+}
+
+// markers lists <dir>/<group>/<name> marker files as group → names.
+func (f *fsckRun) markers(dir string) map[string][]string {
+	out := make(map[string][]string)
+	groups, err := f.s.FS.ReadDir(dir)
+	if err != nil {
+		return out
+	}
+	for _, g := range groups {
+		if !g.IsDir() {
+			continue
+		}
+		children, err := f.s.FS.ReadDir(dir + "/" + g.Name())
+		if err != nil {
+			continue
+		}
+		for _, c := range children {
+			if c.Name() != ".gitkeep" {
+				out[g.Name()] = append(out[g.Name()], c.Name())
+			}
+		}
+	}
+	return out
+}
+
+// removeMarker stages the removal of dir/name when repairing.
+func (f *fsckRun) removeMarker(dir, name string) {
+	if f.repair {
+		f.s.removeMarker(dir, name)
+	}
+}
+
+func (f *fsckRun) checkStatus() {
+	marked := make(map[string]bool)
+	for status, ids := range f.markers("status") {
+		for _, id := range ids {
+			path := "status/" + status + "/" + id
+			switch {
+			case f.missing(id):
+				f.report(path, true, "status marker for missing issue %s", id)
+				f.removeMarker("status/"+status, id)
+			case f.issues[id] != nil && f.issues[id].Status != status:
+				f.report(path, true, "stray status marker: %s is %s", id, f.issues[id].Status)
+				f.removeMarker("status/"+status, id)
+			default:
+				marked[id] = true
+			}
+		}
+	}
+	for id, iss := range f.issues {
+		if iss.Status == "" {
+			f.report("issues/"+id+".json", false, "no status")
+			continue
+		}
+		if !marked[id] {
+			f.report("status/"+iss.Status+"/"+id, true, "missing status marker for %s", id)
+			if f.repair {
+				f.s.setStatus(id, iss.Status)
+			}
+		}
+	}
+}
+
+func (f *fsckRun) checkLabels() {
+	marked := make(map[string]bool)
+	for label, ids := range f.markers("labels") {
+		for _, id := range ids {
+			path := "labels/" + label + "/" + id
+			switch {
+			case f.missing(id):
+				f.report(path, true, "label marker for missing issue %s", id)
+				f.removeMarker("labels/"+label, id)
+			case f.issues[id] != nil && !containsStr(f.issues[id].Labels, label):
+				f.report(path, true, "stray label marker: %s is not labeled %s", id, label)
+				f.removeMarker("labels/"+label, id)
+			default:
+				marked[label+"/"+id] = true
+			}
+		}
+	}
+	for id, iss := range f.issues {
+		for _, label := range iss.Labels {
+			if !marked[label+"/"+id] {
+				f.report("labels/"+label+"/"+id, true, "missing label marker for %s", id)
+				if f.repair {
+					f.s.FS.MkdirAll("labels/" + label)
+					f.s.FS.WriteFile("labels/"+label+"/"+id, []byte{})
+				}
+			}
+		}
+	}
+}
+
+// checkParents clears parent fields that name a missing issue and drops
+// parent/<parent>/<child> markers that disagree with the child's JSON.
+// Current versions do not write parent markers, so none are added.
+func (f *fsckRun) checkParents() {
+	for id, iss := range f.issues {
+		if iss.Parent != "" && f.missing(iss.Parent) {
+			f.report("issues/"+id+".json", true, "parent %s does not exist", iss.Parent)
+			if f.repair {
+				iss.Parent = ""
+				f.dirty[id] = true
+			}
+		}
+	}
+	for parent, children := range f.markers("parent") {
+		for _, child := range children {
+			iss := f.issues[child]
+			switch {
+			case f.missing(child) || f.missing(parent):
+				f.report("parent/"+parent+"/"+child, true, "dangling parent marker")
+				f.removeMarker("parent/"+parent, child)
+			case iss != nil && iss.Parent != parent:
+				f.report("parent/"+parent+"/"+child, true, "stray parent marker: %s has parent %q", child, iss.Parent)
+				f.removeMarker("parent/"+parent, child)
+			}
+		}
+	}
+}
+
+// checkEdges reconciles one link kind. Markers are the source of truth;
+// the outField/inField lists in both issues' JSON are rebuilt from them.
+// A symmetric kind has no inField.
+func (f *fsckRun) checkEdges(kind, outField, inField string, lists func(*Issue) (out, in *[]string)) {
+	out := make(map[string][]string)
+	in := make(map[string][]string)
+	for from, tos := range f.markers(kind) {
+		for _, to := range tos {
+			path := kind + "/" + from + "/" + to
+			switch {
+			case f.missing(from) || f.missing(to):
+				f.report(path, true, "%s marker for missing issue", kind)
+				f.removeMarker(kind+"/"+from, to)
+			case from == to:
+				f.report(path, true, "%s marker links an issue to itself", kind)
+				f.removeMarker(kind+"/"+from, to)
+			default:
+				out[from] = addSorted(out[from], to)
+				in[to] = addSorted(in[to], from)
+			}
+		}
+	}
+
+	for id, iss := range f.issues {
+		o, i := lists(iss)
+		wantOut, wantIn := out[id], in[id]
+		if inField == "" {
+			// Symmetric: one list holds both directions.
+			for _, other := range wantIn {
+				wantOut = addSorted(wantOut, other)
+			}
+			f.reconcile(id, outField, o, wantOut)
+			continue
+		}
+		f.reconcile(id, outField, o, wantOut)
+		f.reconcile(id, inField, i, wantIn)
+	}
+}
+
+// reconcile compares one JSON list with the IDs its markers imply and,
+// when repairing, replaces the list.
+func (f *fsckRun) reconcile(id, field string, have *[]string, want []string) {
+	path := "issues/" + id + ".json"
+	changed := false
+	for _, other := range *have {
+		if !containsStr(want, other) {
+			f.report(path, true, "%s lists %s but no marker records it", field, other)
+			changed = true
+		}
+	}
+	for _, other := range want {
+		if !containsStr(*have, other) {
+			f.report(path, true, "%s is missing %s, which a marker records", field, other)
+			changed = true
+		}
+	}
+	if changed && f.repair {
+		if want == nil {
+			want = []string{}
+		}
+		*have = want
+		f.dirty[id] = true
+	}
+}
+
+// checkAttachments removes attachments whose issue no longer exists.
+func (f *fsckRun) checkAttachments() {
+	entries, err := f.s.FS.ReadDir("attachments")
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		if !e.IsDir() || !f.missing(e.Name()) {
+			continue
+		}
+		dir := "attachments/" + e.Name()
+		f.report(dir, true, "attachments for missing issue %s", e.Name())
+		if f.repair {
+			f.removeTree(dir)
+		}
+	}
+}
+
+func (f *fsckRun) removeTree(dir string) {
+	entries, _ := f.s.FS.ReadDir(dir)
+	for _, e := range entries {
+		if e.IsDir() {
+			f.removeTree(dir + "/" + e.Name())
+		} else {
+			f.s.FS.Remove(dir + "/" + e.Name())
+		}
+	}
+}
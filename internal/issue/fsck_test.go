@@ -0,0 +1,148 @@
+package issue_test
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/jallum/beadwork/internal/issue"
+	"github.com/jallum/beadwork/internal/testutil"
+)
+
+func problemPaths(problems []issue.Problem) []string {
+	var paths []string
+	for _, p := range problems {
+		paths = append(paths, p.Path+": "+p.Problem)
+	}
+	return paths
+}
+
+func TestCheckCleanRepo(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	a, _ := env.Store.Create("A", issue.CreateOpts{})
+	b, _ := env.Store.Create("B", issue.CreateOpts{Parent: a.ID})
+	env.Store.Link(a.ID, b.ID)
+	env.Store.Relate(issue.RelatesTo, a.ID, b.ID)
+	env.Store.Label(b.ID, []string{"ui"}, nil)
+	env.Store.Close(a.ID, "")
+	env.Repo.Commit("setup")
+
+	problems, err := env.Store.Check()
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if len(problems) != 0 {
+		t.Errorf("Check = %v, want none", problemPaths(problems))
+	}
+}
+
+func TestCheckAndRepair(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	a, _ := env.Store.Create("A", issue.CreateOpts{})
+	b, _ := env.Store.Create("B", issue.CreateOpts{})
+	c, _ := env.Store.Create("C", issue.CreateOpts{})
+	env.Store.Label(a.ID, []string{"bug"}, nil)
+	env.Store.Relate(issue.Duplicates, c.ID, a.ID)
+	env.Repo.Commit("setup")
+
+	fs := env.Store.FS
+	fs.WriteFile("status/closed/"+a.ID, []byte{})                  // status marker in two dirs
+	fs.Remove("labels/bug/" + a.ID)                                // label field without marker
+	fs.WriteFile("labels/ui/"+b.ID, []byte{})                      // label marker without field
+	fs.Remove("duplicates/" + c.ID + "/" + a.ID)                   // relation lists without marker
+	fs.WriteFile("blocks/"+b.ID+"/"+c.ID, []byte{})                // marker without blocks lists
+	fs.WriteFile("parent/"+a.ID+"/test-gone", []byte{})            // dangling parent marker
+	fs.WriteFile("attachments/test-gone/notes/a.md", []byte("hi")) // attachments for a deleted issue
+	fs.WriteFile("status/open/test-gone", []byte{})                // status marker for a missing issue
+	fs.WriteFile("issues/test-bad.json", []byte("{oops"))          // corrupt JSON
+	fs.WriteFile("status/open/test-bad", []byte{})                 // left alone: issue exists but is unreadable
+	env.Repo.Commit("damage")
+
+	problems, err := env.Store.Check()
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	got := map[string]bool{}
+	for _, p := range problems {
+		got[p.Path] = true
+	}
+	for _, path := range []string{
+		"status/closed/" + a.ID,
+		"labels/bug/" + a.ID,
+		"labels/ui/" + b.ID,
+		"issues/" + a.ID + ".json",
+		"issues/" + b.ID + ".json",
+		"issues/" + c.ID + ".json",
+		"parent/" + a.ID + "/test-gone",
+		"attachments/test-gone",
+		"status/open/test-gone",
+		"issues/test-bad.json",
+	} {
+		if !got[path] {
+			t.Errorf("Check did not report %s; got %v", path, problemPaths(problems))
+		}
+	}
+	if got["status/open/test-bad"] {
+		t.Error("markers of an unreadable issue must be left alone")
+	}
+	if !sort.SliceIsSorted(problems, func(i, j int) bool { return problems[i].Path < problems[j].Path }) {
+		t.Error("problems should be sorted by path")
+	}
+
+	if _, err := env.Store.Repair(); err != nil {
+		t.Fatalf("Repair: %v", err)
+	}
+	env.Repo.Commit("repair")
+
+	after, _ := env.Store.Check()
+	if len(after) != 1 || after[0].Path != "issues/test-bad.json" || after[0].Repairable {
+		t.Errorf("after repair: %v, want only the corrupt issue", problemPaths(after))
+	}
+
+	if _, err := fs.Stat("status/closed/" + a.ID); err == nil {
+		t.Error("stray status marker not removed")
+	}
+	if !env.MarkerExists("labels/bug/" + a.ID) {
+		t.Error("missing label marker not restored")
+	}
+	if _, err := fs.Stat("attachments/test-gone/notes/a.md"); err == nil {
+		t.Error("orphaned attachment not removed")
+	}
+	if !env.MarkerExists("status/open/test-bad") {
+		t.Error("marker of unreadable issue was removed")
+	}
+	gotB, _ := env.Store.Get(b.ID)
+	gotC, _ := env.Store.Get(c.ID)
+	gotA, _ := env.Store.Get(a.ID)
+	if !reflect.DeepEqual(gotB.Blocks, []string{c.ID}) || !reflect.DeepEqual(gotC.BlockedBy, []string{b.ID}) {
+		t.Errorf("blocks not rebuilt from marker: %v / %v", gotB.Blocks, gotC.BlockedBy)
+	}
+	if len(gotC.Duplicates) != 0 || len(gotA.DuplicatedBy) != 0 {
+		t.Errorf("relation lists not cleared: %v / %v", gotC.Duplicates, gotA.DuplicatedBy)
+	}
+}
+
+func TestRepairClearsMissingParent(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	iss, _ := env.Store.Create("Orphan", issue.CreateOpts{})
+	env.Repo.Commit("setup")
+	env.Store.FS.WriteFile("issues/"+iss.ID+".json", []byte(`{"id":"`+iss.ID+`","status":"open","parent":"test-gone","labels":[],"blocks":[],"blocked_by":[]}`))
+	env.Repo.Commit("damage")
+	env.Store.ClearCache()
+
+	problems, _ := env.Store.Check()
+	if len(problems) != 1 || problems[0].Problem != "parent test-gone does not exist" {
+		t.Fatalf("Check = %v", problemPaths(problems))
+	}
+	env.Store.Repair()
+	got, _ := env.Store.Get(iss.ID)
+	if got.Parent != "" {
+		t.Errorf("Parent = %q, want cleared", got.Parent)
+	}
+}
@@ -0,0 +1,126 @@
+package issue
+
+import "sort"
+
+// Edge kinds in a Graph.
+const (
+	EdgeBlocks = "blocks" // From blocks To
+	EdgeParent = "parent" // From is To's parent
+)
+
+// GraphNode is one issue in a Graph.
+type GraphNode struct {
+	ID       string `json:"id"`
+	Title    string `json:"title"`
+	Status   string `json:"status"`
+	Priority int    `json:"priority"`
+	Type     string `json:"type"`
+}
+
+// GraphEdge links two nodes of a Graph.
+type GraphEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Kind string `json:"kind"`
+}
+
+// Graph is a slice of the issue graph: the blocks and parent links between
+// a set of issues. Nodes are sorted by ID; edges by kind, then endpoints.
+type Graph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+// GraphOpts selects which issues a Graph holds.
+type GraphOpts struct {
+	Root          string // issue to centre on; empty means every issue
+	Depth         int    // with Root, how many links away to go; 0 means no limit
+	IncludeClosed bool   // include done issues (the root always is)
+}
+
+// Graph returns the blocks and parent edges among the issues opts selects.
+// Without a root that is every issue. With one it is the root's
+// neighbourhood: the issues reachable from it by walking down to children
+// and along blocks links in either direction. Parents are not walked up
+// to, so a task's siblings stay out of its graph.
+func (s *Store) Graph(opts GraphOpts) (*Graph, error) {
+	all, err := s.List(Filter{})
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*Issue, len(all))
+	childrenOf := make(map[string][]string)
+	for _, iss := range all {
+		byID[iss.ID] = iss
+		if iss.Parent != "" {
+			childrenOf[iss.Parent] = append(childrenOf[iss.Parent], iss.ID)
+		}
+	}
+	forward, reverse := s.LoadEdges()
+	keep := func(id string) bool {
+		iss, ok := byID[id]
+		return ok && (opts.IncludeClosed || !s.isDone(iss.Status))
+	}
+
+	included := make(map[string]bool)
+	if opts.Root == "" {
+		for id := range byID {
+			if keep(id) {
+				included[id] = true
+			}
+		}
+	} else {
+		root, err := s.resolveID(opts.Root)
+		if err != nil {
+			return nil, err
+		}
+		included[root] = true
+		frontier := []string{root}
+		for depth := 0; len(frontier) > 0 && (opts.Depth <= 0 || depth < opts.Depth); depth++ {
+			var next []string
+			for _, id := range frontier {
+				for _, nbrs := range [][]string{childrenOf[id], forward[id], reverse[id]} {
+					for _, n := range nbrs {
+						if !included[n] && keep(n) {
+							included[n] = true
+							next = append(next, n)
+						}
+					}
+				}
+			}
+			frontier = next
+		}
+	}
+
+	g := &Graph{Nodes: []GraphNode{}, Edges: []GraphEdge{}}
+	for id := range included {
+		iss := byID[id]
+		g.Nodes = append(g.Nodes, GraphNode{
+			ID:       iss.ID,
+			Title:    iss.Title,
+			Status:   iss.Status,
+			Priority: iss.Priority,
+			Type:     iss.Type,
+		})
+		if included[iss.Parent] {
+			g.Edges = append(g.Edges, GraphEdge{From: iss.Parent, To: id, Kind: EdgeParent})
+		}
+		for _, blocked := range forward[id] {
+			if included[blocked] {
+				g.Edges = append(g.Edges, GraphEdge{From: id, To: blocked, Kind: EdgeBlocks})
+			}
+		}
+	}
+	sort.Slice(g.Nodes, func(i, j int) bool { return g.Nodes[i].ID < g.Nodes[j].ID })
+	sort.Slice(g.Edges, func(i, j int) bool {
+		a, b := g.Edges[i], g.Edges[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		return a.To < b.To
+	})
+	return g, nil
+}
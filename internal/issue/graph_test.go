@@ -0,0 +1,105 @@
+package issue_test
+
+import (
+	"testing"
+
+	"github.com/jallum/beadwork/internal/issue"
+	"github.com/jallum/beadwork/internal/testutil"
+)
+
+func edgeSet(g *issue.Graph) map[issue.GraphEdge]bool {
+	set := make(map[issue.GraphEdge]bool)
+	for _, e := range g.Edges {
+		set[e] = true
+	}
+	return set
+}
+
+func nodeIDs(g *issue.Graph) map[string]bool {
+	ids := make(map[string]bool)
+	for _, n := range g.Nodes {
+		ids[n.ID] = true
+	}
+	return ids
+}
+
+func TestGraphAll(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	epic, _ := env.Store.Create("Epic", issue.CreateOpts{Type: "epic"})
+	a, _ := env.Store.Create("A", issue.CreateOpts{Parent: epic.ID})
+	b, _ := env.Store.Create("B", issue.CreateOpts{Parent: epic.ID})
+	done, _ := env.Store.Create("Done", issue.CreateOpts{})
+	env.Store.Link(a.ID, b.ID)
+	env.Store.Link(done.ID, a.ID)
+	env.Store.Close(done.ID, "")
+
+	g, err := env.Store.Graph(issue.GraphOpts{})
+	if err != nil {
+		t.Fatalf("Graph: %v", err)
+	}
+	ids := nodeIDs(g)
+	if len(ids) != 3 || !ids[epic.ID] || !ids[a.ID] || !ids[b.ID] {
+		t.Errorf("nodes = %v, want the three open issues", ids)
+	}
+	want := []issue.GraphEdge{
+		{From: a.ID, To: b.ID, Kind: issue.EdgeBlocks},
+		{From: epic.ID, To: a.ID, Kind: issue.EdgeParent},
+		{From: epic.ID, To: b.ID, Kind: issue.EdgeParent},
+	}
+	if len(g.Edges) != len(want) {
+		t.Fatalf("edges = %+v, want %+v", g.Edges, want)
+	}
+	for i, e := range want {
+		if g.Edges[i] != e {
+			t.Errorf("edges[%d] = %+v, want %+v", i, g.Edges[i], e)
+		}
+	}
+
+	g, _ = env.Store.Graph(issue.GraphOpts{IncludeClosed: true})
+	if !nodeIDs(g)[done.ID] || !edgeSet(g)[issue.GraphEdge{From: done.ID, To: a.ID, Kind: issue.EdgeBlocks}] {
+		t.Errorf("IncludeClosed graph = %+v", g)
+	}
+}
+
+func TestGraphRoot(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	epic, _ := env.Store.Create("Epic", issue.CreateOpts{})
+	child, _ := env.Store.Create("Child", issue.CreateOpts{Parent: epic.ID})
+	sibling, _ := env.Store.Create("Sibling", issue.CreateOpts{Parent: epic.ID})
+	blocker, _ := env.Store.Create("Blocker", issue.CreateOpts{})
+	far, _ := env.Store.Create("Far", issue.CreateOpts{})
+	unrelated, _ := env.Store.Create("Unrelated", issue.CreateOpts{})
+	env.Store.Link(blocker.ID, child.ID)
+	env.Store.Link(far.ID, blocker.ID)
+
+	g, err := env.Store.Graph(issue.GraphOpts{Root: child.ID})
+	if err != nil {
+		t.Fatalf("Graph: %v", err)
+	}
+	ids := nodeIDs(g)
+	if !ids[child.ID] || !ids[blocker.ID] || !ids[far.ID] {
+		t.Errorf("nodes = %v, want child, blocker and far", ids)
+	}
+	if ids[epic.ID] || ids[sibling.ID] || ids[unrelated.ID] {
+		t.Errorf("nodes = %v, should not walk up to the parent or beyond", ids)
+	}
+
+	g, _ = env.Store.Graph(issue.GraphOpts{Root: child.ID, Depth: 1})
+	if ids := nodeIDs(g); len(ids) != 2 || !ids[blocker.ID] {
+		t.Errorf("depth 1 nodes = %v, want child and blocker", ids)
+	}
+
+	g, _ = env.Store.Graph(issue.GraphOpts{Root: epic.ID, Depth: 1})
+	ids = nodeIDs(g)
+	if len(ids) != 3 || !ids[child.ID] || !ids[sibling.ID] {
+		t.Errorf("epic depth 1 nodes = %v, want the epic and its children", ids)
+	}
+
+	if _, err := env.Store.Graph(issue.GraphOpts{Root: "nope"}); err == nil {
+		t.Error("expected error for unknown root")
+	}
+}
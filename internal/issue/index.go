@@ -0,0 +1,209 @@
+package issue
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// indexVersion is bumped whenever the on-disk index layout changes; an
+// index written by another version is discarded and rebuilt.
+const indexVersion = 1
+
+// diskIndex is a local, derived copy of the parts of the beadwork tree
+// that bulk reads need: every issue, the status sets and the blocks
+// edges. It is valid only for the tree it was built from.
+type diskIndex struct {
+	Version int                 `json:"version"`
+	Tree    string              `json:"tree"`
+	Issues  map[string]*Issue   `json:"issues"`
+	Status  map[string][]string `json:"status"`
+	Blocks  map[string][]string `json:"blocks"`
+}
+
+// index returns the query index for the current tree, loading, updating
+// or rebuilding it as needed. It returns nil, so callers read the tree
+// directly, when the index is disabled, the tree has pending changes, or
+// the index cannot be brought up to date.
+func (s *Store) index() *diskIndex {
+	if s.IndexPath == "" || s.FS.Dirty() {
+		return nil
+	}
+	tree := s.FS.TreeHash()
+	if tree.IsZero() {
+		return nil
+	}
+	if s.idx != nil && s.idx.Tree == tree.String() {
+		return s.idx
+	}
+
+	// Always start from the file: the in-memory copy hands out the same
+	// Issue pointers as the read cache, which callers may have mutated.
+	idx := readIndexFile(s.IndexPath)
+	if idx == nil || idx.Tree != tree.String() {
+		if idx == nil || !s.updateIndex(idx, tree) {
+			idx = s.buildIndex(tree)
+		}
+		if idx == nil {
+			return nil
+		}
+		writeIndexFile(s.IndexPath, idx)
+	}
+
+	// Serve later single-issue reads from the index too. Issues already
+	// in the read cache are kept: they are the ones callers hold.
+	if s.cache == nil {
+		s.cache = make(map[string]*Issue, len(idx.Issues))
+	}
+	for id, iss := range idx.Issues {
+		if _, ok := s.cache[id]; !ok {
+			s.cache[id] = iss
+		}
+	}
+	s.idx = idx
+	return idx
+}
+
+// buildIndex reads the whole tree into a fresh index.
+func (s *Store) buildIndex(tree plumbing.Hash) *diskIndex {
+	idx := &diskIndex{
+		Version: indexVersion,
+		Tree:    tree.String(),
+		Issues:  make(map[string]*Issue),
+		Status:  make(map[string][]string),
+		Blocks:  make(map[string][]string),
+	}
+	entries, err := s.FS.ReadDir("issues")
+	if err == nil {
+		for _, e := range entries {
+			if !s.indexPath(idx, "issues/"+e.Name(), true) {
+				return nil
+			}
+		}
+	}
+	for _, dir := range []string{"status", "blocks"} {
+		groups, err := s.FS.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, g := range groups {
+			if !g.IsDir() {
+				continue
+			}
+			children, err := s.FS.ReadDir(dir + "/" + g.Name())
+			if err != nil {
+				continue
+			}
+			for _, c := range children {
+				s.indexPath(idx, dir+"/"+g.Name()+"/"+c.Name(), true)
+			}
+		}
+	}
+	return idx
+}
+
+// updateIndex applies the changes between idx.Tree and tree to idx.
+// Reports false if the diff cannot be computed or a changed issue
+// cannot be read.
+func (s *Store) updateIndex(idx *diskIndex, tree plumbing.Hash) bool {
+	paths, err := s.FS.ChangedPaths(plumbing.NewHash(idx.Tree))
+	if err != nil {
+		return false
+	}
+	for _, p := range paths {
+		_, err := s.FS.Stat(p)
+		if !s.indexPath(idx, p, err == nil) {
+			return false
+		}
+	}
+	idx.Tree = tree.String()
+	return true
+}
+
+// indexPath brings the index entry for one tree path, which exists or
+// was removed, in line with the current tree. Paths the index does not
+// track are ignored.
+func (s *Store) indexPath(idx *diskIndex, p string, exists bool) bool {
+	parts := strings.Split(p, "/")
+	switch {
+	case len(parts) == 2 && parts[0] == "issues" && strings.HasSuffix(parts[1], ".json"):
+		id := strings.TrimSuffix(parts[1], ".json")
+		if !exists {
+			delete(idx.Issues, id)
+			return true
+		}
+		data, err := s.FS.ReadFile(p)
+		if err != nil {
+			return false
+		}
+		var iss Issue
+		if err := json.Unmarshal(data, &iss); err != nil {
+			return false
+		}
+		idx.Issues[id] = &iss
+	case len(parts) == 3 && (parts[0] == "status" || parts[0] == "blocks") && parts[2] != ".gitkeep":
+		set := idx.Status
+		if parts[0] == "blocks" {
+			set = idx.Blocks
+		}
+		if exists {
+			set[parts[1]] = addSorted(set[parts[1]], parts[2])
+		} else if ids := removeStr(set[parts[1]], parts[2]); len(ids) > 0 {
+			set[parts[1]] = ids
+		} else {
+			delete(set, parts[1])
+		}
+	}
+	return true
+}
+
+// readIndexFile loads the index at path, or returns nil if it is
+// missing, corrupt, or from another version.
+func readIndexFile(path string) *diskIndex {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var idx diskIndex
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return nil
+	}
+	if idx.Version != indexVersion || plumbing.NewHash(idx.Tree).IsZero() ||
+		idx.Issues == nil || idx.Status == nil || idx.Blocks == nil {
+		return nil
+	}
+	for id, iss := range idx.Issues {
+		if iss == nil || iss.ID != id {
+			return nil
+		}
+	}
+	return &idx
+}
+
+// writeIndexFile saves idx to path atomically. Failures are ignored: the
+// index is only an accelerator.
+func writeIndexFile(path string, idx *diskIndex) {
+	data, err := json.Marshal(idx)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".index-*")
+	if err != nil {
+		return
+	}
+	_, werr := tmp.Write(data)
+	cerr := tmp.Close()
+	if werr != nil || cerr != nil {
+		os.Remove(tmp.Name())
+		return
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+	}
+}
@@ -0,0 +1,131 @@
+package issue_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/jallum/beadwork/internal/issue"
+	"github.com/jallum/beadwork/internal/testutil"
+)
+
+func listIDs(t *testing.T, s *issue.Store, filter issue.Filter) []string {
+	t.Helper()
+	issues, err := s.List(filter)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	ids := []string{}
+	for _, iss := range issues {
+		ids = append(ids, iss.ID+":"+iss.Status+":"+iss.Title)
+	}
+	return ids
+}
+
+func indexTree(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read index: %v", err)
+	}
+	var idx struct{ Tree string }
+	json.Unmarshal(data, &idx)
+	return idx.Tree
+}
+
+func TestIndexMatchesDirectReads(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+	path := filepath.Join(t.TempDir(), "index.json")
+
+	a, _ := env.Store.Create("Alpha", issue.CreateOpts{})
+	b, _ := env.Store.Create("Beta", issue.CreateOpts{})
+	env.Store.Link(a.ID, b.ID)
+	env.Repo.Commit("setup")
+
+	indexed := issue.NewStore(env.Repo.TreeFS(), "test")
+	indexed.IndexPath = path
+	all := issue.Filter{Statuses: []string{"open", "in_progress", "closed"}}
+
+	if got, want := listIDs(t, indexed, all), listIDs(t, env.Store, all); !reflect.DeepEqual(got, want) {
+		t.Errorf("indexed List = %v, want %v", got, want)
+	}
+	first := indexTree(t, path)
+	if first != env.Repo.TreeFS().TreeHash().String() {
+		t.Errorf("index tree = %s, want current tree", first)
+	}
+
+	// Later commits are folded in from the tree diff.
+	title := "Alpha v2"
+	env.Store.Update(a.ID, issue.UpdateOpts{Title: &title})
+	env.Store.Close(b.ID, "")
+	c, _ := env.Store.Create("Gamma", issue.CreateOpts{})
+	env.Store.Link(c.ID, a.ID)
+	env.Repo.Commit("more")
+
+	indexed = issue.NewStore(env.Repo.TreeFS(), "test")
+	indexed.IndexPath = path
+	env.Store.ClearCache()
+	if got, want := listIDs(t, indexed, all), listIDs(t, env.Store, all); !reflect.DeepEqual(got, want) {
+		t.Errorf("after update: indexed List = %v, want %v", got, want)
+	}
+	if indexTree(t, path) == first {
+		t.Error("index was not advanced to the new tree")
+	}
+	fwd, rev := indexed.LoadEdges()
+	wantFwd, wantRev := env.Store.LoadEdges()
+	if !reflect.DeepEqual(fwd, wantFwd) || !reflect.DeepEqual(rev, wantRev) {
+		t.Errorf("indexed edges = %v / %v, want %v / %v", fwd, rev, wantFwd, wantRev)
+	}
+	ready, _ := indexed.Ready()
+	if len(ready) != 1 || ready[0].ID != c.ID {
+		t.Errorf("Ready = %v, want only %s", ready, c.ID)
+	}
+}
+
+func TestIndexCorruptionFallsBack(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+	path := filepath.Join(t.TempDir(), "index.json")
+
+	env.Store.Create("Alpha", issue.CreateOpts{})
+	env.Repo.Commit("setup")
+
+	for _, junk := range []string{
+		"not json",
+		`{"version":1,"tree":"0000000000000000000000000000000000000000","issues":{},"status":{},"blocks":{}}`,
+		`{"version":1,"tree":"1111111111111111111111111111111111111111","issues":{},"status":{},"blocks":{}}`,
+		`{"version":99}`,
+	} {
+		os.WriteFile(path, []byte(junk), 0644)
+		s := issue.NewStore(env.Repo.TreeFS(), "test")
+		s.IndexPath = path
+		if got := listIDs(t, s, issue.Filter{}); len(got) != 1 {
+			t.Errorf("index %q: List = %v, want the one issue", junk, got)
+		}
+		if indexTree(t, path) != env.Repo.TreeFS().TreeHash().String() {
+			t.Errorf("index %q was not rebuilt", junk)
+		}
+	}
+}
+
+func TestIndexIgnoredWithPendingChanges(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+	path := filepath.Join(t.TempDir(), "index.json")
+
+	env.Store.Create("Alpha", issue.CreateOpts{})
+	env.Repo.Commit("setup")
+
+	s := issue.NewStore(env.Repo.TreeFS(), "test")
+	s.IndexPath = path
+	s.Create("Uncommitted", issue.CreateOpts{})
+	if got := listIDs(t, s, issue.Filter{}); len(got) != 2 {
+		t.Errorf("List = %v, want the pending issue too", got)
+	}
+	if _, err := os.Stat(path); err == nil {
+		t.Error("index should not be built from a dirty tree")
+	}
+}
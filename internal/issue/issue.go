@@ -17,24 +17,32 @@ type Comment struct {
 }
 
 type Issue struct {
-	Assignee    string    `json:"assignee"`
-	BlockedBy   []string  `json:"blocked_by"`
-	Blocks      []string  `json:"blocks"`
-	ClosedAt    string    `json:"closed_at,omitempty"`
-	CloseReason string    `json:"close_reason,omitempty"`
-	Created     string    `json:"created"`
-	DeferUntil  string    `json:"defer_until,omitempty"`
-	Description string    `json:"description"`
-	Due         string    `json:"due,omitempty"`
-	ID          string    `json:"id"`
-	Labels      []string  `json:"labels"`
-	Parent      string    `json:"parent,omitempty"`
-	Comments    []Comment `json:"comments,omitempty"`
-	Priority    int       `json:"priority"`
-	Status      string    `json:"status"`
-	Title       string    `json:"title"`
-	Type        string    `json:"type"`
-	UpdatedAt   string    `json:"updated_at,omitempty"`
+	Assignee     string            `json:"assignee"`
+	BlockedBy    []string          `json:"blocked_by"`
+	Blocks       []string          `json:"blocks"`
+	ClosedAt     string            `json:"closed_at,omitempty"`
+	CloseReason  string            `json:"close_reason,omitempty"`
+	Created      string            `json:"created"`
+	DeferUntil   string            `json:"defer_until,omitempty"`
+	Description  string            `json:"description"`
+	Due          string            `json:"due,omitempty"`
+	DuplicatedBy []string          `json:"duplicated_by,omitempty"`
+	Duplicates   []string          `json:"duplicates,omitempty"`
+	Every        string            `json:"every,omitempty"` // recurrence rule; see Recur
+	Fields       map[string]string `json:"fields,omitempty"`
+	ID           string            `json:"id"`
+	Labels       []string          `json:"labels"`
+	Lease        *Lease            `json:"lease,omitempty"`
+	Parent       string            `json:"parent,omitempty"`
+	Comments     []Comment         `json:"comments,omitempty"`
+	Priority     int               `json:"priority"`
+	RelatesTo    []string          `json:"relates_to,omitempty"`
+	Status       string            `json:"status"`
+	SupersededBy []string          `json:"superseded_by,omitempty"`
+	Supersedes   []string          `json:"supersedes,omitempty"`
+	Title        string            `json:"title"`
+	Type         string            `json:"type"`
+	UpdatedAt    string            `json:"updated_at,omitempty"`
 }
 
 // Committer persists pending TreeFS mutations to the underlying storage.
@@ -42,14 +50,27 @@ type Committer interface {
 	Commit(message string) error
 }
 
+// CommitHooks is consulted by Store.Commit. BeforeCommit sees the staged
+// changes and may veto the commit by returning an error; AfterCommit runs
+// once the commit has landed.
+type CommitHooks interface {
+	BeforeCommit(s *Store, intent string) error
+	AfterCommit(s *Store, intent string)
+}
+
 type Store struct {
 	FS              *treefs.TreeFS
 	Prefix          string
 	Committer       Committer // nil for read-only use
 	DryRun          bool      // when true, Commit logs the intent but skips persistence
 	DefaultPriority *int
-	IDRetries       int       // retries per length before bumping; 0 means 10
-	RandReader      io.Reader // random source; nil means crypto/rand.Reader
+	IDRetries       int         // retries per length before bumping; 0 means 10
+	RandReader      io.Reader   // random source; nil means crypto/rand.Reader
+	Workflow        *Workflow   // statuses and transitions; nil means DefaultWorkflow
+	Fields          FieldSchema // declared custom fields; nil means none
+	User            string      // who "assignee:me" in a query means
+	IndexPath       string      // local query index file; empty disables it
+	Hooks           CommitHooks // run around each Commit; nil runs none
 
 	// SourceHash, when non-zero, designates an additional commit whose
 	// tree may be consulted to resolve attachment blobs during intent
@@ -61,19 +82,62 @@ type Store struct {
 
 	cache map[string]*Issue
 	idSet map[string]bool // lazily populated on first resolveID/ExistingIDs call
+	idx   *diskIndex      // loaded query index; see index.go
+	batch *[]string       // intents collected instead of committed; see StartBatch
 }
 
 // Commit persists pending mutations with the given intent message.
 // When DryRun is true, the intent is logged to stderr and no commit is made.
+// Inside a batch the intent is only collected; see StartBatch.
 func (s *Store) Commit(intent string) error {
 	if s.Committer == nil {
 		return fmt.Errorf("store is read-only: no committer configured")
 	}
+	if s.batch != nil {
+		*s.batch = append(*s.batch, intent)
+		return nil
+	}
 	if s.DryRun {
 		fmt.Fprintf(os.Stderr, "[dry-run] would commit: %s\n", intent)
 		return nil
 	}
-	return s.Committer.Commit(intent)
+	if s.Hooks != nil {
+		if err := s.Hooks.BeforeCommit(s, intent); err != nil {
+			// Drop the vetoed mutation so the store matches the branch.
+			s.Refresh()
+			return err
+		}
+	}
+	if err := s.Committer.Commit(intent); err != nil {
+		return err
+	}
+	if s.Hooks != nil {
+		s.Hooks.AfterCommit(s, intent)
+	}
+	return nil
+}
+
+// StartBatch makes Commit collect intents instead of committing them, so
+// several operations can land as one commit. Mutations stay staged in the
+// TreeFS until the caller commits the intents returned by EndBatch.
+func (s *Store) StartBatch() {
+	s.batch = new([]string)
+}
+
+// Batched returns the intents collected since StartBatch.
+func (s *Store) Batched() []string {
+	if s.batch == nil {
+		return nil
+	}
+	return *s.batch
+}
+
+// EndBatch stops collecting and returns the intents collected since
+// StartBatch.
+func (s *Store) EndBatch() []string {
+	intents := s.Batched()
+	s.batch = nil
+	return intents
 }
 
 // ClearCache discards all cached issues and the lazy ID set.
@@ -82,6 +146,7 @@ func (s *Store) Commit(intent string) error {
 func (s *Store) ClearCache() {
 	s.cache = nil
 	s.idSet = nil
+	s.idx = nil
 }
 
 // Refresh reloads the underlying TreeFS from the current ref and clears
@@ -143,6 +208,8 @@ type CreateOpts struct {
 	Assignee    string
 	DeferUntil  string
 	Due         string
+	Every       string            // recurrence rule, stored as given
+	Fields      map[string]string // custom field values, validated against Store.Fields
 }
 
 type UpdateOpts struct {
@@ -155,6 +222,8 @@ type UpdateOpts struct {
 	Status      *string
 	DeferUntil  *string
 	Due         *string
+	Every       *string           // empty stops the issue recurring
+	Fields      map[string]string // set each field; an empty value unsets it
 }
 
 type Filter struct {
@@ -168,4 +237,6 @@ type Filter struct {
 	Parent                 string
 	Overdue                bool
 	IncludeExpiredDeferred bool
+	Fields                 map[string]string // custom field equality matches
+	Query                  *Query            // parsed --query expression
 }
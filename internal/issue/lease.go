@@ -0,0 +1,183 @@
+package issue
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Lease is a time-limited claim on an in_progress issue. The session that
+// started the issue keeps the lease alive with Heartbeat; once it expires,
+// Ready offers the issue again and Claim may take it over.
+type Lease struct {
+	Agent    string `json:"agent,omitempty"` // coding agent holding the claim, if detected
+	Duration string `json:"duration"`        // how far a heartbeat extends Expires
+	Expires  string `json:"expires"`         // RFC3339
+}
+
+// Expired reports whether the lease has run out at now. A lease whose
+// expiry cannot be parsed counts as expired.
+func (l *Lease) Expired(now time.Time) bool {
+	t, err := time.Parse(time.RFC3339, l.Expires)
+	return err != nil || !now.Before(t)
+}
+
+// IsReclaimable reports whether iss is in_progress under a lease that
+// has expired, so another session may start it.
+func IsReclaimable(iss *Issue, now time.Time) bool {
+	return iss.Status == "in_progress" && iss.Lease != nil && iss.Lease.Expired(now)
+}
+
+// FormatLeaseDuration renders d the way users write it: 2h rather than
+// 2h0m0s.
+func FormatLeaseDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+// ClaimedError is returned by Claim when the issue is held under a live
+// lease and Steal was not requested.
+type ClaimedError struct {
+	ID       string
+	Assignee string
+	Agent    string
+	Expires  string
+}
+
+func (e *ClaimedError) Error() string {
+	holder := e.Assignee
+	if e.Agent != "" {
+		holder += " (" + e.Agent + ")"
+	}
+	return fmt.Sprintf("%s is claimed by %s until %s", e.ID, holder, e.Expires)
+}
+
+type ClaimOpts struct {
+	Assignee string
+	Lease    time.Duration // zero starts without a lease
+	Expires  time.Time     // lease expiry; zero means Now()+Lease
+	Agent    string
+	Steal    bool // take over an in_progress issue even under a live lease
+}
+
+// Claim transitions an issue to in_progress and records who holds it.
+// An open issue is always claimable; an in_progress one only when its
+// lease has expired or opts.Steal is set. Returns a BlockedError if the
+// issue has unresolved blockers and a ClaimedError if another session
+// holds a live lease.
+func (s *Store) Claim(id string, opts ClaimOpts) (*Issue, error) {
+	id, err := s.resolveID(id)
+	if err != nil {
+		return nil, err
+	}
+	iss, err := s.readIssue(id)
+	if err != nil {
+		return nil, err
+	}
+	now := s.Now()
+	switch {
+	case iss.Status == "open":
+		if err := s.CurrentWorkflow().CheckTransition("open", "in_progress"); err != nil {
+			return nil, fmt.Errorf("%s: %w", id, err)
+		}
+	case iss.Status == "in_progress" && (opts.Steal || IsReclaimable(iss, now)):
+		// Taking over an abandoned or stolen claim.
+	case iss.Status == "in_progress" && iss.Lease != nil:
+		return nil, &ClaimedError{ID: id, Assignee: iss.Assignee, Agent: iss.Lease.Agent, Expires: iss.Lease.Expires}
+	default:
+		return nil, fmt.Errorf("%s is %s, not open", id, iss.Status)
+	}
+
+	// Check for open blockers
+	if len(iss.BlockedBy) > 0 {
+		var open []string
+		for _, blockerID := range iss.BlockedBy {
+			blocker, err := s.readIssue(blockerID)
+			if err != nil {
+				open = append(open, blockerID)
+				continue
+			}
+			if !s.isDone(blocker.Status) {
+				open = append(open, blockerID)
+			}
+		}
+		if len(open) > 0 {
+			return nil, &BlockedError{ID: id, Blockers: open}
+		}
+	}
+
+	if iss.Status == "open" {
+		if err := s.moveStatus(id, "open", "in_progress"); err != nil {
+			return nil, err
+		}
+	}
+	iss.Status = "in_progress"
+	iss.Assignee = opts.Assignee
+	iss.Lease = nil
+	if opts.Lease > 0 {
+		expires := opts.Expires
+		if expires.IsZero() {
+			expires = now.Add(opts.Lease)
+		}
+		iss.Lease = &Lease{
+			Agent:    opts.Agent,
+			Duration: FormatLeaseDuration(opts.Lease),
+			Expires:  expires.UTC().Format(time.RFC3339),
+		}
+	}
+	iss.UpdatedAt = now.Format(time.RFC3339)
+	if err := s.writeIssue(iss); err != nil {
+		return nil, err
+	}
+	return iss, nil
+}
+
+type HeartbeatOpts struct {
+	Assignee string        // must match the holder; empty skips the check
+	Lease    time.Duration // new lease length; zero keeps the current one
+	Expires  time.Time     // new expiry; zero means Now()+Lease
+}
+
+// Heartbeat extends the lease on an in_progress issue. A lease that has
+// expired but not been reclaimed can still be renewed by its holder.
+func (s *Store) Heartbeat(id string, opts HeartbeatOpts) (*Issue, error) {
+	id, err := s.resolveID(id)
+	if err != nil {
+		return nil, err
+	}
+	iss, err := s.readIssue(id)
+	if err != nil {
+		return nil, err
+	}
+	if iss.Status != "in_progress" || iss.Lease == nil {
+		return nil, fmt.Errorf("%s has no lease to extend", id)
+	}
+	if opts.Assignee != "" && opts.Assignee != iss.Assignee {
+		return nil, fmt.Errorf("%s is claimed by %s, not %s", id, iss.Assignee, opts.Assignee)
+	}
+	lease := opts.Lease
+	if lease == 0 {
+		lease, err = time.ParseDuration(iss.Lease.Duration)
+		if err != nil || lease <= 0 {
+			return nil, fmt.Errorf("%s: invalid lease duration %q", id, iss.Lease.Duration)
+		}
+	}
+	now := s.Now()
+	expires := opts.Expires
+	if expires.IsZero() {
+		expires = now.Add(lease)
+	}
+	iss.Lease.Duration = FormatLeaseDuration(lease)
+	iss.Lease.Expires = expires.UTC().Format(time.RFC3339)
+	iss.UpdatedAt = now.Format(time.RFC3339)
+	if err := s.writeIssue(iss); err != nil {
+		return nil, err
+	}
+	return iss, nil
+}
@@ -0,0 +1,149 @@
+package issue_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jallum/beadwork/internal/issue"
+	"github.com/jallum/beadwork/internal/testutil"
+)
+
+func containsIssue(issues []*issue.Issue, id string) bool {
+	for _, iss := range issues {
+		if iss.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func TestClaimWithLease(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+	t.Setenv("BW_CLOCK", "2027-03-01T10:00:00Z")
+
+	iss, _ := env.Store.Create("Leased", issue.CreateOpts{})
+	got, err := env.Store.Claim(iss.ID, issue.ClaimOpts{Assignee: "bob", Lease: 2 * time.Hour, Agent: "claude-code"})
+	if err != nil {
+		t.Fatalf("Claim: %v", err)
+	}
+	want := issue.Lease{Agent: "claude-code", Duration: "2h", Expires: "2027-03-01T12:00:00Z"}
+	if got.Status != "in_progress" || got.Assignee != "bob" || got.Lease == nil || *got.Lease != want {
+		t.Fatalf("after claim: status=%s assignee=%s lease=%+v", got.Status, got.Assignee, got.Lease)
+	}
+	env.Repo.Commit("start " + iss.ID)
+
+	// A live lease is refused without Steal.
+	_, err = env.Store.Claim(iss.ID, issue.ClaimOpts{Assignee: "carol"})
+	var ce *issue.ClaimedError
+	if !errors.As(err, &ce) || ce.Assignee != "bob" || ce.Agent != "claude-code" {
+		t.Fatalf("Claim over live lease: err = %v, want ClaimedError", err)
+	}
+	if ready, _ := env.Store.Ready(); containsIssue(ready, iss.ID) {
+		t.Error("issue under a live lease is ready")
+	}
+
+	// Once expired, Ready offers it and Claim takes it over.
+	t.Setenv("BW_CLOCK", "2027-03-01T12:00:00Z")
+	ready, _ := env.Store.Ready()
+	if !containsIssue(ready, iss.ID) {
+		t.Error("issue with expired lease is not ready")
+	}
+	got, err = env.Store.Claim(iss.ID, issue.ClaimOpts{Assignee: "carol", Lease: 30 * time.Minute})
+	if err != nil {
+		t.Fatalf("reclaim: %v", err)
+	}
+	if got.Assignee != "carol" || got.Lease.Agent != "" || got.Lease.Expires != "2027-03-01T12:30:00Z" {
+		t.Errorf("after reclaim: assignee=%s lease=%+v", got.Assignee, got.Lease)
+	}
+	if !env.MarkerExists("status/in_progress/" + iss.ID) {
+		t.Error("in_progress marker missing after reclaim")
+	}
+}
+
+func TestClaimSteal(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	iss, _ := env.Store.Create("Held", issue.CreateOpts{})
+	env.Store.Claim(iss.ID, issue.ClaimOpts{Assignee: "bob", Lease: time.Hour})
+
+	got, err := env.Store.Claim(iss.ID, issue.ClaimOpts{Assignee: "carol", Steal: true})
+	if err != nil {
+		t.Fatalf("steal: %v", err)
+	}
+	if got.Assignee != "carol" || got.Lease != nil {
+		t.Errorf("after steal: assignee=%s lease=%+v", got.Assignee, got.Lease)
+	}
+
+	// Without a lease there is nothing to reclaim.
+	if _, err := env.Store.Claim(iss.ID, issue.ClaimOpts{Assignee: "dave"}); err == nil {
+		t.Error("expected error starting an in_progress issue without a lease")
+	}
+}
+
+func TestHeartbeat(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+	t.Setenv("BW_CLOCK", "2027-03-01T10:00:00Z")
+
+	iss, _ := env.Store.Create("Leased", issue.CreateOpts{})
+	env.Store.Claim(iss.ID, issue.ClaimOpts{Assignee: "bob", Lease: time.Hour})
+
+	t.Setenv("BW_CLOCK", "2027-03-01T10:45:00Z")
+	got, err := env.Store.Heartbeat(iss.ID, issue.HeartbeatOpts{Assignee: "bob"})
+	if err != nil {
+		t.Fatalf("Heartbeat: %v", err)
+	}
+	if got.Lease.Expires != "2027-03-01T11:45:00Z" || got.Lease.Duration != "1h" {
+		t.Errorf("lease = %+v", got.Lease)
+	}
+
+	got, _ = env.Store.Heartbeat(iss.ID, issue.HeartbeatOpts{Lease: 90 * time.Minute})
+	if got.Lease.Expires != "2027-03-01T12:15:00Z" || got.Lease.Duration != "1h30m" {
+		t.Errorf("lease after --lease = %+v", got.Lease)
+	}
+
+	if _, err := env.Store.Heartbeat(iss.ID, issue.HeartbeatOpts{Assignee: "carol"}); err == nil {
+		t.Error("expected error renewing someone else's lease")
+	}
+
+	other, _ := env.Store.Create("No lease", issue.CreateOpts{})
+	env.Store.Start(other.ID, "bob")
+	if _, err := env.Store.Heartbeat(other.ID, issue.HeartbeatOpts{}); err == nil {
+		t.Error("expected error renewing an issue without a lease")
+	}
+}
+
+func TestStatusChangeDropsLease(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	a, _ := env.Store.Create("Close me", issue.CreateOpts{})
+	b, _ := env.Store.Create("Reopen me", issue.CreateOpts{})
+	for _, id := range []string{a.ID, b.ID} {
+		env.Store.Claim(id, issue.ClaimOpts{Assignee: "bob", Lease: time.Hour})
+	}
+
+	if got, _ := env.Store.Close(a.ID, ""); got.Lease != nil {
+		t.Errorf("Close kept lease %+v", got.Lease)
+	}
+	if got, _ := env.Store.Reopen(b.ID); got.Lease != nil {
+		t.Errorf("Reopen kept lease %+v", got.Lease)
+	}
+}
+
+func TestFormatLeaseDuration(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		2 * time.Hour:           "2h",
+		90 * time.Minute:        "1h30m",
+		30 * time.Minute:        "30m",
+		45 * time.Second:        "45s",
+		time.Hour + time.Second: "1h0m1s",
+	} {
+		if got := issue.FormatLeaseDuration(d); got != want {
+			t.Errorf("FormatLeaseDuration(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
@@ -96,6 +96,9 @@ func (s *Store) StatusCount(status string) int {
 
 // IDsWithStatus returns issue IDs from a status index directory.
 func (s *Store) IDsWithStatus(status string) []string {
+	if idx := s.index(); idx != nil {
+		return append([]string(nil), idx.Status[status]...)
+	}
 	entries, err := s.FS.ReadDir("status/" + status)
 	if err != nil {
 		return nil
@@ -109,14 +112,19 @@ func (s *Store) IDsWithStatus(status string) []string {
 	return ids
 }
 
-// IsClosed checks whether a single issue ID appears in the closed index.
+// IsClosed checks whether a single issue ID appears in the index of a
+// done-category status (closed, or a custom done status).
 func (s *Store) IsClosed(id string) bool {
-	_, err := s.FS.Stat("status/closed/" + id)
-	return err == nil
+	for _, status := range s.CurrentWorkflow().NamesIn(CategoryDone) {
+		if _, err := s.FS.Stat("status/" + status + "/" + id); err == nil {
+			return true
+		}
+	}
+	return false
 }
 
 func (s *Store) List(filter Filter) ([]*Issue, error) {
-	statuses := StatusNames()
+	statuses := s.CurrentWorkflow().Names()
 	if len(filter.Statuses) > 0 {
 		statuses = filter.Statuses
 	} else if filter.Status != "" {
@@ -127,18 +135,10 @@ func (s *Store) List(filter Filter) ([]*Issue, error) {
 	seen := make(map[string]bool)
 	var ids []string
 	for _, status := range statuses {
-		dir := "status/" + status
-		entries, err := s.FS.ReadDir(dir)
-		if err != nil {
-			continue
-		}
-		for _, e := range entries {
-			if e.Name() == ".gitkeep" {
-				continue
-			}
-			if !seen[e.Name()] {
-				ids = append(ids, e.Name())
-				seen[e.Name()] = true
+		for _, id := range s.IDsWithStatus(status) {
+			if !seen[id] {
+				ids = append(ids, id)
+				seen[id] = true
 			}
 		}
 	}
@@ -147,18 +147,30 @@ func (s *Store) List(filter Filter) ([]*Issue, error) {
 	// (they'll be filtered to expired-only below).
 	var deferredIDs []string
 	if filter.IncludeExpiredDeferred {
-		entries, err := s.FS.ReadDir("status/deferred")
-		if err == nil {
-			for _, e := range entries {
-				if e.Name() != ".gitkeep" && !seen[e.Name()] {
-					deferredIDs = append(deferredIDs, e.Name())
-					seen[e.Name()] = true
-				}
+		for _, id := range s.IDsWithStatus("deferred") {
+			if !seen[id] {
+				deferredIDs = append(deferredIDs, id)
+				seen[id] = true
 			}
 		}
 	}
 
+	// An empty filter value matches issues without the field.
+	wantFields := make(map[string]string, len(filter.Fields))
+	for name, value := range filter.Fields {
+		if value == "" {
+			wantFields[name] = ""
+			continue
+		}
+		norm, err := s.NormalizeField(name, value)
+		if err != nil {
+			return nil, err
+		}
+		wantFields[name] = norm
+	}
+
 	now := s.Now()
+	env := s.QueryEnv()
 
 	var issues []*Issue
 	for _, id := range ids {
@@ -182,6 +194,12 @@ func (s *Store) List(filter Filter) ([]*Issue, error) {
 		if filter.Parent != "" && issue.Parent != filter.Parent {
 			continue
 		}
+		if !matchFields(issue, wantFields) {
+			continue
+		}
+		if filter.Query != nil && !filter.Query.Match(issue, env) {
+			continue
+		}
 		if filter.Grep != "" {
 			needle := strings.ToLower(filter.Grep)
 			if !strings.Contains(strings.ToLower(issue.Title), needle) &&
@@ -216,6 +234,12 @@ func (s *Store) List(filter Filter) ([]*Issue, error) {
 		if filter.Parent != "" && iss.Parent != filter.Parent {
 			continue
 		}
+		if !matchFields(iss, wantFields) {
+			continue
+		}
+		if filter.Query != nil && !filter.Query.Match(iss, env) {
+			continue
+		}
 		if filter.Grep != "" {
 			needle := strings.ToLower(filter.Grep)
 			if !strings.Contains(strings.ToLower(iss.Title), needle) &&
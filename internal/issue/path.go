@@ -0,0 +1,241 @@
+package issue
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PathOpts configures CriticalPath.
+type PathOpts struct {
+	Estimate string // int field weighting each issue; empty counts every issue as 1
+}
+
+// PathStep is one issue on a critical path.
+type PathStep struct {
+	*Issue
+	Weight int `json:"weight"` // the issue's estimate, or 1
+	Finish int `json:"finish"` // path length up to and including this issue
+}
+
+// DueConflict records an issue due before one of the issues it waits on.
+type DueConflict struct {
+	ID         string `json:"id"`
+	Due        string `json:"due"`
+	Blocker    string `json:"blocker"`
+	BlockerDue string `json:"blocker_due"`
+}
+
+// CriticalPath is the longest chain of open work standing between an issue
+// and being able to close it.
+type CriticalPath struct {
+	Target    string        `json:"target"`
+	Estimate  string        `json:"estimate,omitempty"`
+	Length    int           `json:"length"`
+	Open      int           `json:"open"` // open issues the target waits on, itself included
+	Path      []PathStep    `json:"path"` // first to do first; ends at the target
+	Conflicts []DueConflict `json:"due_conflicts"`
+	Next      *Issue        `json:"next,omitempty"` // the ready issue that most shortens the path
+	NextSaves int           `json:"next_saves"`     // how much closing Next shortens it
+}
+
+// CriticalPath finds the longest chain of open prerequisites ending at id.
+// An issue's prerequisites are its open blockers and its open children: the
+// same work buildSubtreeOverlay folds into a subtree root. Each issue weighs
+// its opts.Estimate value, or 1 without one.
+//
+// Alongside the path it reports every issue due before a prerequisite is,
+// and the ready issue (a tip of the prerequisite graph that could be picked
+// up now) whose closing shortens the path most.
+func (s *Store) CriticalPath(id string, opts PathOpts) (*CriticalPath, error) {
+	id, err := s.resolveID(id)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Estimate != "" {
+		def, ok := s.Fields[opts.Estimate]
+		if !ok {
+			return nil, fmt.Errorf("unknown field %q", opts.Estimate)
+		}
+		if def.Type != FieldInt {
+			return nil, fmt.Errorf("field %s is not an int field", opts.Estimate)
+		}
+	}
+
+	open := make(map[string]*Issue)
+	children := make(map[string][]string)
+	for _, status := range s.unresolvedStatuses() {
+		for _, oid := range s.IDsWithStatus(status) {
+			iss, err := s.readIssue(oid)
+			if err != nil {
+				continue
+			}
+			open[oid] = iss
+			if iss.Parent != "" {
+				children[iss.Parent] = append(children[iss.Parent], oid)
+			}
+		}
+	}
+	target := open[id]
+	if target == nil {
+		return nil, fmt.Errorf("%s is closed", id)
+	}
+
+	// Gather the prerequisite graph reachable from the target.
+	_, reverse := s.LoadEdges()
+	prereqs := make(map[string][]string)
+	var gather func(id string)
+	gather = func(id string) {
+		if _, seen := prereqs[id]; seen {
+			return
+		}
+		var ps []string
+		for _, p := range append(append([]string(nil), children[id]...), reverse[id]...) {
+			if open[p] != nil && !slices.Contains(ps, p) {
+				ps = append(ps, p)
+			}
+		}
+		sort.Strings(ps)
+		prereqs[id] = ps
+		for _, p := range ps {
+			gather(p)
+		}
+	}
+	gather(id)
+
+	weight := func(id string) int {
+		if opts.Estimate == "" {
+			return 1
+		}
+		n, err := strconv.Atoi(open[id].Fields[opts.Estimate])
+		if err != nil {
+			return 1
+		}
+		return n
+	}
+
+	// longest returns each issue's finish: its weight plus the longest
+	// finish among its prerequisites, with done counting as weight 0. A
+	// prerequisite already on the walk's stack closes a cycle and is
+	// skipped.
+	longest := func(done string) (map[string]int, map[string]string) {
+		finish := make(map[string]int)
+		via := make(map[string]string)
+		onStack := make(map[string]bool)
+		var visit func(id string) int
+		visit = func(id string) int {
+			if f, ok := finish[id]; ok {
+				return f
+			}
+			onStack[id] = true
+			best := 0
+			for _, p := range prereqs[id] {
+				if onStack[p] {
+					continue
+				}
+				if f := visit(p); f > best || (f == best && via[id] != "" && open[p].Priority < open[via[id]].Priority) {
+					best, via[id] = f, p
+				}
+			}
+			onStack[id] = false
+			w := weight(id)
+			if id == done {
+				w = 0
+			}
+			finish[id] = best + w
+			return finish[id]
+		}
+		visit(id)
+		return finish, via
+	}
+
+	finish, via := longest("")
+	cp := &CriticalPath{
+		Target:    id,
+		Estimate:  opts.Estimate,
+		Length:    finish[id],
+		Open:      len(prereqs),
+		Conflicts: []DueConflict{},
+	}
+	for step := id; step != ""; step = via[step] {
+		cp.Path = append(cp.Path, PathStep{Issue: open[step], Weight: weight(step), Finish: finish[step]})
+	}
+	for i, j := 0, len(cp.Path)-1; i < j; i, j = i+1, j-1 {
+		cp.Path[i], cp.Path[j] = cp.Path[j], cp.Path[i]
+	}
+
+	ids := make([]string, 0, len(prereqs))
+	for pid := range prereqs {
+		ids = append(ids, pid)
+	}
+	sort.Strings(ids)
+	for _, pid := range ids {
+		due, ok := dueInstant(open[pid].Due)
+		if !ok {
+			continue
+		}
+		for _, p := range prereqs[pid] {
+			if pdue, ok := dueInstant(open[p].Due); ok && pdue.After(due) {
+				cp.Conflicts = append(cp.Conflicts, DueConflict{ID: pid, Due: open[pid].Due, Blocker: p, BlockerDue: open[p].Due})
+			}
+		}
+	}
+
+	tips, err := s.Tips([]string{id}, prereqs)
+	if err != nil {
+		return nil, err
+	}
+	now := s.Now()
+	sortIssues(tips, now)
+	onPath := make(map[string]bool, len(cp.Path))
+	for _, step := range cp.Path {
+		onPath[step.ID] = true
+	}
+	// With parallel chains of equal length no single issue shortens the
+	// path; prefer one on the reported path all the same.
+	for _, tip := range tips {
+		if !isReadyNow(tip, now) {
+			continue
+		}
+		f, _ := longest(tip.ID)
+		saves := cp.Length - f[id]
+		if cp.Next == nil || saves > cp.NextSaves || (saves == cp.NextSaves && onPath[tip.ID] && !onPath[cp.Next.ID]) {
+			cp.Next, cp.NextSaves = tip, saves
+		}
+	}
+	return cp, nil
+}
+
+// isReadyNow reports whether an unblocked issue could be picked up now, by
+// the same rules as Ready.
+func isReadyNow(iss *Issue, now time.Time) bool {
+	switch iss.Status {
+	case "open":
+		return true
+	case "deferred":
+		return IsDeferralExpired(iss.DeferUntil, now)
+	case "in_progress":
+		return IsReclaimable(iss, now)
+	}
+	return false
+}
+
+// dueInstant returns when a due date falls: an RFC3339 value as given, a
+// date at the end of that day, matching IsOverdue.
+func dueInstant(due string) (time.Time, bool) {
+	if due == "" {
+		return time.Time{}, false
+	}
+	if strings.Contains(due, "T") {
+		t, err := time.Parse(time.RFC3339, due)
+		return t, err == nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", due, time.Local)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t.AddDate(0, 0, 1).Add(-time.Nanosecond), true
+}
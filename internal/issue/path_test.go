@@ -0,0 +1,111 @@
+package issue_test
+
+import (
+	"testing"
+
+	"github.com/jallum/beadwork/internal/issue"
+	"github.com/jallum/beadwork/internal/testutil"
+)
+
+// pathFixture builds an epic with two children, A and B, where A waits on
+// the chain D → C:
+//
+//	D → C → A ─┐
+//	           ├─ epic
+//	        B ─┘
+func pathFixture(t *testing.T, env *testutil.Env) (epic, a, b, c, d *issue.Issue) {
+	t.Helper()
+	env.Store.Fields = teamFields(t)
+	epic, _ = env.Store.Create("Ship", issue.CreateOpts{Due: "2027-03-01"})
+	a, _ = env.Store.Create("A", issue.CreateOpts{Parent: epic.ID, Due: "2027-02-01"})
+	b, _ = env.Store.Create("B", issue.CreateOpts{Parent: epic.ID, Fields: map[string]string{"estimate": "5"}})
+	c, _ = env.Store.Create("C", issue.CreateOpts{Due: "2027-02-15"})
+	d, _ = env.Store.Create("D", issue.CreateOpts{})
+	done, _ := env.Store.Create("Done", issue.CreateOpts{})
+	env.Store.Create("Unrelated", issue.CreateOpts{})
+	env.Store.Link(c.ID, a.ID)
+	env.Store.Link(d.ID, c.ID)
+	env.Store.Link(done.ID, epic.ID)
+	env.Store.Close(done.ID, "")
+	return
+}
+
+func pathIDs(cp *issue.CriticalPath) []string {
+	var ids []string
+	for _, s := range cp.Path {
+		ids = append(ids, s.ID)
+	}
+	return ids
+}
+
+func TestCriticalPath(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+	epic, a, _, c, d := pathFixture(t, env)
+
+	cp, err := env.Store.CriticalPath(epic.ID, issue.PathOpts{})
+	if err != nil {
+		t.Fatalf("CriticalPath: %v", err)
+	}
+	want := []string{d.ID, c.ID, a.ID, epic.ID}
+	if got := pathIDs(cp); len(got) != len(want) || got[0] != want[0] || got[3] != want[3] {
+		t.Errorf("path = %v, want %v", got, want)
+	}
+	if cp.Length != 4 || cp.Open != 5 {
+		t.Errorf("length = %d, open = %d; want 4, 5", cp.Length, cp.Open)
+	}
+	if cp.Path[1].Finish != 2 {
+		t.Errorf("finish of C = %d, want 2", cp.Path[1].Finish)
+	}
+	if cp.Next == nil || cp.Next.ID != d.ID || cp.NextSaves != 1 {
+		t.Errorf("next = %v saves %d, want %s saves 1", cp.Next, cp.NextSaves, d.ID)
+	}
+	if len(cp.Conflicts) != 1 || cp.Conflicts[0] != (issue.DueConflict{ID: a.ID, Due: "2027-02-01", Blocker: c.ID, BlockerDue: "2027-02-15"}) {
+		t.Errorf("conflicts = %+v", cp.Conflicts)
+	}
+}
+
+func TestCriticalPathEstimate(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+	epic, _, b, _, _ := pathFixture(t, env)
+
+	cp, err := env.Store.CriticalPath(epic.ID, issue.PathOpts{Estimate: "estimate"})
+	if err != nil {
+		t.Fatalf("CriticalPath: %v", err)
+	}
+	if got := pathIDs(cp); len(got) != 2 || got[0] != b.ID {
+		t.Errorf("path = %v, want [%s %s]", got, b.ID, epic.ID)
+	}
+	if cp.Length != 6 {
+		t.Errorf("length = %d, want 6", cp.Length)
+	}
+	if cp.Next == nil || cp.Next.ID != b.ID || cp.NextSaves != 2 {
+		t.Errorf("next = %v saves %d, want %s saves 2", cp.Next, cp.NextSaves, b.ID)
+	}
+
+	for _, field := range []string{"nope", "severity"} {
+		if _, err := env.Store.CriticalPath(epic.ID, issue.PathOpts{Estimate: field}); err == nil {
+			t.Errorf("estimate %q: expected error", field)
+		}
+	}
+}
+
+func TestCriticalPathReadyTarget(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	iss, _ := env.Store.Create("Alone", issue.CreateOpts{})
+	cp, err := env.Store.CriticalPath(iss.ID, issue.PathOpts{})
+	if err != nil {
+		t.Fatalf("CriticalPath: %v", err)
+	}
+	if cp.Length != 1 || cp.Next == nil || cp.Next.ID != iss.ID {
+		t.Errorf("cp = %+v", cp)
+	}
+
+	env.Store.Close(iss.ID, "")
+	if _, err := env.Store.CriticalPath(iss.ID, issue.PathOpts{}); err == nil {
+		t.Error("expected error for a closed issue")
+	}
+}
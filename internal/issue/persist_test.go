@@ -97,3 +97,32 @@ func TestDeleteEvictsFromCache(t *testing.T) {
 		t.Error("Get after Delete should return error, not stale cached value")
 	}
 }
+
+func TestStartBatchCollectsCommits(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+	before, _ := env.Repo.AllCommits()
+
+	env.Store.StartBatch()
+	a, _ := env.Store.Create("A", issue.CreateOpts{})
+	if err := env.Store.Commit("create " + a.ID); err != nil {
+		t.Fatalf("Commit in batch: %v", err)
+	}
+	b, _ := env.Store.Create("B", issue.CreateOpts{})
+	env.Store.Commit("create " + b.ID)
+	if got, _ := env.Repo.AllCommits(); len(got) != len(before) {
+		t.Fatal("Commit inside a batch reached the repo")
+	}
+	intents := env.Store.EndBatch()
+	if len(intents) != 2 || intents[1] != "create "+b.ID {
+		t.Fatalf("EndBatch = %q", intents)
+	}
+
+	if err := env.Store.Commit(strings.Join(intents, "\n")); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+	after, _ := env.Repo.AllCommits()
+	if len(after) != len(before)+1 {
+		t.Errorf("got %d new commits, want 1", len(after)-len(before))
+	}
+}
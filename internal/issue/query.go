@@ -0,0 +1,576 @@
+package issue
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Query is a parsed filter expression such as
+//
+//	status:open priority<=1 label:auth -label:wontfix assignee:me
+//	due<7d parent:bw-a1b2 (type:bug OR type:task)
+//
+// Terms separated by whitespace are ANDed; OR, NOT/-, and parentheses
+// combine them. A term is <field><op><value> with op one of : = != < <=
+// > >=; a bare word matches title or description. Build one with
+// ParseQuery and apply it with Match, or pass it in Filter.Query.
+type Query struct {
+	src  string
+	root queryNode
+}
+
+// QueryEnv supplies the context a query is evaluated in: the clock for
+// relative dates and the user "me" stands for.
+type QueryEnv struct {
+	Now time.Time
+	Me  string
+}
+
+// QueryEnv returns the environment queries against this store are
+// evaluated in.
+func (s *Store) QueryEnv() QueryEnv {
+	return QueryEnv{Now: s.Now(), Me: s.User}
+}
+
+// QueryError reports a parse error at a byte offset in the query.
+type QueryError struct {
+	Query string
+	Pos   int
+	Token string
+	Msg   string
+}
+
+func (e *QueryError) Error() string {
+	if e.Token == "" {
+		return fmt.Sprintf("query: %s at end of input", e.Msg)
+	}
+	return fmt.Sprintf("query: %s at column %d: %q", e.Msg, e.Pos+1, e.Token)
+}
+
+// String returns the query source.
+func (q *Query) String() string { return q.src }
+
+// Match reports whether iss satisfies the query.
+func (q *Query) Match(iss *Issue, env QueryEnv) bool {
+	return q.root.match(iss, env)
+}
+
+// Mentions reports whether the query has a term on field (by canonical
+// name), e.g. so callers can widen default status scoping when the query
+// picks statuses itself.
+func (q *Query) Mentions(field string) bool {
+	return q.root.mentions(field)
+}
+
+// Filter returns the issues that satisfy the query, in order.
+func (q *Query) Filter(issues []*Issue, env QueryEnv) []*Issue {
+	var out []*Issue
+	for _, iss := range issues {
+		if q.Match(iss, env) {
+			out = append(out, iss)
+		}
+	}
+	return out
+}
+
+type queryNode interface {
+	match(iss *Issue, env QueryEnv) bool
+	mentions(field string) bool
+}
+
+type andNode []queryNode
+type orNode []queryNode
+type notNode struct{ inner queryNode }
+
+func (n andNode) match(iss *Issue, env QueryEnv) bool {
+	for _, c := range n {
+		if !c.match(iss, env) {
+			return false
+		}
+	}
+	return true
+}
+
+func (n andNode) mentions(f string) bool {
+	for _, c := range n {
+		if c.mentions(f) {
+			return true
+		}
+	}
+	return false
+}
+
+func (n orNode) match(iss *Issue, env QueryEnv) bool {
+	for _, c := range n {
+		if c.match(iss, env) {
+			return true
+		}
+	}
+	return false
+}
+
+func (n orNode) mentions(f string) bool { return andNode(n).mentions(f) }
+
+func (n notNode) match(iss *Issue, env QueryEnv) bool { return !n.inner.match(iss, env) }
+func (n notNode) mentions(f string) bool              { return n.inner.mentions(f) }
+
+// Query field kinds.
+const (
+	qString = iota // exact, case-insensitive
+	qText          // substring, case-insensitive
+	qLabel         // membership
+	qInt
+	qDate
+	qCustom // field.<name>: numeric, date, or string comparison
+)
+
+var queryFields = map[string]int{
+	"status":   qString,
+	"type":     qString,
+	"assignee": qString,
+	"parent":   qString,
+	"id":       qString,
+	"title":    qText,
+	"text":     qText,
+	"label":    qLabel,
+	"priority": qInt,
+	"due":      qDate,
+	"defer":    qDate,
+	"created":  qDate,
+	"updated":  qDate,
+	"closed":   qDate,
+}
+
+var queryAliases = map[string]string{
+	"s": "status", "t": "type", "a": "assignee", "l": "label", "p": "priority",
+}
+
+type termNode struct {
+	field string
+	kind  int
+	op    string
+	value string
+	num   int // parsed value for qInt
+}
+
+func (n *termNode) mentions(f string) bool { return n.field == f }
+
+func (n *termNode) match(iss *Issue, env QueryEnv) bool {
+	switch n.kind {
+	case qText:
+		needle := strings.ToLower(n.value)
+		hit := strings.Contains(strings.ToLower(iss.Title), needle)
+		if n.field == "text" {
+			hit = hit || strings.Contains(strings.ToLower(iss.Description), needle)
+		}
+		return hit == (n.op != "!=")
+	case qLabel:
+		return containsStr(iss.Labels, n.value) == (n.op != "!=")
+	case qInt:
+		return compareOrdered(iss.Priority, n.num, n.op)
+	case qDate:
+		return matchDate(issueDate(iss, n.field), n.value, n.op, env)
+	case qCustom:
+		return matchCustom(iss.Fields[strings.TrimPrefix(n.field, "field.")], n.value, n.op, env)
+	}
+	got := issueString(iss, n.field)
+	want := n.value
+	switch {
+	case want == "me" && n.field == "assignee":
+		want = env.Me
+	case want == "none":
+		want = ""
+	}
+	eq := strings.EqualFold(got, want)
+	if n.op == "!=" {
+		return !eq
+	}
+	return eq
+}
+
+func issueString(iss *Issue, field string) string {
+	switch field {
+	case "status":
+		return iss.Status
+	case "type":
+		return iss.Type
+	case "assignee":
+		return iss.Assignee
+	case "parent":
+		return iss.Parent
+	case "id":
+		return iss.ID
+	}
+	return ""
+}
+
+func issueDate(iss *Issue, field string) string {
+	switch field {
+	case "due":
+		return iss.Due
+	case "defer":
+		return iss.DeferUntil
+	case "created":
+		return iss.Created
+	case "updated":
+		return iss.UpdatedAt
+	case "closed":
+		return iss.ClosedAt
+	}
+	return ""
+}
+
+func compareOrdered[T int | string](got, want T, op string) bool {
+	switch op {
+	case ":", "=":
+		return got == want
+	case "!=":
+		return got != want
+	case "<":
+		return got < want
+	case "<=":
+		return got <= want
+	case ">":
+		return got > want
+	case ">=":
+		return got >= want
+	}
+	return false
+}
+
+// dayOf reduces a stored date or RFC3339 timestamp to a local YYYY-MM-DD
+// day, the granularity all date terms compare at.
+func dayOf(s string) (string, bool) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.In(time.Local).Format("2006-01-02"), true
+	}
+	if _, err := time.Parse("2006-01-02", s); err == nil {
+		return s, true
+	}
+	return "", false
+}
+
+// queryDay resolves a date term value: YYYY-MM-DD, RFC3339, today,
+// tomorrow, yesterday, or a day offset from today such as 7d, 2w or -3d.
+func queryDay(v string, now time.Time) (string, bool) {
+	if d, ok := dayOf(v); ok {
+		return d, true
+	}
+	local := now.In(time.Local)
+	switch strings.ToLower(v) {
+	case "today":
+		return local.Format("2006-01-02"), true
+	case "tomorrow":
+		return local.AddDate(0, 0, 1).Format("2006-01-02"), true
+	case "yesterday":
+		return local.AddDate(0, 0, -1).Format("2006-01-02"), true
+	}
+	if len(v) < 2 {
+		return "", false
+	}
+	n, err := strconv.Atoi(v[:len(v)-1])
+	if err != nil {
+		return "", false
+	}
+	switch v[len(v)-1] {
+	case 'd':
+		return local.AddDate(0, 0, n).Format("2006-01-02"), true
+	case 'w':
+		return local.AddDate(0, 0, 7*n).Format("2006-01-02"), true
+	}
+	return "", false
+}
+
+func matchDate(got, value, op string, env QueryEnv) bool {
+	if value == "none" {
+		return (got == "") == (op != "!=")
+	}
+	gotDay, ok := dayOf(got)
+	if !ok {
+		return op == "!="
+	}
+	want, _ := queryDay(value, env.Now)
+	return compareOrdered(gotDay, want, op)
+}
+
+func matchCustom(got, value, op string, env QueryEnv) bool {
+	if value == "none" {
+		return (got == "") == (op != "!=")
+	}
+	if got == "" {
+		return op == "!="
+	}
+	if g, err := strconv.Atoi(got); err == nil {
+		if w, err := strconv.Atoi(value); err == nil {
+			return compareOrdered(g, w, op)
+		}
+	}
+	if g, ok := dayOf(got); ok {
+		if w, ok := queryDay(value, env.Now); ok {
+			return compareOrdered(g, w, op)
+		}
+	}
+	if op == ":" || op == "=" || op == "!=" {
+		return strings.EqualFold(got, value) == (op != "!=")
+	}
+	return compareOrdered(got, value, op)
+}
+
+// --- parsing ---
+
+type queryToken struct {
+	text string // for words: the raw token including any quotes
+	pos  int
+}
+
+// lexQuery splits src into parentheses and words. Quoted sections
+// ("...") keep their spaces and may appear anywhere in a word.
+func lexQuery(src string) ([]queryToken, error) {
+	var toks []queryToken
+	i := 0
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c == '(' || c == ')':
+			toks = append(toks, queryToken{text: string(c), pos: i})
+			i++
+		default:
+			start := i
+			inQuote := false
+			for i < len(src) {
+				c := src[i]
+				if c == '"' {
+					inQuote = !inQuote
+				} else if !inQuote && (c == ' ' || c == '\t' || c == '\n' || c == '(' || c == ')') {
+					break
+				}
+				i++
+			}
+			if inQuote {
+				return nil, &QueryError{Query: src, Pos: start, Token: src[start:], Msg: "unterminated quote"}
+			}
+			toks = append(toks, queryToken{text: src[start:i], pos: start})
+		}
+	}
+	return toks, nil
+}
+
+type queryParser struct {
+	src  string
+	toks []queryToken
+	i    int
+}
+
+// ParseQuery parses a query expression. An empty or all-whitespace query
+// matches every issue.
+func ParseQuery(src string) (*Query, error) {
+	toks, err := lexQuery(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &queryParser{src: src, toks: toks}
+	if len(toks) == 0 {
+		return &Query{src: src, root: andNode{}}, nil
+	}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.i < len(p.toks) {
+		return nil, p.errorf(p.toks[p.i], "unexpected token")
+	}
+	return &Query{src: src, root: root}, nil
+}
+
+func (p *queryParser) errorf(t queryToken, msg string) error {
+	return &QueryError{Query: p.src, Pos: t.pos, Token: t.text, Msg: msg}
+}
+
+func (p *queryParser) peek() (queryToken, bool) {
+	if p.i < len(p.toks) {
+		return p.toks[p.i], true
+	}
+	return queryToken{}, false
+}
+
+func (p *queryParser) parseOr() (queryNode, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	nodes := orNode{first}
+	for {
+		t, ok := p.peek()
+		if !ok || t.text != "OR" {
+			break
+		}
+		p.i++
+		next, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, next)
+	}
+	if len(nodes) == 1 {
+		return first, nil
+	}
+	return nodes, nil
+}
+
+func (p *queryParser) parseAnd() (queryNode, error) {
+	var nodes andNode
+	for {
+		t, ok := p.peek()
+		if !ok || t.text == ")" || t.text == "OR" {
+			break
+		}
+		if t.text == "AND" {
+			p.i++
+			continue
+		}
+		n, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, n)
+	}
+	if len(nodes) == 0 {
+		t, ok := p.peek()
+		if !ok {
+			return nil, &QueryError{Query: p.src, Pos: len(p.src), Msg: "expected a term"}
+		}
+		return nil, p.errorf(t, "expected a term")
+	}
+	if len(nodes) == 1 {
+		return nodes[0], nil
+	}
+	return nodes, nil
+}
+
+func (p *queryParser) parseUnary() (queryNode, error) {
+	t, _ := p.peek()
+	switch {
+	case t.text == "NOT":
+		p.i++
+		if _, ok := p.peek(); !ok {
+			return nil, p.errorf(t, "NOT needs a term")
+		}
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{inner}, nil
+	case t.text == "-":
+		return nil, p.errorf(t, "- must be attached to a term")
+	case strings.HasPrefix(t.text, "-"):
+		// -label:wontfix: negate the rest of the word in place.
+		p.toks[p.i] = queryToken{text: t.text[1:], pos: t.pos + 1}
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{inner}, nil
+	case t.text == "(":
+		p.i++
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if c, ok := p.peek(); !ok || c.text != ")" {
+			return nil, p.errorf(t, "unclosed parenthesis")
+		}
+		p.i++
+		return inner, nil
+	case t.text == ")":
+		return nil, p.errorf(t, "unexpected )")
+	}
+	p.i++
+	return p.parseTerm(t)
+}
+
+// queryOps lists comparison operators, longest first so "<=" wins over "<".
+var queryOps = []string{"!=", "<=", ">=", ":", "=", "<", ">"}
+
+func (p *queryParser) parseTerm(t queryToken) (queryNode, error) {
+	word := t.text
+	opAt, op := -1, ""
+	for i := 0; i < len(word) && opAt < 0; i++ {
+		if word[i] == '"' {
+			break // a quoted bare word
+		}
+		for _, o := range queryOps {
+			if strings.HasPrefix(word[i:], o) {
+				opAt, op = i, o
+				break
+			}
+		}
+	}
+	if opAt < 0 {
+		return &termNode{field: "text", kind: qText, op: ":", value: unquote(word)}, nil
+	}
+
+	name := strings.ToLower(word[:opAt])
+	if full, ok := queryAliases[name]; ok {
+		name = full
+	}
+	value := unquote(word[opAt+len(op):])
+	if name == "" {
+		return nil, p.errorf(t, "missing field name")
+	}
+	if value == "" && word[len(word)-1] != '"' {
+		return nil, p.errorf(t, "missing value")
+	}
+
+	n := &termNode{field: name, op: op, value: value}
+	if strings.HasPrefix(name, "field.") && len(name) > len("field.") {
+		n.kind = qCustom
+		return n, nil
+	}
+	kind, ok := queryFields[name]
+	if !ok {
+		return nil, p.errorf(t, fmt.Sprintf("unknown field %q", name))
+	}
+	n.kind = kind
+
+	ordered := op != ":" && op != "=" && op != "!="
+	switch kind {
+	case qInt:
+		v := strings.TrimPrefix(strings.TrimPrefix(value, "p"), "P")
+		num, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, p.errorf(t, "priority must be a number")
+		}
+		n.num = num
+	case qDate:
+		if value == "none" {
+			if ordered {
+				return nil, p.errorf(t, "none only works with : or !=")
+			}
+			break
+		}
+		if _, ok := queryDay(value, time.Now()); !ok {
+			return nil, p.errorf(t, "invalid date (use YYYY-MM-DD, today, or an offset like 7d, -2w)")
+		}
+	default:
+		if ordered {
+			return nil, p.errorf(t, fmt.Sprintf("%s does not support %s", name, op))
+		}
+	}
+	return n, nil
+}
+
+func unquote(s string) string {
+	if !strings.Contains(s, `"`) {
+		return s
+	}
+	return strings.Map(func(r rune) rune {
+		if r == '"' {
+			return -1
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,173 @@
+package issue_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jallum/beadwork/internal/issue"
+	"github.com/jallum/beadwork/internal/testutil"
+)
+
+func mustQuery(t *testing.T, src string) *issue.Query {
+	t.Helper()
+	q, err := issue.ParseQuery(src)
+	if err != nil {
+		t.Fatalf("ParseQuery(%q): %v", src, err)
+	}
+	return q
+}
+
+func TestQueryMatch(t *testing.T) {
+	now := time.Date(2027, 3, 10, 12, 0, 0, 0, time.Local)
+	env := issue.QueryEnv{Now: now, Me: "alice"}
+	iss := &issue.Issue{
+		ID:          "bw-a1b2",
+		Title:       "Login fails on Safari",
+		Description: "Cookie is dropped",
+		Status:      "open",
+		Priority:    1,
+		Type:        "bug",
+		Assignee:    "alice",
+		Labels:      []string{"auth", "web"},
+		Parent:      "bw-epic",
+		Due:         "2027-03-14",
+		Created:     "2027-03-01T09:00:00Z",
+		Fields:      map[string]string{"estimate": "5", "severity": "high", "target": "2027-04-01"},
+	}
+
+	tests := []struct {
+		q    string
+		want bool
+	}{
+		{"", true},
+		{"status:open", true},
+		{"status:closed", false},
+		{"status!=closed", true},
+		{"s:open t:bug", true},
+		{"priority<=1", true},
+		{"priority<1", false},
+		{"priority:P1", true},
+		{"label:auth", true},
+		{"-label:auth", false},
+		{"-label:wontfix", true},
+		{"label!=wontfix", true},
+		{"assignee:me", true},
+		{"assignee:bob", false},
+		{"assignee:none", false},
+		{"parent:bw-epic", true},
+		{"id:BW-A1B2", true},
+		{"due<7d", true},
+		{"due<3d", false},
+		{"due<=2027-03-14", true},
+		{"due:none", false},
+		{"defer:none", true},
+		{"defer<7d", false},
+		{"created>-14d", true},
+		{"created<yesterday", true},
+		{"closed:none", true},
+		{"safari", true},
+		{"cookie", true},
+		{"title:cookie", false},
+		{`"fails on"`, true},
+		{`title:"fails on"`, true},
+		{"type:bug OR type:task", true},
+		{"type:epic OR type:task", false},
+		{"status:open (type:epic OR type:task)", false},
+		{"status:open (type:bug OR type:task)", true},
+		{"NOT (type:epic OR label:web)", false},
+		{"status:open AND priority>=1", true},
+		{"field.estimate>3", true},
+		{"field.estimate>10", false},
+		{"field.severity:HIGH", true},
+		{"field.target<30d", true},
+		{"field.customer:none", true},
+		{"field.customer:acme", false},
+		{"status:open priority<=1 label:auth -label:wontfix assignee:me due<7d parent:bw-epic (type:bug OR type:task)", true},
+	}
+	for _, tt := range tests {
+		if got := mustQuery(t, tt.q).Match(iss, env); got != tt.want {
+			t.Errorf("%q: Match = %v, want %v", tt.q, got, tt.want)
+		}
+	}
+}
+
+func TestQueryOrBindsLooserThanAnd(t *testing.T) {
+	env := issue.QueryEnv{Now: time.Now()}
+	q := mustQuery(t, "type:bug priority:0 OR type:task")
+	if !q.Match(&issue.Issue{Type: "task", Priority: 3}, env) {
+		t.Error("task should match the second branch")
+	}
+	if q.Match(&issue.Issue{Type: "bug", Priority: 3}, env) {
+		t.Error("P3 bug should not match")
+	}
+}
+
+func TestQueryMentions(t *testing.T) {
+	q := mustQuery(t, "label:x (s:closed OR -type:bug)")
+	if !q.Mentions("status") || !q.Mentions("type") || q.Mentions("priority") {
+		t.Error("Mentions wrong")
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	tests := []struct {
+		q     string
+		token string
+		msg   string
+	}{
+		{"status:open bogus:1", "bogus:1", "unknown field"},
+		{"priority<=high", "priority<=high", "priority must be a number"},
+		{"due<soon", "due<soon", "invalid date"},
+		{"status<open", "status<open", "does not support <"},
+		{"(type:bug OR type:task", "(", "unclosed parenthesis"},
+		{"type:bug )", ")", "unexpected"},
+		{"type:bug OR", "", "expected a term"},
+		{`title:"open`, `title:"open`, "unterminated quote"},
+		{"label:", "label:", "missing value"},
+		{":x", ":x", "missing field name"},
+	}
+	for _, tt := range tests {
+		_, err := issue.ParseQuery(tt.q)
+		var qe *issue.QueryError
+		if !errors.As(err, &qe) {
+			t.Errorf("%q: err = %v, want *QueryError", tt.q, err)
+			continue
+		}
+		if qe.Token != tt.token || !strings.Contains(qe.Msg, tt.msg) {
+			t.Errorf("%q: got token %q msg %q", tt.q, qe.Token, qe.Msg)
+		}
+		if qe.Token != "" && tt.q[qe.Pos:qe.Pos+len(qe.Token)] != qe.Token {
+			t.Errorf("%q: Pos %d does not point at %q", tt.q, qe.Pos, qe.Token)
+		}
+	}
+}
+
+func TestParseQueryErrorMessage(t *testing.T) {
+	_, err := issue.ParseQuery("status:open colour:red")
+	if err == nil || err.Error() != `query: unknown field "colour" at column 13: "colour:red"` {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestListWithQuery(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+	env.Store.User = "alice"
+
+	a, _ := env.Store.Create("Auth bug", issue.CreateOpts{Type: "bug", Assignee: "alice"})
+	env.Store.Label(a.ID, []string{"auth"}, nil)
+	b, _ := env.Store.Create("Auth task", issue.CreateOpts{Type: "task"})
+	env.Store.Label(b.ID, []string{"auth", "wontfix"}, nil)
+	env.Store.Create("Docs", issue.CreateOpts{Type: "task", Assignee: "alice"})
+	env.Repo.Commit("create")
+
+	got, err := env.Store.List(issue.Filter{Query: mustQuery(t, "label:auth -label:wontfix assignee:me")})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(got) != 1 || got[0].ID != a.ID {
+		t.Errorf("got %d issues, want only %s", len(got), a.ID)
+	}
+}
@@ -0,0 +1,53 @@
+package issue
+
+import "fmt"
+
+type RecurOpts struct {
+	ID         string // explicit ID for the next instance; empty generates one
+	DeferUntil string // when the next instance becomes ready
+}
+
+// Recur creates the next instance of the recurring issue id: a copy of
+// its title, description, type, priority, assignee, parent, labels,
+// fields and rule, deferred until opts.DeferUntil and recorded as
+// superseding its predecessor. Working out when the next occurrence
+// falls is left to the caller, so replaying the intent does not depend
+// on when it runs.
+func (s *Store) Recur(id string, opts RecurOpts) (*Issue, error) {
+	id, err := s.resolveID(id)
+	if err != nil {
+		return nil, err
+	}
+	prev, err := s.readIssue(id)
+	if err != nil {
+		return nil, err
+	}
+	if prev.Every == "" {
+		return nil, fmt.Errorf("%s does not recur", id)
+	}
+
+	priority := prev.Priority
+	next, err := s.Create(prev.Title, CreateOpts{
+		ID:          opts.ID,
+		Parent:      prev.Parent,
+		Description: prev.Description,
+		Priority:    &priority,
+		Type:        prev.Type,
+		Assignee:    prev.Assignee,
+		DeferUntil:  opts.DeferUntil,
+		Every:       prev.Every,
+		Fields:      prev.Fields,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(prev.Labels) > 0 {
+		if next, err = s.Label(next.ID, prev.Labels, nil); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.Relate(Supersedes, next.ID, id); err != nil {
+		return nil, err
+	}
+	return s.readIssue(next.ID)
+}
@@ -0,0 +1,76 @@
+package issue_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jallum/beadwork/internal/issue"
+	"github.com/jallum/beadwork/internal/testutil"
+)
+
+func TestRecur(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	p := 1
+	parent, _ := env.Store.Create("Ops", issue.CreateOpts{})
+	iss, _ := env.Store.Create("Rotate keys", issue.CreateOpts{
+		Parent:      parent.ID,
+		Description: "Run the rotation script",
+		Priority:    &p,
+		Type:        "chore",
+		Every:       "monthly",
+	})
+	env.Store.Label(iss.ID, []string{"ops"}, nil)
+	env.Store.Close(iss.ID, "")
+
+	next, err := env.Store.Recur(iss.ID, issue.RecurOpts{DeferUntil: "2027-04-01"})
+	if err != nil {
+		t.Fatalf("Recur: %v", err)
+	}
+	if next.ID == iss.ID {
+		t.Fatal("next instance reused the predecessor's ID")
+	}
+	if next.Title != "Rotate keys" || next.Description != "Run the rotation script" ||
+		next.Priority != 1 || next.Type != "chore" || next.Parent != parent.ID || next.Every != "monthly" {
+		t.Errorf("next = %+v", next)
+	}
+	if len(next.Labels) != 1 || next.Labels[0] != "ops" {
+		t.Errorf("labels = %v, want [ops]", next.Labels)
+	}
+	if next.Status != "deferred" || next.DeferUntil != "2027-04-01" {
+		t.Errorf("status=%s defer=%s, want deferred until 2027-04-01", next.Status, next.DeferUntil)
+	}
+	if len(next.Supersedes) != 1 || next.Supersedes[0] != iss.ID {
+		t.Errorf("supersedes = %v, want [%s]", next.Supersedes, iss.ID)
+	}
+	prev, _ := env.Store.Get(iss.ID)
+	if len(prev.SupersededBy) != 1 || prev.SupersededBy[0] != next.ID {
+		t.Errorf("predecessor superseded_by = %v, want [%s]", prev.SupersededBy, next.ID)
+	}
+}
+
+func TestRecurExplicitID(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	iss, _ := env.Store.Create("Standup", issue.CreateOpts{Every: "9am"})
+	next, err := env.Store.Recur(iss.ID, issue.RecurOpts{ID: "test-next", DeferUntil: "2027-01-02T09:00:00Z"})
+	if err != nil {
+		t.Fatalf("Recur: %v", err)
+	}
+	if next.ID != "test-next" {
+		t.Errorf("ID = %q, want test-next", next.ID)
+	}
+}
+
+func TestRecurRequiresRule(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	iss, _ := env.Store.Create("One-off", issue.CreateOpts{})
+	_, err := env.Store.Recur(iss.ID, issue.RecurOpts{DeferUntil: "2027-01-02"})
+	if err == nil || !strings.Contains(err.Error(), "does not recur") {
+		t.Errorf("err = %v, want 'does not recur'", err)
+	}
+}
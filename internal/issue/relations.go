@@ -0,0 +1,222 @@
+package issue
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Relation kinds beyond blocks. Each link is stored as a marker file
+// <kind>/<from>/<to> and mirrored in both issues' JSON. Unlike blocks,
+// relations never affect readiness.
+const (
+	RelatesTo  = "relates-to" // symmetric
+	Duplicates = "duplicates" // from is a duplicate of to
+	Supersedes = "supersedes" // from replaces to
+)
+
+// RelationKinds lists the relation kinds in display order.
+var RelationKinds = []string{RelatesTo, Duplicates, Supersedes}
+
+// IsRelationKind reports whether kind is one of RelationKinds.
+func IsRelationKind(kind string) bool {
+	return containsStr(RelationKinds, kind)
+}
+
+// relationLists returns the issue's outgoing and incoming ID lists for
+// kind. For the symmetric relates-to both are the same list.
+func relationLists(iss *Issue, kind string) (out, in *[]string) {
+	switch kind {
+	case RelatesTo:
+		return &iss.RelatesTo, &iss.RelatesTo
+	case Duplicates:
+		return &iss.Duplicates, &iss.DuplicatedBy
+	case Supersedes:
+		return &iss.Supersedes, &iss.SupersededBy
+	}
+	return nil, nil
+}
+
+// Relate records that fromID <kind> toID.
+func (s *Store) Relate(kind, fromID, toID string) error {
+	if !IsRelationKind(kind) {
+		return fmt.Errorf("unknown relation %q (known: blocks, %s)", kind, strings.Join(RelationKinds, ", "))
+	}
+	fromID, toID, err := s.resolvePair(fromID, toID)
+	if err != nil {
+		return err
+	}
+	if fromID == toID {
+		return fmt.Errorf("an issue cannot be related to itself")
+	}
+	if kind != RelatesTo && s.RelationExists(kind, toID, fromID) {
+		return fmt.Errorf("%s already %s %s", toID, kind, fromID)
+	}
+
+	s.FS.MkdirAll(kind + "/" + fromID)
+	if err := s.FS.WriteFile(kind+"/"+fromID+"/"+toID, []byte{}); err != nil {
+		return err
+	}
+
+	now := s.nowRFC3339()
+	from, err := s.readIssue(fromID)
+	if err != nil {
+		return err
+	}
+	out, _ := relationLists(from, kind)
+	*out = addSorted(*out, toID)
+	from.UpdatedAt = now
+	if err := s.writeIssue(from); err != nil {
+		return err
+	}
+
+	to, err := s.readIssue(toID)
+	if err != nil {
+		return err
+	}
+	_, in := relationLists(to, kind)
+	*in = addSorted(*in, fromID)
+	to.UpdatedAt = now
+	return s.writeIssue(to)
+}
+
+// Unrelate removes a fromID <kind> toID link. For relates-to the link is
+// removed whichever direction it was recorded in.
+func (s *Store) Unrelate(kind, fromID, toID string) error {
+	if !IsRelationKind(kind) {
+		return fmt.Errorf("unknown relation %q (known: blocks, %s)", kind, strings.Join(RelationKinds, ", "))
+	}
+	fromID, toID, err := s.resolvePair(fromID, toID)
+	if err != nil {
+		return err
+	}
+	s.removeRelationMarker(kind, fromID, toID)
+	if kind == RelatesTo {
+		s.removeRelationMarker(kind, toID, fromID)
+	}
+
+	now := s.nowRFC3339()
+	from, err := s.readIssue(fromID)
+	if err != nil {
+		return err
+	}
+	out, _ := relationLists(from, kind)
+	*out = removeStr(*out, toID)
+	from.UpdatedAt = now
+	if err := s.writeIssue(from); err != nil {
+		return err
+	}
+
+	to, err := s.readIssue(toID)
+	if err != nil {
+		return err
+	}
+	_, in := relationLists(to, kind)
+	*in = removeStr(*in, fromID)
+	to.UpdatedAt = now
+	return s.writeIssue(to)
+}
+
+// RelationExists reports whether fromID <kind> toID is recorded. For
+// relates-to either direction counts.
+func (s *Store) RelationExists(kind, fromID, toID string) bool {
+	fromID, toID, err := s.resolvePair(fromID, toID)
+	if err != nil {
+		return false
+	}
+	if _, err := s.FS.Stat(kind + "/" + fromID + "/" + toID); err == nil {
+		return true
+	}
+	if kind == RelatesTo {
+		_, err := s.FS.Stat(kind + "/" + toID + "/" + fromID)
+		return err == nil
+	}
+	return false
+}
+
+// Relations returns the issue's related IDs keyed by section: the kind
+// for outgoing links and "<kind>-by" for incoming ones (relates-to has
+// no incoming form). Empty entries are omitted.
+func Relations(iss *Issue) map[string][]string {
+	rel := make(map[string][]string)
+	for _, kind := range RelationKinds {
+		out, in := relationLists(iss, kind)
+		if len(*out) > 0 {
+			rel[kind] = *out
+		}
+		if kind != RelatesTo && len(*in) > 0 {
+			rel[kind+"-by"] = *in
+		}
+	}
+	return rel
+}
+
+// dropRelations removes every relation marker involving iss and strips
+// its ID from the related issues. Used by Delete.
+func (s *Store) dropRelations(iss *Issue) {
+	for _, kind := range RelationKinds {
+		out, in := relationLists(iss, kind)
+		for _, other := range *out {
+			s.removeRelationMarker(kind, iss.ID, other)
+			if kind == RelatesTo {
+				s.removeRelationMarker(kind, other, iss.ID)
+			}
+			if o, err := s.readIssue(other); err == nil {
+				_, oin := relationLists(o, kind)
+				*oin = removeStr(*oin, iss.ID)
+				s.writeIssue(o)
+			}
+		}
+		if kind == RelatesTo {
+			continue
+		}
+		for _, other := range *in {
+			s.removeRelationMarker(kind, other, iss.ID)
+			if o, err := s.readIssue(other); err == nil {
+				oout, _ := relationLists(o, kind)
+				*oout = removeStr(*oout, iss.ID)
+				s.writeIssue(o)
+			}
+		}
+	}
+}
+
+// removeRelationMarker removes <kind>/<from>/<to> and the directory's
+// .gitkeep once it holds no other links.
+func (s *Store) removeRelationMarker(kind, fromID, toID string) {
+	s.removeMarker(kind+"/"+fromID, toID)
+}
+
+// removeMarker removes the marker file dir/name and the directory's
+// .gitkeep once nothing else is left in it.
+func (s *Store) removeMarker(dir, name string) {
+	s.FS.Remove(dir + "/" + name)
+	entries, _ := s.FS.ReadDir(dir)
+	for _, e := range entries {
+		if e.Name() != ".gitkeep" {
+			return
+		}
+	}
+	s.FS.Remove(dir + "/.gitkeep")
+}
+
+func (s *Store) resolvePair(a, b string) (string, string, error) {
+	a, err := s.resolveID(a)
+	if err != nil {
+		return "", "", err
+	}
+	b, err = s.resolveID(b)
+	if err != nil {
+		return "", "", err
+	}
+	return a, b, nil
+}
+
+func addSorted(list []string, id string) []string {
+	if containsStr(list, id) {
+		return list
+	}
+	list = append(list, id)
+	sort.Strings(list)
+	return list
+}
@@ -0,0 +1,134 @@
+package issue_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jallum/beadwork/internal/issue"
+	"github.com/jallum/beadwork/internal/testutil"
+)
+
+func TestRelateMirrorsBothSides(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	a, _ := env.Store.Create("A", issue.CreateOpts{})
+	b, _ := env.Store.Create("B", issue.CreateOpts{})
+	c, _ := env.Store.Create("C", issue.CreateOpts{})
+
+	for _, l := range []struct{ kind, from, to string }{
+		{issue.RelatesTo, a.ID, b.ID},
+		{issue.Duplicates, c.ID, a.ID},
+		{issue.Supersedes, b.ID, c.ID},
+	} {
+		if err := env.Store.Relate(l.kind, l.from, l.to); err != nil {
+			t.Fatalf("Relate %s %s %s: %v", l.from, l.kind, l.to, err)
+		}
+	}
+
+	gotA, _ := env.Store.Get(a.ID)
+	gotB, _ := env.Store.Get(b.ID)
+	gotC, _ := env.Store.Get(c.ID)
+	if want := map[string][]string{issue.RelatesTo: {b.ID}, "duplicates-by": {c.ID}}; !reflect.DeepEqual(issue.Relations(gotA), want) {
+		t.Errorf("Relations(A) = %v, want %v", issue.Relations(gotA), want)
+	}
+	if want := map[string][]string{issue.RelatesTo: {a.ID}, issue.Supersedes: {c.ID}}; !reflect.DeepEqual(issue.Relations(gotB), want) {
+		t.Errorf("Relations(B) = %v, want %v", issue.Relations(gotB), want)
+	}
+	if want := map[string][]string{issue.Duplicates: {a.ID}, "supersedes-by": {b.ID}}; !reflect.DeepEqual(issue.Relations(gotC), want) {
+		t.Errorf("Relations(C) = %v, want %v", issue.Relations(gotC), want)
+	}
+	if _, err := env.Store.FS.Stat("duplicates/" + c.ID + "/" + a.ID); err != nil {
+		t.Errorf("duplicates marker missing: %v", err)
+	}
+
+	// relates-to is symmetric; the others are directional.
+	if !env.Store.RelationExists(issue.RelatesTo, b.ID, a.ID) {
+		t.Error("relates-to should exist in both directions")
+	}
+	if env.Store.RelationExists(issue.Duplicates, a.ID, c.ID) {
+		t.Error("duplicates should not exist in reverse")
+	}
+
+	// Relations never block.
+	ready, _ := env.Store.Ready()
+	if len(ready) != 3 {
+		t.Errorf("Ready = %d issues, want 3", len(ready))
+	}
+}
+
+func TestRelateErrors(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	a, _ := env.Store.Create("A", issue.CreateOpts{})
+	b, _ := env.Store.Create("B", issue.CreateOpts{})
+	env.Store.Relate(issue.Duplicates, a.ID, b.ID)
+
+	if err := env.Store.Relate(issue.RelatesTo, a.ID, a.ID); err == nil {
+		t.Error("expected error relating an issue to itself")
+	}
+	if err := env.Store.Relate(issue.Duplicates, b.ID, a.ID); err == nil {
+		t.Error("expected error for reverse duplicates")
+	}
+	if err := env.Store.Relate("mentions", a.ID, b.ID); err == nil {
+		t.Error("expected error for unknown kind")
+	}
+}
+
+func TestUnrelateRelatesToEitherDirection(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	a, _ := env.Store.Create("A", issue.CreateOpts{})
+	b, _ := env.Store.Create("B", issue.CreateOpts{})
+	env.Store.Relate(issue.RelatesTo, a.ID, b.ID)
+
+	if err := env.Store.Unrelate(issue.RelatesTo, b.ID, a.ID); err != nil {
+		t.Fatalf("Unrelate: %v", err)
+	}
+	if env.Store.RelationExists(issue.RelatesTo, a.ID, b.ID) {
+		t.Error("relation should be gone")
+	}
+	if _, err := env.Store.FS.Stat("relates-to/" + a.ID); err == nil {
+		t.Error("empty relates-to directory should be removed")
+	}
+	gotA, _ := env.Store.Get(a.ID)
+	gotB, _ := env.Store.Get(b.ID)
+	if len(gotA.RelatesTo) != 0 || len(gotB.RelatesTo) != 0 {
+		t.Errorf("RelatesTo = %v / %v, want empty", gotA.RelatesTo, gotB.RelatesTo)
+	}
+}
+
+func TestDeleteDropsRelations(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	a, _ := env.Store.Create("A", issue.CreateOpts{})
+	b, _ := env.Store.Create("B", issue.CreateOpts{})
+	c, _ := env.Store.Create("C", issue.CreateOpts{})
+	env.Store.Relate(issue.RelatesTo, b.ID, a.ID)
+	env.Store.Relate(issue.Supersedes, c.ID, a.ID)
+
+	plan, err := env.Store.DeletePreview(a.ID)
+	if err != nil {
+		t.Fatalf("DeletePreview: %v", err)
+	}
+	if want := map[string][]string{issue.RelatesTo: {b.ID}, "supersedes-by": {c.ID}}; !reflect.DeepEqual(plan.Relations, want) {
+		t.Errorf("plan.Relations = %v, want %v", plan.Relations, want)
+	}
+
+	if _, err := env.Store.Delete(a.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	gotB, _ := env.Store.Get(b.ID)
+	gotC, _ := env.Store.Get(c.ID)
+	if len(gotB.RelatesTo) != 0 || len(gotC.Supersedes) != 0 {
+		t.Errorf("related issues still reference deleted: %v / %v", gotB.RelatesTo, gotC.Supersedes)
+	}
+	for _, p := range []string{"relates-to/" + b.ID + "/" + a.ID, "supersedes/" + c.ID + "/" + a.ID} {
+		if _, err := env.Store.FS.Stat(p); err == nil {
+			t.Errorf("marker %s should be removed", p)
+		}
+	}
+}
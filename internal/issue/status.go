@@ -1,16 +1,26 @@
 package issue
 
-// StatusInfo pairs a status name with its display icon.
+// Status categories. Ready, Blocked, and blocker resolution reason about
+// categories rather than status names, so custom workflow states slot in.
+const (
+	CategoryActive  = "active"  // actionable or being worked (open, in_progress, ...)
+	CategoryWaiting = "waiting" // parked until something external happens (deferred, ...)
+	CategoryDone    = "done"    // resolved; satisfies blockers (closed, ...)
+)
+
+// StatusInfo pairs a status name with its display icon and category.
 type StatusInfo struct {
-	Name string
-	Icon string
+	Name     string
+	Icon     string
+	Category string
 }
 
+// Statuses are the built-in statuses every repo has.
 var Statuses = []StatusInfo{
-	{"open", "○"},
-	{"in_progress", "◐"},
-	{"deferred", "❄"},
-	{"closed", "✓"},
+	{"open", "○", CategoryActive},
+	{"in_progress", "◐", CategoryActive},
+	{"deferred", "❄", CategoryWaiting},
+	{"closed", "✓", CategoryDone},
 }
 
 func StatusNames() []string {
@@ -9,6 +9,7 @@ type SubtreeCloseResult struct {
 	Closed    []*Issue `json:"closed"`
 	Skipped   []*Issue `json:"skipped"`
 	Unblocked []*Issue `json:"unblocked"`
+	Next      []*Issue `json:"next,omitempty"` // next instances of recurring issues
 }
 
 // CloseSubtree closes id and every descendant under it. Members that are
@@ -47,10 +48,13 @@ func (s *Store) CloseSubtree(id, reason string) (*SubtreeCloseResult, error) {
 		if err != nil {
 			continue
 		}
-		if iss.Status == "closed" {
+		if s.isDone(iss.Status) {
 			result.Skipped = append(result.Skipped, iss)
 			continue
 		}
+		if err := s.CurrentWorkflow().CheckTransition(iss.Status, "closed"); err != nil {
+			return nil, fmt.Errorf("%s: %w", memberID, err)
+		}
 
 		memberReason := reason
 		if memberID != id {
@@ -61,6 +65,7 @@ func (s *Store) CloseSubtree(id, reason string) (*SubtreeCloseResult, error) {
 		}
 		now := s.nowRFC3339()
 		iss.Status = "closed"
+		iss.Lease = nil
 		iss.ClosedAt = now
 		iss.CloseReason = memberReason
 		iss.UpdatedAt = now
@@ -0,0 +1,126 @@
+package issue
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// Template is a reusable issue shape for bw create --template. Templates
+// live on the beadwork branch as templates/<name>, a JSON document, so
+// they sync to every clone. Title and Description are text/template
+// sources rendered when the template is used; Children are created under
+// the new issue, recursively.
+type Template struct {
+	Name        string      `json:"name,omitempty"`  // set when read; not stored
+	Title       string      `json:"title,omitempty"` // required for children; overrides the given title at the root
+	Type        string      `json:"type,omitempty"`
+	Priority    *int        `json:"priority,omitempty"`
+	Labels      []string    `json:"labels,omitempty"`
+	Description string      `json:"description,omitempty"`
+	Children    []*Template `json:"children,omitempty"`
+}
+
+// ValidateTemplateName reports whether name may be used for a template.
+func ValidateTemplateName(name string) error {
+	if !viewNameRe.MatchString(name) {
+		return fmt.Errorf("invalid template name %q (use lowercase letters, digits, - and _)", name)
+	}
+	return nil
+}
+
+// Validate checks the template's own values; issue types are checked when
+// the template is used.
+func (t *Template) Validate() error {
+	return t.validate("")
+}
+
+func (t *Template) validate(path string) error {
+	if t.Priority != nil && (*t.Priority < 0 || *t.Priority > 4) {
+		return fmt.Errorf("template%s: priority must be 0-4", path)
+	}
+	for i, c := range t.Children {
+		p := fmt.Sprintf("%s.children[%d]", path, i)
+		if c == nil || c.Title == "" {
+			return fmt.Errorf("template%s: title is required", p)
+		}
+		if err := c.validate(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Encode returns the template as stored: compact JSON without its name.
+func (t *Template) Encode() ([]byte, error) {
+	stored := *t
+	stored.Name = ""
+	return json.Marshal(&stored)
+}
+
+// DecodeTemplate parses a stored template.
+func DecodeTemplate(name string, data []byte) (*Template, error) {
+	var t Template
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("template %s: %w", name, err)
+	}
+	t.Name = name
+	return &t, nil
+}
+
+// SaveTemplate creates or replaces the template name.
+func (s *Store) SaveTemplate(name string, t *Template) error {
+	if err := ValidateTemplateName(name); err != nil {
+		return err
+	}
+	if err := t.Validate(); err != nil {
+		return err
+	}
+	data, err := t.Encode()
+	if err != nil {
+		return err
+	}
+	s.FS.MkdirAll("templates")
+	return s.FS.WriteFile("templates/"+name, append(data, '\n'))
+}
+
+// GetTemplate returns the template name.
+func (s *Store) GetTemplate(name string) (*Template, error) {
+	data, err := s.FS.ReadFile("templates/" + name)
+	if err != nil {
+		return nil, fmt.Errorf("no such template: %s", name)
+	}
+	return DecodeTemplate(name, data)
+}
+
+// Templates returns every template, sorted by name.
+func (s *Store) Templates() ([]*Template, error) {
+	entries, err := s.FS.ReadDir("templates")
+	if err != nil {
+		return nil, nil
+	}
+	var names []string
+	for _, e := range entries {
+		if e.Name() != ".gitkeep" {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	templates := make([]*Template, 0, len(names))
+	for _, name := range names {
+		t, err := s.GetTemplate(name)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, t)
+	}
+	return templates, nil
+}
+
+// DeleteTemplate removes the template name.
+func (s *Store) DeleteTemplate(name string) error {
+	if _, err := s.FS.Stat("templates/" + name); err != nil {
+		return fmt.Errorf("no such template: %s", name)
+	}
+	return s.FS.Remove("templates/" + name)
+}
@@ -0,0 +1,87 @@
+package issue_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jallum/beadwork/internal/issue"
+	"github.com/jallum/beadwork/internal/testutil"
+)
+
+func TestTemplatesSaveListDelete(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	if templates, _ := env.Store.Templates(); len(templates) != 0 {
+		t.Fatalf("Templates = %v, want none", templates)
+	}
+	p := 1
+	bug := &issue.Template{
+		Type:        "bug",
+		Priority:    &p,
+		Labels:      []string{"triage"},
+		Description: "## Steps to reproduce\n\n## Expected\n",
+	}
+	if err := env.Store.SaveTemplate("bug", bug); err != nil {
+		t.Fatalf("SaveTemplate: %v", err)
+	}
+	epic := &issue.Template{Type: "epic", Children: []*issue.Template{{Title: "Design {{.Title}}"}}}
+	if err := env.Store.SaveTemplate("epic", epic); err != nil {
+		t.Fatalf("SaveTemplate: %v", err)
+	}
+	env.Repo.Commit("templates")
+
+	templates, err := env.Store.Templates()
+	if err != nil {
+		t.Fatalf("Templates: %v", err)
+	}
+	if len(templates) != 2 || templates[0].Name != "bug" || templates[1].Name != "epic" {
+		t.Fatalf("Templates = %+v", templates)
+	}
+	got, _ := env.Store.GetTemplate("bug")
+	if got.Type != "bug" || *got.Priority != 1 || got.Description != bug.Description || got.Labels[0] != "triage" {
+		t.Errorf("bug template = %+v", got)
+	}
+	if got, _ := env.Store.GetTemplate("epic"); len(got.Children) != 1 || got.Children[0].Title != "Design {{.Title}}" {
+		t.Errorf("epic children = %+v", got.Children)
+	}
+
+	// The name is not part of the stored document.
+	data, _ := env.Store.FS.ReadFile("templates/bug")
+	if strings.Contains(string(data), `"name"`) {
+		t.Errorf("stored template includes its name: %s", data)
+	}
+
+	if err := env.Store.DeleteTemplate("bug"); err != nil {
+		t.Fatalf("DeleteTemplate: %v", err)
+	}
+	if _, err := env.Store.GetTemplate("bug"); err == nil {
+		t.Error("GetTemplate after delete: expected error")
+	}
+	if err := env.Store.DeleteTemplate("bug"); err == nil {
+		t.Error("DeleteTemplate of missing template: expected error")
+	}
+}
+
+func TestSaveTemplateValidation(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	bad := 7
+	tests := []struct {
+		name string
+		tpl  *issue.Template
+		want string
+	}{
+		{"Bad Name", &issue.Template{}, "invalid template name"},
+		{"prio", &issue.Template{Priority: &bad}, "priority must be 0-4"},
+		{"untitled", &issue.Template{Children: []*issue.Template{{Type: "task"}}}, "children[0]: title is required"},
+		{"nested", &issue.Template{Children: []*issue.Template{{Title: "a", Children: []*issue.Template{{Title: "b", Priority: &bad}}}}}, "children[0].children[0]: priority"},
+	}
+	for _, tt := range tests {
+		err := env.Store.SaveTemplate(tt.name, tt.tpl)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("SaveTemplate(%q): err = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
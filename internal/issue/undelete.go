@@ -0,0 +1,241 @@
+package issue
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// DeletedIssue is an issue that was deleted and can be restored.
+type DeletedIssue struct {
+	*Issue           // as it was just before deletion
+	DeletedAt string `json:"deleted_at"`
+	DeletedIn string `json:"deleted_in"` // hash of the deleting commit
+
+	before string // hash of the last commit the issue existed in
+}
+
+// Deleted lists the issues deleted in this branch's history that do not
+// exist now, most recently deleted first. Each is read as it stood in the
+// parent of the commit that deleted it.
+func (s *Store) Deleted() ([]DeletedIssue, error) {
+	commits, err := s.FS.AllCommits()
+	if err != nil {
+		return nil, fmt.Errorf("reading history: %w", err)
+	}
+	s.ensureIDSet()
+	seen := make(map[string]bool)
+	var out []DeletedIssue
+	for _, c := range commits {
+		if c.Parent == "" {
+			continue
+		}
+		for _, line := range strings.Split(c.Message, "\n") {
+			fields := strings.Fields(line)
+			if len(fields) != 2 || fields[0] != "delete" {
+				continue
+			}
+			id := fields[1]
+			if seen[id] || s.idSet[id] {
+				continue
+			}
+			seen[id] = true
+			iss, err := s.issueAt(c.Parent, id)
+			if err != nil {
+				continue
+			}
+			out = append(out, DeletedIssue{
+				Issue:     iss,
+				DeletedAt: c.Time.UTC().Format(time.RFC3339),
+				DeletedIn: c.Hash,
+				before:    c.Parent,
+			})
+		}
+	}
+	return out, nil
+}
+
+// Undelete restores a deleted issue as it stood before its last deletion:
+// its JSON, status and label markers, attachments, and the blocks and
+// relation links to issues that still exist. Its parent is kept if it
+// still exists, and children the deletion orphaned are re-adopted unless
+// they have been given another parent since. Links that can no longer be
+// made (the counterpart is gone, or the link would now form a cycle) are
+// dropped and returned.
+func (s *Store) Undelete(id string) (*Issue, []string, error) {
+	del, err := s.findDeleted(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	iss := del.Issue
+	id = iss.ID
+
+	blocks, blockedBy := iss.Blocks, iss.BlockedBy
+	rels := Relations(iss)
+
+	iss.Blocks, iss.BlockedBy = []string{}, []string{}
+	iss.RelatesTo, iss.Duplicates, iss.DuplicatedBy = nil, nil, nil
+	iss.Supersedes, iss.SupersededBy = nil, nil
+	if iss.Parent != "" && !s.exists(iss.Parent) {
+		iss.Parent = ""
+	}
+	iss.UpdatedAt = s.nowRFC3339()
+	if err := s.writeIssue(iss); err != nil {
+		return nil, nil, err
+	}
+	if err := s.setStatus(id, iss.Status); err != nil {
+		return nil, nil, err
+	}
+	for _, label := range iss.Labels {
+		s.FS.MkdirAll("labels/" + label)
+		if err := s.FS.WriteFile("labels/"+label+"/"+id, []byte{}); err != nil {
+			return nil, nil, err
+		}
+	}
+	s.trackID(id)
+
+	var dropped []string
+	relink := func(desc string, link func() error, other string) {
+		if !s.exists(other) {
+			dropped = append(dropped, desc+" (deleted)")
+			return
+		}
+		if err := link(); err != nil {
+			dropped = append(dropped, desc+" ("+err.Error()+")")
+		}
+	}
+	for _, other := range blocks {
+		relink(id+" blocks "+other, func() error { return s.Link(id, other) }, other)
+	}
+	for _, other := range blockedBy {
+		relink(other+" blocks "+id, func() error { return s.Link(other, id) }, other)
+	}
+	for _, kind := range RelationKinds {
+		for _, other := range rels[kind] {
+			relink(id+" "+kind+" "+other, func() error { return s.Relate(kind, id, other) }, other)
+		}
+		for _, other := range rels[kind+"-by"] {
+			relink(other+" "+kind+" "+id, func() error { return s.Relate(kind, other, id) }, other)
+		}
+	}
+
+	if err := s.readoptChildren(id, del.before); err != nil {
+		return nil, nil, err
+	}
+	if err := s.restoreAttachments(id, del.before); err != nil {
+		return nil, nil, err
+	}
+	iss, err = s.readIssue(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return iss, dropped, nil
+}
+
+// findDeleted resolves id, in full or as a unique prefix or suffix,
+// against the deleted issues.
+func (s *Store) findDeleted(id string) (*DeletedIssue, error) {
+	if s.exists(id) {
+		return nil, fmt.Errorf("%s exists; only deleted issues can be restored", id)
+	}
+	deleted, err := s.Deleted()
+	if err != nil {
+		return nil, err
+	}
+	var matches []*DeletedIssue
+	for i := range deleted {
+		d := &deleted[i]
+		if d.ID == id {
+			return d, nil
+		}
+		if strings.HasPrefix(d.ID, id) || strings.HasSuffix(d.ID, id) {
+			matches = append(matches, d)
+		}
+	}
+	if len(matches) == 1 {
+		return matches[0], nil
+	}
+	if len(matches) > 1 {
+		ids := make([]string, len(matches))
+		for i, m := range matches {
+			ids[i] = m.ID
+		}
+		return nil, fmt.Errorf("ambiguous ID %q: matches deleted %s", id, strings.Join(ids, ", "))
+	}
+	return nil, fmt.Errorf("no deleted issue found matching %q", id)
+}
+
+// readoptChildren sets the parent of every issue that had id as its parent
+// at commit before and has had no parent since.
+func (s *Store) readoptChildren(id, before string) error {
+	all, err := s.List(Filter{})
+	if err != nil {
+		return err
+	}
+	for _, child := range all {
+		if child.Parent != "" || child.ID == id {
+			continue
+		}
+		old, err := s.issueAt(before, child.ID)
+		if err != nil || old.Parent != id {
+			continue
+		}
+		child.Parent = id
+		child.UpdatedAt = s.nowRFC3339()
+		if err := s.writeIssue(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreAttachments writes back the attachments recorded for id by attach
+// intents that are missing from the tree, reading them as of commit before.
+func (s *Store) restoreAttachments(id, before string) error {
+	commits, err := s.FS.AllCommits()
+	if err != nil {
+		return fmt.Errorf("reading history: %w", err)
+	}
+	prefix := "attach " + id + " "
+	for _, c := range commits {
+		for _, line := range strings.Split(c.Message, "\n") {
+			storedPath, ok := strings.CutPrefix(line, prefix)
+			if !ok || storedPath == "" {
+				continue
+			}
+			p := attachmentPath(id, storedPath)
+			if _, err := s.FS.Stat(p); err == nil {
+				continue
+			}
+			data, err := s.FS.ReadFileAt(plumbing.NewHash(before), p)
+			if err != nil {
+				continue // removed before the issue was deleted
+			}
+			if err := s.Attach(id, storedPath, data); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// issueAt reads issue id as of the given commit.
+func (s *Store) issueAt(hash, id string) (*Issue, error) {
+	data, err := s.FS.ReadFileAt(plumbing.NewHash(hash), "issues/"+id+".json")
+	if err != nil {
+		return nil, err
+	}
+	var iss Issue
+	if err := json.Unmarshal(data, &iss); err != nil {
+		return nil, fmt.Errorf("corrupt issue %s at %.7s: %w", id, hash, err)
+	}
+	return &iss, nil
+}
+
+func (s *Store) exists(id string) bool {
+	s.ensureIDSet()
+	return s.idSet[id]
+}
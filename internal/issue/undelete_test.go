@@ -0,0 +1,140 @@
+package issue_test
+
+import (
+	"testing"
+
+	"github.com/jallum/beadwork/internal/issue"
+	"github.com/jallum/beadwork/internal/testutil"
+)
+
+func TestDeletedListsRecoverableIssues(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	a, _ := env.Store.Create("Gone", issue.CreateOpts{})
+	b, _ := env.Store.Create("Kept", issue.CreateOpts{})
+	env.CommitIntent("create " + a.ID + "\ncreate " + b.ID)
+	env.Store.Delete(a.ID)
+	env.CommitIntent("delete " + a.ID)
+
+	deleted, err := env.Store.Deleted()
+	if err != nil {
+		t.Fatalf("Deleted: %v", err)
+	}
+	if len(deleted) != 1 || deleted[0].ID != a.ID {
+		t.Fatalf("Deleted = %+v, want only %s", deleted, a.ID)
+	}
+	if deleted[0].Title != "Gone" || deleted[0].DeletedAt == "" || deleted[0].DeletedIn == "" {
+		t.Errorf("deleted entry = %+v", deleted[0])
+	}
+}
+
+func TestUndeleteRestoresIssueAndMarkers(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	parent, _ := env.Store.Create("Epic", issue.CreateOpts{})
+	a, _ := env.Store.Create("Gone", issue.CreateOpts{Parent: parent.ID})
+	b, _ := env.Store.Create("Waits", issue.CreateOpts{})
+	child, _ := env.Store.Create("Child", issue.CreateOpts{Parent: a.ID})
+	env.Store.Label(a.ID, []string{"bug"}, nil)
+	env.Store.Comment(a.ID, "note", "alice")
+	env.Store.Link(a.ID, b.ID)
+	env.CommitIntent("setup")
+	env.Store.Delete(a.ID)
+	env.CommitIntent("delete " + a.ID)
+
+	if got, _ := env.Store.Get(child.ID); got.Parent != "" {
+		t.Fatalf("child parent after delete = %q, want empty", got.Parent)
+	}
+
+	iss, dropped, err := env.Store.Undelete(a.ID)
+	if err != nil {
+		t.Fatalf("Undelete: %v", err)
+	}
+	if len(dropped) != 0 {
+		t.Errorf("dropped = %v, want none", dropped)
+	}
+	if iss.Title != "Gone" || iss.Parent != parent.ID || len(iss.Comments) != 1 {
+		t.Errorf("restored issue = %+v", iss)
+	}
+	if len(iss.Blocks) != 1 || iss.Blocks[0] != b.ID {
+		t.Errorf("Blocks = %v, want [%s]", iss.Blocks, b.ID)
+	}
+	if got, _ := env.Store.Get(b.ID); len(got.BlockedBy) != 1 || got.BlockedBy[0] != a.ID {
+		t.Errorf("%s BlockedBy = %v, want [%s]", b.ID, got.BlockedBy, a.ID)
+	}
+	if got, _ := env.Store.Get(child.ID); got.Parent != a.ID {
+		t.Errorf("child parent = %q, want %s", got.Parent, a.ID)
+	}
+	for _, marker := range []string{
+		"status/open/" + a.ID,
+		"labels/bug/" + a.ID,
+		"blocks/" + a.ID + "/" + b.ID,
+	} {
+		if !env.MarkerExists(marker) {
+			t.Errorf("missing marker %s", marker)
+		}
+	}
+}
+
+func TestUndeleteDropsLinksToDeletedIssues(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	a, _ := env.Store.Create("Gone", issue.CreateOpts{})
+	b, _ := env.Store.Create("Also gone", issue.CreateOpts{})
+	env.Store.Link(a.ID, b.ID)
+	env.Store.Relate(issue.RelatesTo, a.ID, b.ID)
+	env.CommitIntent("setup")
+	env.Store.Delete(a.ID)
+	env.CommitIntent("delete " + a.ID)
+	env.Store.Delete(b.ID)
+	env.CommitIntent("delete " + b.ID)
+
+	iss, dropped, err := env.Store.Undelete(a.ID)
+	if err != nil {
+		t.Fatalf("Undelete: %v", err)
+	}
+	if len(dropped) != 2 {
+		t.Errorf("dropped = %v, want the blocks and relates-to links", dropped)
+	}
+	if len(iss.Blocks) != 0 || len(iss.RelatesTo) != 0 {
+		t.Errorf("restored links = %v %v, want none", iss.Blocks, iss.RelatesTo)
+	}
+}
+
+func TestUndeleteRestoresAttachments(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	a, _ := env.Store.Create("Gone", issue.CreateOpts{})
+	env.Store.Attach(a.ID, "notes.md", []byte("hello"))
+	env.CommitIntent("create " + a.ID + "\nattach " + a.ID + " notes.md")
+	env.Store.Delete(a.ID)
+	env.Repo.TreeFS().Remove("attachments/" + a.ID + "/notes.md")
+	env.CommitIntent("delete " + a.ID)
+
+	if _, _, err := env.Store.Undelete(a.ID); err != nil {
+		t.Fatalf("Undelete: %v", err)
+	}
+	data, err := env.Store.GetAttachment(a.ID, "notes.md")
+	if err != nil || string(data) != "hello" {
+		t.Errorf("attachment = %q, %v; want hello", data, err)
+	}
+}
+
+func TestUndeleteRefusesExistingIssue(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	a, _ := env.Store.Create("Here", issue.CreateOpts{})
+	env.CommitIntent("create " + a.ID)
+
+	if _, _, err := env.Store.Undelete(a.ID); err == nil {
+		t.Error("expected error undeleting an existing issue")
+	}
+	if _, _, err := env.Store.Undelete("test-zzzz"); err == nil {
+		t.Error("expected error for an issue that was never deleted")
+	}
+}
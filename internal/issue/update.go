@@ -36,6 +36,12 @@ func (s *Store) Update(id string, opts UpdateOpts) (*Issue, error) {
 	if opts.Due != nil {
 		issue.Due = *opts.Due
 	}
+	if opts.Every != nil {
+		issue.Every = *opts.Every
+	}
+	if err := s.applyFields(issue, opts.Fields); err != nil {
+		return nil, err
+	}
 	if opts.Parent != nil {
 		if *opts.Parent != "" {
 			if *opts.Parent == id {
@@ -66,10 +72,14 @@ func (s *Store) Update(id string, opts UpdateOpts) (*Issue, error) {
 		oldStatus := issue.Status
 		newStatus := *opts.Status
 		if oldStatus != newStatus {
+			if err := s.CurrentWorkflow().CheckTransition(oldStatus, newStatus); err != nil {
+				return nil, fmt.Errorf("%s: %w", id, err)
+			}
 			if err := s.moveStatus(id, oldStatus, newStatus); err != nil {
 				return nil, err
 			}
 			issue.Status = newStatus
+			issue.Lease = nil
 		}
 	}
 
@@ -92,12 +102,16 @@ func (s *Store) Close(id, reason string) (*Issue, error) {
 	if issue.Status == "closed" {
 		return nil, fmt.Errorf("%s is already closed", id)
 	}
+	if err := s.CurrentWorkflow().CheckTransition(issue.Status, "closed"); err != nil {
+		return nil, fmt.Errorf("%s: %w", id, err)
+	}
 
 	if err := s.moveStatus(id, issue.Status, "closed"); err != nil {
 		return nil, err
 	}
 	now := s.nowRFC3339()
 	issue.Status = "closed"
+	issue.Lease = nil
 	issue.ClosedAt = now
 	issue.CloseReason = reason
 	issue.UpdatedAt = now
@@ -116,18 +130,21 @@ func (s *Store) Reopen(id string) (*Issue, error) {
 	if err != nil {
 		return nil, err
 	}
-	switch issue.Status {
-	case "closed", "in_progress":
-		// ok
-	default:
+	// Reopen undoes resolution or a claim: done statuses, and active ones
+	// other than open itself.
+	if !s.isDone(issue.Status) && (issue.Status == "open" || s.CurrentWorkflow().Category(issue.Status) != CategoryActive) {
 		return nil, fmt.Errorf("%s is %s, not closed or in_progress", id, issue.Status)
 	}
+	if err := s.CurrentWorkflow().CheckTransition(issue.Status, "open"); err != nil {
+		return nil, fmt.Errorf("%s: %w", id, err)
+	}
 
 	if err := s.moveStatus(id, issue.Status, "open"); err != nil {
 		return nil, err
 	}
 	issue.Status = "open"
 	issue.Assignee = ""
+	issue.Lease = nil
 	issue.ClosedAt = ""
 	issue.CloseReason = ""
 	issue.UpdatedAt = s.nowRFC3339()
@@ -150,44 +167,5 @@ func (e *BlockedError) Error() string {
 // Start transitions an open issue to in_progress and sets its assignee.
 // Returns a BlockedError if the issue has unresolved blockers.
 func (s *Store) Start(id, assignee string) (*Issue, error) {
-	id, err := s.resolveID(id)
-	if err != nil {
-		return nil, err
-	}
-	iss, err := s.readIssue(id)
-	if err != nil {
-		return nil, err
-	}
-	if iss.Status != "open" {
-		return nil, fmt.Errorf("%s is %s, not open", id, iss.Status)
-	}
-
-	// Check for open blockers
-	if len(iss.BlockedBy) > 0 {
-		var open []string
-		for _, blockerID := range iss.BlockedBy {
-			blocker, err := s.readIssue(blockerID)
-			if err != nil {
-				open = append(open, blockerID)
-				continue
-			}
-			if blocker.Status != "closed" {
-				open = append(open, blockerID)
-			}
-		}
-		if len(open) > 0 {
-			return nil, &BlockedError{ID: id, Blockers: open}
-		}
-	}
-
-	if err := s.moveStatus(id, "open", "in_progress"); err != nil {
-		return nil, err
-	}
-	iss.Status = "in_progress"
-	iss.Assignee = assignee
-	iss.UpdatedAt = s.nowRFC3339()
-	if err := s.writeIssue(iss); err != nil {
-		return nil, err
-	}
-	return iss, nil
+	return s.Claim(id, ClaimOpts{Assignee: assignee})
 }
@@ -0,0 +1,87 @@
+package issue
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// View is a named, saved set of bw list arguments. Views live on the
+// beadwork branch as views/<name>, a JSON array of the arguments, so
+// they sync to every clone.
+type View struct {
+	Name string   `json:"name"`
+	Args []string `json:"args"`
+}
+
+var viewNameRe = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// ValidateViewName reports whether name may be used for a saved view.
+func ValidateViewName(name string) error {
+	if !viewNameRe.MatchString(name) {
+		return fmt.Errorf("invalid view name %q (use lowercase letters, digits, - and _)", name)
+	}
+	return nil
+}
+
+// SaveView creates or replaces the view name.
+func (s *Store) SaveView(name string, args []string) error {
+	if err := ValidateViewName(name); err != nil {
+		return err
+	}
+	if args == nil {
+		args = []string{}
+	}
+	data, err := json.Marshal(args)
+	if err != nil {
+		return err
+	}
+	s.FS.MkdirAll("views")
+	return s.FS.WriteFile("views/"+name, append(data, '\n'))
+}
+
+// GetView returns the saved view name.
+func (s *Store) GetView(name string) (*View, error) {
+	data, err := s.FS.ReadFile("views/" + name)
+	if err != nil {
+		return nil, fmt.Errorf("no such view: %s", name)
+	}
+	v := &View{Name: name}
+	if err := json.Unmarshal(data, &v.Args); err != nil {
+		return nil, fmt.Errorf("view %s: %w", name, err)
+	}
+	return v, nil
+}
+
+// Views returns every saved view, sorted by name.
+func (s *Store) Views() ([]*View, error) {
+	entries, err := s.FS.ReadDir("views")
+	if err != nil {
+		return nil, nil
+	}
+	var names []string
+	for _, e := range entries {
+		if e.Name() != ".gitkeep" {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	views := make([]*View, 0, len(names))
+	for _, name := range names {
+		v, err := s.GetView(name)
+		if err != nil {
+			return nil, err
+		}
+		views = append(views, v)
+	}
+	return views, nil
+}
+
+// DeleteView removes the view name.
+func (s *Store) DeleteView(name string) error {
+	if _, err := s.FS.Stat("views/" + name); err != nil {
+		return fmt.Errorf("no such view: %s", name)
+	}
+	return s.FS.Remove("views/" + name)
+}
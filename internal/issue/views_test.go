@@ -0,0 +1,67 @@
+package issue_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jallum/beadwork/internal/issue"
+	"github.com/jallum/beadwork/internal/testutil"
+)
+
+func TestViewsSaveListDelete(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	if views, _ := env.Store.Views(); len(views) != 0 {
+		t.Fatalf("Views = %v, want none", views)
+	}
+	if err := env.Store.SaveView("triage", []string{"--query", "assignee:none", "--all"}); err != nil {
+		t.Fatalf("SaveView: %v", err)
+	}
+	if err := env.Store.SaveView("bugs", []string{"--type", "bug"}); err != nil {
+		t.Fatalf("SaveView: %v", err)
+	}
+	env.Repo.Commit("views")
+
+	views, err := env.Store.Views()
+	if err != nil {
+		t.Fatalf("Views: %v", err)
+	}
+	if len(views) != 2 || views[0].Name != "bugs" || views[1].Name != "triage" {
+		t.Fatalf("Views = %+v", views)
+	}
+	if !reflect.DeepEqual(views[1].Args, []string{"--query", "assignee:none", "--all"}) {
+		t.Errorf("triage args = %q", views[1].Args)
+	}
+
+	// Saving again replaces the view.
+	env.Store.SaveView("bugs", []string{"--type", "bug", "--all"})
+	v, _ := env.Store.GetView("bugs")
+	if len(v.Args) != 3 {
+		t.Errorf("bugs args = %q", v.Args)
+	}
+
+	if err := env.Store.DeleteView("bugs"); err != nil {
+		t.Fatalf("DeleteView: %v", err)
+	}
+	if _, err := env.Store.GetView("bugs"); err == nil {
+		t.Error("GetView after delete: expected error")
+	}
+	if err := env.Store.DeleteView("bugs"); err == nil {
+		t.Error("DeleteView twice: expected error")
+	}
+}
+
+func TestSaveViewRejectsBadNames(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	for _, name := range []string{"", "My View", "../x", "-x"} {
+		if err := env.Store.SaveView(name, nil); err == nil {
+			t.Errorf("SaveView(%q): expected error", name)
+		}
+	}
+	if err := issue.ValidateViewName("on-call_2"); err != nil {
+		t.Errorf("ValidateViewName: %v", err)
+	}
+}
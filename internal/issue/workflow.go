@@ -0,0 +1,243 @@
+package issue
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Workflow is the set of statuses a repo uses and the transitions allowed
+// between them. It is declared in .bwconfig:
+//
+//	status.review=active            declare a custom status and its category
+//	status.review.icon=◎            display icon (optional, any status)
+//	transitions.open=in_progress,review,deferred,closed
+//
+// A status with a transitions entry may only move to the listed targets;
+// statuses without one may move anywhere. With no status.* or
+// transitions.* keys the workflow is DefaultWorkflow, which accepts any
+// status change exactly as before.
+type Workflow struct {
+	Statuses    []StatusInfo
+	Transitions map[string][]string
+
+	configured bool
+}
+
+// DefaultWorkflow is the workflow of a repo with no workflow config.
+var DefaultWorkflow = &Workflow{Statuses: Statuses}
+
+var statusNameRe = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// customIcon is shown for custom statuses that don't declare an icon.
+const customIcon = "•"
+
+// ParseWorkflow builds a Workflow from repo config key/value pairs.
+// Unrelated keys are ignored.
+func ParseWorkflow(cfg map[string]string) (*Workflow, error) {
+	builtin := make(map[string]StatusInfo, len(Statuses))
+	for _, s := range Statuses {
+		builtin[s.Name] = s
+	}
+
+	custom := make(map[string]StatusInfo)
+	icons := make(map[string]string)
+	rawTransitions := make(map[string]string)
+	configured := false
+
+	for key, val := range cfg {
+		switch {
+		case strings.HasPrefix(key, "status."):
+			configured = true
+			rest := strings.TrimPrefix(key, "status.")
+			if name, ok := strings.CutSuffix(rest, ".icon"); ok {
+				icons[name] = val
+				continue
+			}
+			if !statusNameRe.MatchString(rest) {
+				return nil, fmt.Errorf("%s: invalid status name %q", key, rest)
+			}
+			if b, ok := builtin[rest]; ok {
+				if val != b.Category {
+					return nil, fmt.Errorf("%s: built-in status %s is always %s", key, rest, b.Category)
+				}
+				continue
+			}
+			switch val {
+			case CategoryActive, CategoryWaiting, CategoryDone:
+			default:
+				return nil, fmt.Errorf("%s: category must be %s, %s, or %s", key, CategoryActive, CategoryWaiting, CategoryDone)
+			}
+			custom[rest] = StatusInfo{Name: rest, Icon: customIcon, Category: val}
+		case strings.HasPrefix(key, "transitions."):
+			configured = true
+			rawTransitions[strings.TrimPrefix(key, "transitions.")] = val
+		}
+	}
+	if !configured {
+		return DefaultWorkflow, nil
+	}
+
+	// Order: built-ins, each followed by the custom statuses of its
+	// category (alphabetically), so open/in_progress/review/... reads
+	// naturally in legends and listings.
+	var customNames []string
+	for name := range custom {
+		customNames = append(customNames, name)
+	}
+	sort.Strings(customNames)
+	w := &Workflow{configured: true, Transitions: make(map[string][]string)}
+	for _, b := range Statuses {
+		w.Statuses = append(w.Statuses, b)
+		if b.Name == "open" {
+			continue // open is the entry state; customs follow in_progress
+		}
+		for _, name := range customNames {
+			if custom[name].Category == b.Category {
+				w.Statuses = append(w.Statuses, custom[name])
+			}
+		}
+	}
+
+	for name, icon := range icons {
+		i := w.index(name)
+		if i < 0 {
+			return nil, fmt.Errorf("status.%s.icon: unknown status %q", name, name)
+		}
+		w.Statuses[i].Icon = icon
+	}
+
+	for from, val := range rawTransitions {
+		if w.index(from) < 0 {
+			return nil, fmt.Errorf("transitions.%s: unknown status %q", from, from)
+		}
+		targets := []string{}
+		for _, to := range strings.Split(val, ",") {
+			to = strings.TrimSpace(to)
+			if to == "" {
+				continue
+			}
+			if w.index(to) < 0 {
+				return nil, fmt.Errorf("transitions.%s: unknown status %q", from, to)
+			}
+			targets = append(targets, to)
+		}
+		w.Transitions[from] = targets
+	}
+	return w, nil
+}
+
+func (w *Workflow) index(name string) int {
+	for i, s := range w.Statuses {
+		if s.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Names returns all status names in display order.
+func (w *Workflow) Names() []string {
+	names := make([]string, len(w.Statuses))
+	for i, s := range w.Statuses {
+		names[i] = s.Name
+	}
+	return names
+}
+
+// NamesIn returns the status names in the given category, in display order.
+func (w *Workflow) NamesIn(category string) []string {
+	var names []string
+	for _, s := range w.Statuses {
+		if s.Category == category {
+			names = append(names, s.Name)
+		}
+	}
+	return names
+}
+
+// Icon returns the display icon for status, or "?" if it is unknown.
+func (w *Workflow) Icon(status string) string {
+	if i := w.index(status); i >= 0 {
+		return w.Statuses[i].Icon
+	}
+	return "?"
+}
+
+// Icons maps every status to its display icon.
+func (w *Workflow) Icons() map[string]string {
+	icons := make(map[string]string, len(w.Statuses))
+	for _, s := range w.Statuses {
+		icons[s.Name] = s.Icon
+	}
+	return icons
+}
+
+// Category returns the category of status, or "" if it is unknown.
+func (w *Workflow) Category(status string) string {
+	if i := w.index(status); i >= 0 {
+		return w.Statuses[i].Category
+	}
+	return ""
+}
+
+// CheckTransition reports whether an issue may move from one status to
+// another. The default workflow allows every change.
+func (w *Workflow) CheckTransition(from, to string) error {
+	if !w.configured || from == to {
+		return nil
+	}
+	if w.index(to) < 0 {
+		return fmt.Errorf("unknown status %q (known: %s)", to, strings.Join(w.Names(), ", "))
+	}
+	allowed, ok := w.Transitions[from]
+	if !ok || containsStr(allowed, to) {
+		return nil
+	}
+	if len(allowed) == 0 {
+		return fmt.Errorf("cannot move from %s to %s: %s has no allowed transitions", from, to, from)
+	}
+	return fmt.Errorf("cannot move from %s to %s (allowed: %s)", from, to, strings.Join(allowed, ", "))
+}
+
+// CurrentWorkflow returns the store's workflow, or DefaultWorkflow when
+// none is configured.
+func (s *Store) CurrentWorkflow() *Workflow {
+	if s.Workflow != nil {
+		return s.Workflow
+	}
+	return DefaultWorkflow
+}
+
+// isDone reports whether status resolves blockers.
+func (s *Store) isDone(status string) bool {
+	return s.CurrentWorkflow().Category(status) == CategoryDone
+}
+
+// isClaimed reports whether status is work someone has picked up: an
+// active status other than open. in_review is honored even when it is not
+// declared, as it always has been.
+func (s *Store) isClaimed(status string) bool {
+	if status == "in_review" {
+		return true
+	}
+	return status != "open" && s.CurrentWorkflow().Category(status) == CategoryActive
+}
+
+// unresolvedStatuses returns every status whose issues are still live
+// (not done). The legacy in_review is listed after the active statuses
+// when the workflow doesn't declare it.
+func (s *Store) unresolvedStatuses() []string {
+	w := s.CurrentWorkflow()
+	var names []string
+	for _, st := range w.Statuses {
+		if st.Category == CategoryActive {
+			names = append(names, st.Name)
+		}
+	}
+	if w.index("in_review") < 0 {
+		names = append(names, "in_review")
+	}
+	return append(names, w.NamesIn(CategoryWaiting)...)
+}
@@ -0,0 +1,190 @@
+package issue_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jallum/beadwork/internal/issue"
+	"github.com/jallum/beadwork/internal/testutil"
+)
+
+func teamWorkflow(t *testing.T) *issue.Workflow {
+	t.Helper()
+	wf, err := issue.ParseWorkflow(map[string]string{
+		"prefix":                  "test",
+		"status.review":           "active",
+		"status.review.icon":      "◎",
+		"status.qa":               "active",
+		"status.blocked-external": "waiting",
+		"status.wontfix":          "done",
+		"transitions.open":        "in_progress,deferred,closed,wontfix",
+		"transitions.in_progress": "review,open",
+		"transitions.review":      "qa,in_progress",
+		"transitions.qa":          "closed,in_progress",
+	})
+	if err != nil {
+		t.Fatalf("ParseWorkflow: %v", err)
+	}
+	return wf
+}
+
+func TestParseWorkflowDefault(t *testing.T) {
+	wf, err := issue.ParseWorkflow(map[string]string{"prefix": "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wf != issue.DefaultWorkflow {
+		t.Error("expected DefaultWorkflow with no workflow keys")
+	}
+	if err := wf.CheckTransition("closed", "whatever"); err != nil {
+		t.Errorf("default workflow should allow any transition: %v", err)
+	}
+}
+
+func TestParseWorkflowOrderAndIcons(t *testing.T) {
+	wf := teamWorkflow(t)
+	want := []string{"open", "in_progress", "qa", "review", "deferred", "blocked-external", "closed", "wontfix"}
+	if got := wf.Names(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Names = %v, want %v", got, want)
+	}
+	if got := wf.Icon("review"); got != "◎" {
+		t.Errorf("Icon(review) = %q", got)
+	}
+	if got := wf.Icon("qa"); got != "•" {
+		t.Errorf("Icon(qa) = %q", got)
+	}
+	if got := wf.Icons(); got["review"] != "◎" || got["open"] != "○" {
+		t.Errorf("Icons = %v", got)
+	}
+	if got := wf.Category("blocked-external"); got != issue.CategoryWaiting {
+		t.Errorf("Category(blocked-external) = %q", got)
+	}
+}
+
+func TestParseWorkflowErrors(t *testing.T) {
+	for _, cfg := range []map[string]string{
+		{"status.review": "pending"},
+		{"status.Bad Name": "active"},
+		{"status.closed": "active"},
+		{"status.ghost.icon": "x"},
+		{"transitions.open": "nowhere"},
+		{"transitions.nowhere": "open"},
+	} {
+		if _, err := issue.ParseWorkflow(cfg); err == nil {
+			t.Errorf("ParseWorkflow(%v): expected error", cfg)
+		}
+	}
+}
+
+func TestCheckTransition(t *testing.T) {
+	wf := teamWorkflow(t)
+	tests := []struct {
+		from, to string
+		ok       bool
+	}{
+		{"open", "in_progress", true},
+		{"in_progress", "review", true},
+		{"in_progress", "closed", false},
+		{"review", "qa", true},
+		{"qa", "closed", true},
+		{"open", "bogus", false},
+		{"closed", "open", true}, // no transitions entry: unrestricted
+		{"review", "review", true},
+	}
+	for _, tt := range tests {
+		err := wf.CheckTransition(tt.from, tt.to)
+		if (err == nil) != tt.ok {
+			t.Errorf("CheckTransition(%s, %s) = %v, want ok=%v", tt.from, tt.to, err, tt.ok)
+		}
+	}
+}
+
+func TestWorkflowEnforcedByStore(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+	env.Store.Workflow = teamWorkflow(t)
+
+	iss, _ := env.Store.Create("Feature", issue.CreateOpts{})
+	if _, err := env.Store.Start(iss.ID, "alice"); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if _, err := env.Store.Close(iss.ID, ""); err == nil || !strings.Contains(err.Error(), "allowed: review, open") {
+		t.Errorf("Close from in_progress: err = %v", err)
+	}
+	review := "review"
+	if _, err := env.Store.Update(iss.ID, issue.UpdateOpts{Status: &review}); err != nil {
+		t.Fatalf("Update to review: %v", err)
+	}
+	closed := "closed"
+	if _, err := env.Store.Update(iss.ID, issue.UpdateOpts{Status: &closed}); err == nil {
+		t.Error("expected review -> closed to be refused")
+	}
+	if _, err := env.Store.Reopen(iss.ID); err == nil {
+		t.Error("expected review -> open to be refused")
+	}
+	qa := "qa"
+	if _, err := env.Store.Update(iss.ID, issue.UpdateOpts{Status: &qa}); err != nil {
+		t.Fatalf("Update to qa: %v", err)
+	}
+	if _, err := env.Store.Close(iss.ID, "shipped"); err != nil {
+		t.Fatalf("Close from qa: %v", err)
+	}
+}
+
+func TestWorkflowCategoriesDriveReadyAndBlocked(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+	env.Store.Workflow = teamWorkflow(t)
+
+	blocker, _ := env.Store.Create("Blocker", issue.CreateOpts{})
+	inReview, _ := env.Store.Create("In review", issue.CreateOpts{})
+	parked, _ := env.Store.Create("Parked", issue.CreateOpts{})
+	env.Store.Link(blocker.ID, inReview.ID)
+
+	for id, path := range map[string][]string{
+		inReview.ID: {"in_progress", "review"},
+		parked.ID:   {"in_progress", "open"},
+	} {
+		for _, st := range path {
+			if _, err := env.Store.Update(id, issue.UpdateOpts{Status: &st}); err != nil {
+				t.Fatalf("Update %s to %s: %v", id, st, err)
+			}
+		}
+	}
+	ext := "blocked-external"
+	// open has a transitions entry without blocked-external; go via a
+	// status with no entry.
+	deferred := "deferred"
+	env.Store.Update(parked.ID, issue.UpdateOpts{Status: &deferred})
+	if _, err := env.Store.Update(parked.ID, issue.UpdateOpts{Status: &ext}); err != nil {
+		t.Fatalf("Update to blocked-external: %v", err)
+	}
+
+	// Active custom statuses show up in Blocked.
+	blocked, _ := env.Store.Blocked()
+	if len(blocked) != 1 || blocked[0].ID != inReview.ID {
+		t.Errorf("Blocked = %v, want [%s]", blocked, inReview.ID)
+	}
+
+	// Waiting statuses are never ready.
+	ready, _ := env.Store.Ready()
+	for _, r := range ready {
+		if r.ID == parked.ID {
+			t.Error("blocked-external issue should not be ready")
+		}
+	}
+
+	// A done-category status resolves blockers.
+	wontfix := "wontfix"
+	if _, err := env.Store.Update(blocker.ID, issue.UpdateOpts{Status: &wontfix}); err != nil {
+		t.Fatalf("Update to wontfix: %v", err)
+	}
+	if !env.Store.IsClosed(blocker.ID) {
+		t.Error("wontfix should count as closed")
+	}
+	blocked, _ = env.Store.Blocked()
+	if len(blocked) != 0 {
+		t.Errorf("Blocked after wontfix = %v, want none", blocked)
+	}
+}
@@ -22,7 +22,8 @@ func Escape(s string) string {
 }
 
 // IssueSummary returns a # heading line with status, id, optional type tag,
-// and title, followed by optional Due:, Deferred:, Parent:, and Labels: lines.
+// and title, followed by optional Due:, Deferred:, Every:, Parent:, Labels:,
+// and Fields: lines.
 // The now parameter is used for overdue detection.
 func IssueSummary(iss *issue.Issue, now time.Time) string {
 	var b strings.Builder
@@ -53,6 +54,22 @@ func IssueSummary(iss *issue.Issue, now time.Time) string {
 			b.WriteString(" (expired — now due for attention)")
 		}
 	}
+	if iss.Every != "" {
+		b.WriteString("\nEvery: ")
+		b.WriteString(Escape(iss.Every))
+	}
+	if iss.Lease != nil {
+		b.WriteString("\nLease: ")
+		b.WriteString(Escape(iss.Assignee))
+		if iss.Lease.Agent != "" {
+			b.WriteString(" (" + iss.Lease.Agent + ")")
+		}
+		b.WriteString(" until ")
+		b.WriteString(formatDateDisplay(iss.Lease.Expires))
+		if issue.IsReclaimable(iss, now) {
+			b.WriteString(" (expired — can be reclaimed)")
+		}
+	}
 	if iss.Parent != "" {
 		b.WriteString("\nParent: ")
 		b.WriteString(iss.Parent)
@@ -61,6 +78,14 @@ func IssueSummary(iss *issue.Issue, now time.Time) string {
 		b.WriteString("\nLabels: ")
 		b.WriteString(strings.Join(iss.Labels, ", "))
 	}
+	if len(iss.Fields) > 0 {
+		var kvs []string
+		for _, name := range issue.SortedFieldNames(iss.Fields) {
+			kvs = append(kvs, name+"="+Escape(iss.Fields[name]))
+		}
+		b.WriteString("\nFields: ")
+		b.WriteString(strings.Join(kvs, ", "))
+	}
 
 	return b.String()
 }
@@ -114,6 +139,9 @@ func IssueOneLinerWithDue(iss *issue.Issue, now time.Time, closedBlockers map[st
 	if iss.Status == "deferred" && issue.IsDeferralExpired(iss.DeferUntil, now) {
 		suffixes = append(suffixes, "(deferred until "+formatDateDisplay(iss.DeferUntil)+", now due)")
 	}
+	if issue.IsReclaimable(iss, now) {
+		suffixes = append(suffixes, "(lease expired "+formatDateDisplay(iss.Lease.Expires)+", reclaimable)")
+	}
 	if len(suffixes) > 0 {
 		return line + " " + strings.Join(suffixes, " ")
 	}
@@ -222,6 +250,64 @@ func Unblocks(deps []*issue.Issue) string {
 	return b.String()
 }
 
+// Related returns a ## <heading> section listing related issues (e.g.
+// RELATED, DUPLICATED BY). Returns "" if issues is empty.
+func Related(heading string, issues []*issue.Issue) string {
+	if len(issues) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("## ")
+	b.WriteString(heading)
+	b.WriteByte('\n')
+	for _, iss := range issues {
+		b.WriteString("\n- ")
+		b.WriteString(statusToken(iss.Status))
+		b.WriteByte(' ')
+		b.WriteString(idToken(iss.ID))
+		b.WriteByte(' ')
+		b.WriteString(priorityToken(iss.Priority))
+		b.WriteByte(' ')
+		b.WriteString(Escape(iss.Title))
+	}
+	return b.String()
+}
+
+// CommitRef is a code commit listed in a COMMITS section.
+type CommitRef struct {
+	Hash    string
+	Subject string
+	Time    time.Time
+	Closes  bool // the commit carries a Closes: trailer for the issue
+}
+
+// Commits returns a ## COMMITS section listing code commits that mention
+// an issue, one per line with short hash and date. Returns "" if commits
+// is empty.
+func Commits(commits []CommitRef) string {
+	if len(commits) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("## COMMITS\n")
+	for _, c := range commits {
+		b.WriteString("\n- ")
+		hash := c.Hash
+		if len(hash) > 7 {
+			hash = hash[:7]
+		}
+		b.WriteString(hash)
+		b.WriteByte(' ')
+		b.WriteString(c.Time.Format("2006-01-02"))
+		b.WriteByte(' ')
+		b.WriteString(Escape(c.Subject))
+		if c.Closes {
+			b.WriteString(" (closes)")
+		}
+	}
+	return b.String()
+}
+
 // Comments returns a ## COMMENTS section with author+timestamp headers
 // and blockquoted text. Returns "" if comments is empty.
 func Comments(comments []issue.Comment) string {
@@ -89,6 +89,20 @@ func TestIssueSummaryWithParentAndLabels(t *testing.T) {
 	}
 }
 
+func TestIssueSummaryWithFields(t *testing.T) {
+	iss := &issue.Issue{
+		ID:     "bw-123",
+		Title:  "Crash on login",
+		Status: "open",
+		Type:   "bug",
+		Fields: map[string]string{"severity": "high", "estimate": "3"},
+	}
+	got := IssueSummary(iss, testNow)
+	if !strings.Contains(got, "Fields: estimate=3, severity=high") {
+		t.Errorf("should contain sorted Fields line: got %q", got)
+	}
+}
+
 func TestIssueSummaryNoParentNoLabels(t *testing.T) {
 	iss := &issue.Issue{
 		ID:     "bw-456",
@@ -103,6 +117,9 @@ func TestIssueSummaryNoParentNoLabels(t *testing.T) {
 	if strings.Contains(got, "Labels:") {
 		t.Errorf("should not contain Labels line when empty: got %q", got)
 	}
+	if strings.Contains(got, "Fields:") {
+		t.Errorf("should not contain Fields line when empty: got %q", got)
+	}
 }
 
 func TestIssueOneLiner(t *testing.T) {
@@ -270,6 +287,25 @@ func TestUnblocksEmpty(t *testing.T) {
 	}
 }
 
+func TestCommits(t *testing.T) {
+	got := Commits([]CommitRef{
+		{Hash: "0123456789abcdef", Subject: "Fix the widget", Time: testNow, Closes: true},
+		{Hash: "fedcba9876543210", Subject: "Start the widget", Time: testNow.AddDate(0, 0, -2)},
+	})
+	if !strings.Contains(got, "## COMMITS") {
+		t.Errorf("should have COMMITS header: got %q", got)
+	}
+	if !strings.Contains(got, "- 0123456 2027-04-16 Fix the widget (closes)") {
+		t.Errorf("closing commit line wrong: got %q", got)
+	}
+	if !strings.HasSuffix(got, "- fedcba9 2027-04-14 Start the widget") {
+		t.Errorf("mentioning commit line wrong: got %q", got)
+	}
+	if Commits(nil) != "" {
+		t.Error("Commits(nil) should be empty")
+	}
+}
+
 func TestComments(t *testing.T) {
 	comments := []issue.Comment{
 		{Timestamp: "2024-01-15T10:00:00Z", Author: "alice", Text: "First comment"},
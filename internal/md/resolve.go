@@ -28,6 +28,19 @@ var statusIcons = map[string]string{
 	"deferred":    "❄",
 }
 
+// StatusIcons maps statuses to the icon rendered for their {status:...}
+// tokens, as declared by a repo's workflow. Statuses it leaves out fall
+// back to the built-in icons; a nil StatusIcons uses only those.
+type StatusIcons map[string]string
+
+func (icons StatusIcons) icon(status string) (string, bool) {
+	if icon, ok := icons[status]; ok {
+		return icon, true
+	}
+	icon, ok := statusIcons[status]
+	return icon, ok
+}
+
 var priorityColors = map[string]string{
 	"0": ansiBrightRed,
 	"1": ansiRed,
@@ -51,6 +64,12 @@ var overdueSimpleRe = regexp.MustCompile(`\{overdue\}`)
 
 // ResolveMarkdown transforms tokenized markdown into clean markdown for agents.
 func ResolveMarkdown(s string) string {
+	return StatusIcons(nil).ResolveMarkdown(s)
+}
+
+// ResolveMarkdown resolves s like the package-level ResolveMarkdown,
+// rendering {status:...} tokens with icons.
+func (icons StatusIcons) ResolveMarkdown(s string) string {
 	s = overdueSimpleRe.ReplaceAllString(s, "(OVERDUE)")
 	s = tokenRe.ReplaceAllStringFunc(s, func(tok string) string {
 		m := tokenRe.FindStringSubmatch(tok)
@@ -60,7 +79,7 @@ func ResolveMarkdown(s string) string {
 		kind, val := m[1], m[2]
 		switch kind {
 		case "status":
-			if icon, ok := statusIcons[val]; ok {
+			if icon, ok := icons.icon(val); ok {
 				return icon
 			}
 			return val
@@ -88,6 +107,12 @@ func ResolveMarkdown(s string) string {
 // ResolveTTY transforms tokenized markdown into ANSI-colored terminal output.
 // Pipeline: resolve tokens → plain text with TTY extras → wrap → colorize.
 func ResolveTTY(s string, width int) string {
+	return StatusIcons(nil).ResolveTTY(s, width)
+}
+
+// ResolveTTY resolves s like the package-level ResolveTTY, rendering
+// {status:...} tokens with icons.
+func (icons StatusIcons) ResolveTTY(s string, width int) string {
 	// Stage 0: Resolve bare {overdue} tokens.
 	s = overdueSimpleRe.ReplaceAllString(s, "\x01overdue\x02OVERDUE\x01end\x02")
 
@@ -100,7 +125,7 @@ func ResolveTTY(s string, width int) string {
 		kind, val := m[1], m[2]
 		switch kind {
 		case "status":
-			if icon, ok := statusIcons[val]; ok {
+			if icon, ok := icons.icon(val); ok {
 				if _, hasColor := statusColors[val]; hasColor {
 					return "\x01status:" + val + "\x02" + icon + "\x01end\x02"
 				}
@@ -334,3 +334,16 @@ func TestResolveTTYFenceDelimitersDim(t *testing.T) {
 		t.Errorf("closing fence should be dim: got %q", lines[2])
 	}
 }
+
+func TestStatusIconsResolve(t *testing.T) {
+	icons := StatusIcons{"open": "★", "review": "◎"}
+	if got := icons.ResolveMarkdown("{status:open} {status:review} {status:closed}"); got != "★ ◎ ✓" {
+		t.Errorf("ResolveMarkdown = %q", got)
+	}
+	if got := icons.ResolveTTY("{status:review}", 80); !strings.Contains(got, "◎") {
+		t.Errorf("ResolveTTY = %q", got)
+	}
+	if got := ResolveMarkdown("{status:open} {status:review}"); got != "○ review" {
+		t.Errorf("built-in icons changed: %q", got)
+	}
+}
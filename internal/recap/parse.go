@@ -19,73 +19,76 @@ var (
 	linkRe      = regexp.MustCompile(`^link\s+(\S+)\s+blocks\s+(\S+)`)
 	unlinkRe    = regexp.MustCompile(`^unlink\s+(\S+)\s+blocks\s+(\S+)`)
 	deleteRe    = regexp.MustCompile(`^delete\s+(\S+)`)
+	undeleteRe  = regexp.MustCompile(`^undelete\s+(\S+)`)
 	labelRe     = regexp.MustCompile(`^label\s+(\S+)`)
 	unblockedRe = regexp.MustCompile(`^unblocked\s+(\S+)$`)
+	recurRe     = regexp.MustCompile(`^recur\s+(\S+)\s+(\S+)\s+until\s+(\S+)$`)
 )
 
-// ParseIntent extracts events from a beadwork commit message.
-// The first line is the primary intent; subsequent lines may contain
-// secondary events (e.g., "unblocked <id>").
+// ParseIntent extracts events from a beadwork commit message. Every line
+// is an intent of its own: most commits have one, but close -r, templates
+// and bw batch put several in one message, and closes add secondary lines
+// such as "unblocked <id>" or "recur <prev> <next> until <date>" (which
+// reports the next instance of a recurring issue as created).
 func ParseIntent(message string, ts time.Time) []Event {
-	lines := strings.Split(strings.TrimSpace(message), "\n")
-	if len(lines) == 0 {
-		return nil
-	}
-
 	var events []Event
-	first := strings.TrimSpace(lines[0])
-
-	switch {
-	case createRe.MatchString(first):
-		m := createRe.FindStringSubmatch(first)
-		detail := strings.TrimSpace(first[len(m[0]):])
-		events = append(events, Event{Type: "create", ID: m[1], Time: ts, Detail: detail})
-	case closeRe.MatchString(first):
-		m := closeRe.FindStringSubmatch(first)
-		detail := strings.TrimSpace(first[len(m[0]):])
-		events = append(events, Event{Type: "close", ID: m[1], Time: ts, Detail: detail})
-	case startRe.MatchString(first):
-		m := startRe.FindStringSubmatch(first)
-		events = append(events, Event{Type: "start", ID: m[1], Time: ts})
-	case updateRe.MatchString(first):
-		m := updateRe.FindStringSubmatch(first)
-		detail := strings.TrimSpace(first[len(m[0]):])
-		events = append(events, Event{Type: "update", ID: m[1], Time: ts, Detail: detail})
-	case reopenRe.MatchString(first):
-		m := reopenRe.FindStringSubmatch(first)
-		events = append(events, Event{Type: "reopen", ID: m[1], Time: ts})
-	case deferRe.MatchString(first):
-		m := deferRe.FindStringSubmatch(first)
-		detail := strings.TrimSpace(first[len(m[0]):])
-		events = append(events, Event{Type: "defer", ID: m[1], Time: ts, Detail: detail})
-	case undeferRe.MatchString(first):
-		m := undeferRe.FindStringSubmatch(first)
-		events = append(events, Event{Type: "undefer", ID: m[1], Time: ts})
-	case commentRe.MatchString(first):
-		m := commentRe.FindStringSubmatch(first)
-		events = append(events, Event{Type: "comment", ID: m[1], Time: ts})
-	case linkRe.MatchString(first):
-		m := linkRe.FindStringSubmatch(first)
-		events = append(events, Event{Type: "link", ID: m[1], Time: ts, Detail: "blocks " + m[2]})
-	case unlinkRe.MatchString(first):
-		m := unlinkRe.FindStringSubmatch(first)
-		events = append(events, Event{Type: "unlink", ID: m[1], Time: ts, Detail: "blocks " + m[2]})
-	case deleteRe.MatchString(first):
-		m := deleteRe.FindStringSubmatch(first)
-		events = append(events, Event{Type: "delete", ID: m[1], Time: ts})
-	case labelRe.MatchString(first):
-		m := labelRe.FindStringSubmatch(first)
-		detail := strings.TrimSpace(first[len(m[0]):])
-		events = append(events, Event{Type: "label", ID: m[1], Time: ts, Detail: detail})
-	}
-
-	// Parse secondary events from lines >= 2 (e.g., "unblocked <id>").
-	for _, line := range lines[1:] {
-		line = strings.TrimSpace(line)
-		if m := unblockedRe.FindStringSubmatch(line); m != nil {
-			events = append(events, Event{Type: "unblocked", ID: m[1], Time: ts})
+	for _, line := range strings.Split(strings.TrimSpace(message), "\n") {
+		if ev, ok := parseLine(strings.TrimSpace(line), ts); ok {
+			events = append(events, ev)
 		}
 	}
-
 	return events
 }
+
+// parseLine extracts the event from a single intent line.
+func parseLine(line string, ts time.Time) (Event, bool) {
+	detail := func(m []string) string {
+		return strings.TrimSpace(line[len(m[0]):])
+	}
+	if m := createRe.FindStringSubmatch(line); m != nil {
+		return Event{Type: "create", ID: m[1], Time: ts, Detail: detail(m)}, true
+	}
+	if m := closeRe.FindStringSubmatch(line); m != nil {
+		return Event{Type: "close", ID: m[1], Time: ts, Detail: detail(m)}, true
+	}
+	if m := startRe.FindStringSubmatch(line); m != nil {
+		return Event{Type: "start", ID: m[1], Time: ts}, true
+	}
+	if m := updateRe.FindStringSubmatch(line); m != nil {
+		return Event{Type: "update", ID: m[1], Time: ts, Detail: detail(m)}, true
+	}
+	if m := reopenRe.FindStringSubmatch(line); m != nil {
+		return Event{Type: "reopen", ID: m[1], Time: ts}, true
+	}
+	if m := deferRe.FindStringSubmatch(line); m != nil {
+		return Event{Type: "defer", ID: m[1], Time: ts, Detail: detail(m)}, true
+	}
+	if m := undeferRe.FindStringSubmatch(line); m != nil {
+		return Event{Type: "undefer", ID: m[1], Time: ts}, true
+	}
+	if m := commentRe.FindStringSubmatch(line); m != nil {
+		return Event{Type: "comment", ID: m[1], Time: ts}, true
+	}
+	if m := linkRe.FindStringSubmatch(line); m != nil {
+		return Event{Type: "link", ID: m[1], Time: ts, Detail: "blocks " + m[2]}, true
+	}
+	if m := unlinkRe.FindStringSubmatch(line); m != nil {
+		return Event{Type: "unlink", ID: m[1], Time: ts, Detail: "blocks " + m[2]}, true
+	}
+	if m := deleteRe.FindStringSubmatch(line); m != nil {
+		return Event{Type: "delete", ID: m[1], Time: ts}, true
+	}
+	if m := undeleteRe.FindStringSubmatch(line); m != nil {
+		return Event{Type: "undelete", ID: m[1], Time: ts}, true
+	}
+	if m := labelRe.FindStringSubmatch(line); m != nil {
+		return Event{Type: "label", ID: m[1], Time: ts, Detail: detail(m)}, true
+	}
+	if m := unblockedRe.FindStringSubmatch(line); m != nil {
+		return Event{Type: "unblocked", ID: m[1], Time: ts}, true
+	}
+	if m := recurRe.FindStringSubmatch(line); m != nil {
+		return Event{Type: "create", ID: m[2], Time: ts, Detail: "recurs " + m[1] + " until " + m[3]}, true
+	}
+	return Event{}, false
+}
@@ -125,6 +125,42 @@ func TestParseIntentUnblockedSecondary(t *testing.T) {
 	}
 }
 
+func TestParseIntentCreateSecondary(t *testing.T) {
+	msg := "create bw-1 p1 epic \"Epic\"\ncreate bw-2 p2 task \"Child\" parent=bw-1"
+	events := ParseIntent(msg, testTime)
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2", len(events))
+	}
+	if events[1].Type != "create" || events[1].ID != "bw-2" {
+		t.Errorf("event[1] = %v", events[1])
+	}
+}
+
+func TestParseIntentRecurSecondary(t *testing.T) {
+	msg := "close bw-1\nrecur bw-1 bw-2 until 2027-03-15"
+	events := ParseIntent(msg, testTime)
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2", len(events))
+	}
+	if events[1].Type != "create" || events[1].ID != "bw-2" || events[1].Detail != "recurs bw-1 until 2027-03-15" {
+		t.Errorf("event[1] = %v", events[1])
+	}
+}
+
+func TestParseIntentBatch(t *testing.T) {
+	msg := "close bw-1\nupdate bw-2 title=\"New\"\nlabel bw-3 +urgent\nlink bw-2 blocks bw-3\nstart bw-4"
+	events := ParseIntent(msg, testTime)
+	want := []string{"close bw-1", "update bw-2", "label bw-3", "link bw-2", "start bw-4"}
+	if len(events) != len(want) {
+		t.Fatalf("got %d events, want %d: %v", len(events), len(want), events)
+	}
+	for i, e := range events {
+		if got := e.Type + " " + e.ID; got != want[i] {
+			t.Errorf("event[%d] = %s, want %s", i, got, want[i])
+		}
+	}
+}
+
 func TestParseIntentReasonContainingUnblocked(t *testing.T) {
 	// A close reason that contains "unblocked" as a word should NOT
 	// produce a spurious unblocked event.
@@ -0,0 +1,84 @@
+package repo
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/jallum/beadwork/internal/recap"
+	"github.com/jallum/beadwork/internal/treefs"
+)
+
+// ResolveAt resolves a point-in-time spec to a commit on the beadwork
+// branch. The spec may be a commit hash or unique prefix (at least 4 hex
+// characters), an RFC3339 timestamp or YYYY-MM-DD date, or a recap-style
+// token (today, yesterday, week, 24h, 7d, ...) which names the start of
+// that window. Times resolve to the newest commit at or before them.
+// Errors do not name the flag the spec came from; callers add it.
+func (r *Repo) ResolveAt(spec string, now time.Time) (plumbing.Hash, error) {
+	commits, err := r.tfs.AllCommits()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	if len(commits) == 0 {
+		return plumbing.ZeroHash, fmt.Errorf("no beadwork history")
+	}
+
+	if isHexPrefix(spec) {
+		var match string
+		for _, c := range commits {
+			if strings.HasPrefix(c.Hash, strings.ToLower(spec)) {
+				if match != "" && match != c.Hash {
+					return plumbing.ZeroHash, fmt.Errorf("ambiguous commit prefix")
+				}
+				match = c.Hash
+			}
+		}
+		if match != "" {
+			return plumbing.NewHash(match), nil
+		}
+	}
+
+	at, err := parseAtTime(spec, now)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	for _, c := range commits {
+		if !c.Time.After(at) {
+			return plumbing.NewHash(c.Hash), nil
+		}
+	}
+	return plumbing.ZeroHash, fmt.Errorf("no beadwork commits at or before %s", at.Format(time.RFC3339))
+}
+
+// TreeFSAt returns a read-only TreeFS over the beadwork tree at hash.
+func (r *Repo) TreeFSAt(hash plumbing.Hash) (*treefs.TreeFS, error) {
+	return treefs.OpenAt(r.tfs.Repo(), hash)
+}
+
+func isHexPrefix(s string) bool {
+	if len(s) < 4 || len(s) > 40 {
+		return false
+	}
+	for _, c := range strings.ToLower(s) {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func parseAtTime(spec string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, spec); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", spec, now.Location()); err == nil {
+		return t, nil
+	}
+	w, err := recap.ParseWindow(strings.Fields(spec), "", now)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected commit, RFC3339 time, YYYY-MM-DD, or window like yesterday, week, 7d")
+	}
+	return w.Start, nil
+}
@@ -0,0 +1,95 @@
+package repo_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jallum/beadwork/internal/issue"
+	"github.com/jallum/beadwork/internal/testutil"
+)
+
+// commitAt creates an issue and commits it with the given clock.
+func commitAt(t *testing.T, env *testutil.Env, title, clock string) string {
+	t.Helper()
+	t.Setenv("BW_CLOCK", clock)
+	iss, err := env.Store.Create(title, issue.CreateOpts{})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	env.CommitIntent("create " + iss.ID)
+	return env.Repo.TreeFS().RefHash().String()
+}
+
+func TestResolveAt(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	friday := commitAt(t, env, "Friday", "2026-10-09T15:00:00Z")
+	monday := commitAt(t, env, "Monday", "2026-10-12T09:00:00Z")
+	now, _ := time.Parse(time.RFC3339, "2026-10-12T12:00:00Z")
+
+	tests := []struct {
+		spec string
+		want string
+	}{
+		{friday[:8], friday},
+		{strings.ToUpper(monday[:10]), monday},
+		{"2026-10-10T00:00:00Z", friday},
+		{"2026-10-12T09:00:00Z", monday},
+		{"2026-10-11", friday},
+		{"today", friday},
+		{"1h", monday},
+		{"24h", friday},
+	}
+	for _, tt := range tests {
+		got, err := env.Repo.ResolveAt(tt.spec, now)
+		if err != nil {
+			t.Errorf("ResolveAt(%q): %v", tt.spec, err)
+			continue
+		}
+		if got.String() != tt.want {
+			t.Errorf("ResolveAt(%q) = %s, want %s", tt.spec, got.String()[:8], tt.want[:8])
+		}
+	}
+}
+
+func TestResolveAtErrors(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	commitAt(t, env, "Only", "2026-10-09T15:00:00Z")
+	now, _ := time.Parse(time.RFC3339, "2026-10-12T12:00:00Z")
+
+	for _, spec := range []string{"1999-01-01", "someday", "deadbeef"} {
+		if _, err := env.Repo.ResolveAt(spec, now); err == nil {
+			t.Errorf("ResolveAt(%q): expected error", spec)
+		}
+	}
+}
+
+func TestTreeFSAtIsReadOnly(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	before := env.Repo.TreeFS().RefHash()
+	commitAt(t, env, "Later", "2026-10-09T15:00:00Z")
+
+	fs, err := env.Repo.TreeFSAt(before)
+	if err != nil {
+		t.Fatalf("TreeFSAt: %v", err)
+	}
+	store := issue.NewStore(fs, env.Repo.Prefix)
+	issues, err := store.List(issue.Filter{})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(issues) != 0 {
+		t.Errorf("got %d issues at init, want 0", len(issues))
+	}
+	if _, err := store.Create("Nope", issue.CreateOpts{}); err == nil {
+		if err := store.Commit("create nope"); err == nil {
+			t.Error("expected commit on snapshot store to fail")
+		}
+	}
+}
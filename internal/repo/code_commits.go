@@ -0,0 +1,255 @@
+package repo
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// codeCursorRef is the directory of per-branch cursors recording how far
+// each code branch has been scanned for issue references. Like the recap
+// cursor, the refs are local-only.
+const codeCursorRef = "refs/beadwork/code-cursor"
+
+// CodeCommit is a commit on a code branch whose message names one or more
+// issues.
+type CodeCommit struct {
+	Hash    string    `json:"hash"`
+	Subject string    `json:"subject"`
+	Author  string    `json:"author"`
+	Time    time.Time `json:"time"`
+	Refs    []string  `json:"refs"`             // every issue ID in the message
+	Closes  []string  `json:"closes,omitempty"` // IDs named in a Closes:/Fixes:/Resolves: trailer
+}
+
+// CommitStat is the short diff stat of a commit.
+type CommitStat struct {
+	Files      int `json:"files"`
+	Insertions int `json:"insertions"`
+	Deletions  int `json:"deletions"`
+}
+
+var closesTrailerRe = regexp.MustCompile(`(?i)^(closes|fixes|resolves):\s*(.*)$`)
+
+// codeCommitsPath is the local cache of scanned matches.
+func (r *Repo) codeCommitsPath() string {
+	return filepath.Join(r.GitDir, "beadwork", "code-commits.json")
+}
+
+// codeBranches returns the tips of the branches scanned for code commits,
+// keyed by branch name: the checked-out branch, plus main and master when
+// they exist. The beadwork branch is never included.
+func (r *Repo) codeBranches() map[string]plumbing.Hash {
+	g := r.tfs.Repo()
+	tips := make(map[string]plumbing.Hash)
+	if head, err := g.Head(); err == nil && head.Name().IsBranch() {
+		tips[head.Name().Short()] = head.Hash()
+	}
+	for _, name := range []string{"main", "master"} {
+		if ref, err := g.Reference(plumbing.NewBranchReferenceName(name), true); err == nil {
+			tips[name] = ref.Hash()
+		}
+	}
+	delete(tips, BranchName)
+	return tips
+}
+
+func (r *Repo) codeCursor(branch string) string {
+	data, err := os.ReadFile(filepath.Join(r.GitDir, codeCursorRef, branch))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+func (r *Repo) setCodeCursor(branch, hash string) error {
+	path := filepath.Join(r.GitDir, codeCursorRef, branch)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(hash+"\n"), 0644)
+}
+
+// CodeCommits returns the code commits that name id, newest first. The
+// branches are scanned incrementally: only commits not reachable from the
+// per-branch cursors are read, and matches accumulate in a local cache.
+func (r *Repo) CodeCommits(id string) ([]CodeCommit, error) {
+	all, err := r.ScanCodeCommits()
+	if err != nil {
+		return nil, err
+	}
+	var out []CodeCommit
+	for _, c := range all {
+		if slices.Contains(c.Refs, id) {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+// ScanCodeCommits brings the cache of code commits up to date with the
+// code branches and returns every cached commit, newest first.
+func (r *Repo) ScanCodeCommits() ([]CodeCommit, error) {
+	var cached []CodeCommit
+	haveCache := false
+	if data, err := os.ReadFile(r.codeCommitsPath()); err == nil {
+		haveCache = json.Unmarshal(data, &cached) == nil
+	}
+
+	tips := r.codeBranches()
+	branches := make([]string, 0, len(tips))
+	for name := range tips {
+		branches = append(branches, name)
+	}
+	sort.Strings(branches)
+
+	args := []string{"log", "--format=%H%x1f%an%x1f%aI%x1f%B%x1e"}
+	stale := false
+	for _, name := range branches {
+		args = append(args, tips[name].String())
+		if r.codeCursor(name) != tips[name].String() {
+			stale = true
+		}
+	}
+	if !stale && haveCache {
+		return cached, nil
+	}
+	if len(branches) == 0 {
+		return cached, nil
+	}
+	if haveCache {
+		// A cursor whose commit is gone (say, after a forced rewrite and gc)
+		// cannot bound the walk; the commits beneath it are rescanned and
+		// deduplicated below.
+		var not []string
+		for _, name := range branches {
+			if c := r.codeCursor(name); c != "" {
+				if _, err := r.tfs.Repo().CommitObject(plumbing.NewHash(c)); err == nil {
+					not = append(not, c)
+				}
+			}
+		}
+		if len(not) > 0 {
+			args = append(args, "--not")
+			args = append(args, not...)
+		}
+	} else {
+		cached = nil
+	}
+
+	out, err := execGit(r.RepoDir(), args...)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, len(cached))
+	for _, c := range cached {
+		seen[c.Hash] = true
+	}
+	idRe := r.issueIDRe()
+	for _, rec := range strings.Split(out, "\x1e") {
+		fields := strings.SplitN(strings.TrimLeft(rec, "\n"), "\x1f", 4)
+		if len(fields) < 4 || seen[fields[0]] {
+			continue
+		}
+		c, ok := parseCodeCommit(fields, idRe)
+		if !ok {
+			continue
+		}
+		seen[c.Hash] = true
+		cached = append(cached, c)
+	}
+	sort.SliceStable(cached, func(i, j int) bool { return cached[i].Time.After(cached[j].Time) })
+
+	data, err := json.Marshal(cached)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.codeCommitsPath()), 0755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(r.codeCommitsPath(), data, 0644); err != nil {
+		return nil, err
+	}
+	for _, name := range branches {
+		if err := r.setCodeCursor(name, tips[name].String()); err != nil {
+			return nil, err
+		}
+	}
+	return cached, nil
+}
+
+// issueIDRe matches this repo's issue IDs, children included.
+func (r *Repo) issueIDRe() *regexp.Regexp {
+	return regexp.MustCompile(`\b` + regexp.QuoteMeta(r.Prefix) + `-[a-z0-9]+(?:\.[0-9]+)*\b`)
+}
+
+// parseCodeCommit builds a CodeCommit from a hash, author, date and message,
+// reporting false when the message names no issue.
+func parseCodeCommit(fields []string, idRe *regexp.Regexp) (CodeCommit, bool) {
+	msg := strings.TrimSpace(fields[3])
+	refs := idRe.FindAllString(msg, -1)
+	if len(refs) == 0 {
+		return CodeCommit{}, false
+	}
+	t, _ := time.Parse(time.RFC3339, fields[2])
+	c := CodeCommit{Hash: fields[0], Author: fields[1], Time: t}
+	c.Subject, _, _ = strings.Cut(msg, "\n")
+	for _, id := range refs {
+		if !slices.Contains(c.Refs, id) {
+			c.Refs = append(c.Refs, id)
+		}
+	}
+	for _, line := range strings.Split(msg, "\n") {
+		if m := closesTrailerRe.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+			for _, id := range idRe.FindAllString(m[2], -1) {
+				if !slices.Contains(c.Closes, id) {
+					c.Closes = append(c.Closes, id)
+				}
+			}
+		}
+	}
+	return c, true
+}
+
+var shortStatRe = regexp.MustCompile(`(\d+) (file|insertion|deletion)`)
+
+// CommitStats returns the short diff stat of each of the given commits.
+func (r *Repo) CommitStats(hashes []string) (map[string]CommitStat, error) {
+	stats := make(map[string]CommitStat, len(hashes))
+	if len(hashes) == 0 {
+		return stats, nil
+	}
+	args := append([]string{"log", "--no-walk=unsorted", "--shortstat", "--format=%x1e%H"}, hashes...)
+	out, err := execGit(r.RepoDir(), args...)
+	if err != nil {
+		return nil, err
+	}
+	for _, rec := range strings.Split(out, "\x1e") {
+		hash, rest, _ := strings.Cut(strings.TrimSpace(rec), "\n")
+		if hash == "" {
+			continue
+		}
+		var st CommitStat
+		for _, m := range shortStatRe.FindAllStringSubmatch(rest, -1) {
+			n, _ := strconv.Atoi(m[1])
+			switch m[2] {
+			case "file":
+				st.Files = n
+			case "insertion":
+				st.Insertions = n
+			case "deletion":
+				st.Deletions = n
+			}
+		}
+		stats[hash] = st
+	}
+	return stats, nil
+}
@@ -0,0 +1,93 @@
+package repo_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jallum/beadwork/internal/testutil"
+)
+
+func TestCodeCommitsFindsMentions(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	env.CodeCommit("a.go", "a", "Fix login redirect (test-abc)")
+	env.CodeCommit("b.go", "b", "Unrelated cleanup")
+	env.CodeCommit("c.go", "c\nc\n", "Finish the redirect work\n\nRefs: test-xyz\nCloses: test-abc")
+	env.CodeCommit("d.go", "d", "Child work for test-abc.1")
+
+	commits, err := env.Repo.CodeCommits("test-abc")
+	if err != nil {
+		t.Fatalf("CodeCommits: %v", err)
+	}
+	if len(commits) != 2 {
+		t.Fatalf("got %d commits, want 2: %+v", len(commits), commits)
+	}
+	if commits[0].Subject != "Finish the redirect work" || commits[1].Subject != "Fix login redirect (test-abc)" {
+		t.Errorf("subjects = %q, %q (want newest first)", commits[0].Subject, commits[1].Subject)
+	}
+	if len(commits[0].Closes) != 1 || commits[0].Closes[0] != "test-abc" {
+		t.Errorf("Closes = %v, want [test-abc]", commits[0].Closes)
+	}
+	if len(commits[1].Closes) != 0 {
+		t.Errorf("plain mention should not close: %v", commits[1].Closes)
+	}
+
+	refs, _ := env.Repo.CodeCommits("test-xyz")
+	if len(refs) != 1 {
+		t.Errorf("Refs: trailer not matched: %+v", refs)
+	}
+	child, _ := env.Repo.CodeCommits("test-abc.1")
+	if len(child) != 1 {
+		t.Errorf("child ID not matched: %+v", child)
+	}
+}
+
+func TestCodeCommitsScansIncrementally(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	env.CodeCommit("a.go", "a", "Start test-abc")
+	if got, _ := env.Repo.CodeCommits("test-abc"); len(got) != 1 {
+		t.Fatalf("first scan = %d commits, want 1", len(got))
+	}
+	cursors, err := os.ReadDir(filepath.Join(env.Repo.GitDir, "refs", "beadwork", "code-cursor"))
+	if err != nil || len(cursors) == 0 {
+		t.Fatalf("expected a cursor ref after scanning: %v", err)
+	}
+
+	env.CodeCommit("b.go", "b", "Continue test-abc")
+	got, err := env.Repo.CodeCommits("test-abc")
+	if err != nil {
+		t.Fatalf("CodeCommits: %v", err)
+	}
+	if len(got) != 2 {
+		t.Errorf("after new commit = %d commits, want 2", len(got))
+	}
+
+	// With the cache gone, the cursors are ignored and history is rescanned.
+	os.Remove(filepath.Join(env.Repo.GitDir, "beadwork", "code-commits.json"))
+	if got, _ := env.Repo.CodeCommits("test-abc"); len(got) != 2 {
+		t.Errorf("after rescan = %d commits, want 2", len(got))
+	}
+}
+
+func TestCommitStats(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	env.CodeCommit("a.go", "one\ntwo\nthree\n", "Add a.go for test-abc")
+	commits, _ := env.Repo.CodeCommits("test-abc")
+	if len(commits) != 1 {
+		t.Fatalf("got %d commits, want 1", len(commits))
+	}
+	stats, err := env.Repo.CommitStats([]string{commits[0].Hash})
+	if err != nil {
+		t.Fatalf("CommitStats: %v", err)
+	}
+	st := stats[commits[0].Hash]
+	if st.Files != 1 || st.Insertions != 3 || st.Deletions != 0 {
+		t.Errorf("stat = %+v, want 1 file, +3 -0", st)
+	}
+}
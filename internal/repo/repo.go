@@ -133,6 +133,55 @@ func (r *Repo) UserName() string {
 	return "unknown"
 }
 
+// IndexPath returns the location of the local query index, or "" when it
+// is disabled. The index is opt-in per clone: git config beadwork.index true.
+func (r *Repo) IndexPath() string {
+	cfg, err := r.tfs.Repo().Config()
+	if err != nil {
+		return ""
+	}
+	if on, _ := strconv.ParseBool(cfg.Raw.Section("beadwork").Option("index")); !on {
+		return ""
+	}
+	return filepath.Join(r.GitDir, "beadwork", "index.json")
+}
+
+// Hooks returns the hook executables configured for this clone, keyed by
+// hook name: git config beadwork.hooks.<name> <path>. Like the index, hooks
+// live in the clone's git config rather than on the beadwork branch, so a
+// sync never installs a command someone else chose.
+func (r *Repo) Hooks() map[string]string {
+	cfg, err := r.tfs.Repo().Config()
+	if err != nil {
+		return nil
+	}
+	sub := cfg.Raw.Section("beadwork").Subsection("hooks")
+	hooks := make(map[string]string)
+	for _, o := range sub.Options {
+		hooks[o.Key] = o.Value
+	}
+	return hooks
+}
+
+// FindGitDir returns the git directory of the repository containing dir
+// (the current directory when empty) without opening it.
+func FindGitDir(dir string) (string, error) {
+	if dir == "" {
+		var err error
+		dir, err = os.Getwd()
+		if err != nil {
+			return "", err
+		}
+	}
+	return findGitDir(dir)
+}
+
+// SocketPath returns where bw serve listens by default for the
+// repository whose git directory is gitDir.
+func SocketPath(gitDir string) string {
+	return filepath.Join(gitDir, "beadwork", "bw.sock")
+}
+
 func (r *Repo) IsInitialized() bool {
 	return r.initialized
 }
@@ -259,3 +259,43 @@ func TestCommitWithChanges(t *testing.T) {
 		t.Fatalf("Commit noop: %v", err)
 	}
 }
+
+func TestIndexPathOptIn(t *testing.T) {
+	dir := t.TempDir()
+	gitRun(t, dir, "init")
+
+	orig, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(orig)
+
+	r, err := repo.FindRepo()
+	if err != nil {
+		t.Fatalf("FindRepo: %v", err)
+	}
+	if got := r.IndexPath(); got != "" {
+		t.Errorf("IndexPath() = %q, want disabled by default", got)
+	}
+
+	gitRun(t, dir, "config", "beadwork.index", "true")
+	r, _ = repo.FindRepo()
+	want := filepath.Join(r.GitDir, "beadwork", "index.json")
+	if got := r.IndexPath(); got != want {
+		t.Errorf("IndexPath() = %q, want %q", got, want)
+	}
+}
+
+func TestHooksFromGitConfig(t *testing.T) {
+	dir := t.TempDir()
+	gitRun(t, dir, "init")
+	gitRun(t, dir, "config", "beadwork.hooks.post-close", "scripts/notify")
+	gitRun(t, dir, "config", "beadwork.remote", "origin")
+
+	r, err := repo.FindRepoAt(dir)
+	if err != nil {
+		t.Fatalf("FindRepoAt: %v", err)
+	}
+	hooks := r.Hooks()
+	if len(hooks) != 1 || hooks["post-close"] != "scripts/notify" {
+		t.Errorf("Hooks() = %v", hooks)
+	}
+}
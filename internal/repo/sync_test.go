@@ -157,6 +157,56 @@ func TestSyncDirtyRebaseIntentReplay(t *testing.T) {
 	}
 }
 
+func TestSyncMergesDifferentFieldsOfSameIssue(t *testing.T) {
+	env := testutil.NewEnv(t)
+	defer env.Cleanup()
+
+	bare := env.NewBareRemote()
+
+	shared, _ := env.Store.Create("Shared issue", issue.CreateOpts{Priority: intPtr(2)})
+	env.CommitIntent("create " + shared.ID + " p2 task \"Shared issue\"")
+	env.Repo.Sync(nil)
+
+	env2 := env.CloneEnv(bare)
+	defer env2.Cleanup()
+
+	// Remote: comment on the issue.
+	env2.SwitchTo()
+	env2.Store.Comment(shared.ID, "from remote", "")
+	env2.CommitIntent("comment " + shared.ID + " \"from remote\"")
+	env2.Repo.Sync(nil)
+
+	// Local: change priority and add a label on the same issue.
+	env.SwitchTo()
+	env.Store.Update(shared.ID, issue.UpdateOpts{Priority: intPtr(0)})
+	env.CommitIntent("update " + shared.ID + " priority=0")
+	env.Store.Label(shared.ID, []string{"urgent"}, nil)
+	env.CommitIntent("label " + shared.ID + " +urgent")
+
+	status, intents, err := env.Repo.Sync(nil)
+	if err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if status != "rebased and pushed" {
+		t.Fatalf("status = %q, want 'rebased and pushed' (intents %v)", status, intents)
+	}
+
+	env.Store.ClearCache()
+	got, err := env.Store.Get(shared.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.Priority != 0 {
+		t.Errorf("priority = %d, want 0 (local)", got.Priority)
+	}
+	if len(got.Comments) != 1 || got.Comments[0].Text != "from remote" {
+		t.Errorf("comments = %+v, want the remote comment", got.Comments)
+	}
+	if len(got.Labels) != 1 || got.Labels[0] != "urgent" {
+		t.Errorf("labels = %v, want [urgent]", got.Labels)
+	}
+}
+
 func TestSyncMultipleIntentsReplay(t *testing.T) {
 	env := testutil.NewEnv(t)
 	defer env.Cleanup()
@@ -0,0 +1,355 @@
+// Package stats derives flow metrics from beadwork commit history. Every
+// intent line is replayed in commit order to rebuild each issue's status
+// transitions; the metrics are then read off those timelines for a window.
+// Like recap, the model is renderer-agnostic.
+package stats
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jallum/beadwork/internal/intent"
+	"github.com/jallum/beadwork/internal/issue"
+	"github.com/jallum/beadwork/internal/recap"
+	"github.com/jallum/beadwork/internal/treefs"
+)
+
+// Group keys accepted by Options.By.
+var GroupKeys = []string{"type", "label", "assignee", "parent"}
+
+// IssueLookup resolves an issue's current state and status categories.
+type IssueLookup interface {
+	Issue(id string) *issue.Issue // nil if the issue no longer exists
+	Category(status string) string
+}
+
+// Options configures Build.
+type Options struct {
+	By       string // one of GroupKeys, or empty
+	Burndown string // issue whose subtree to chart, or empty
+}
+
+// Durations summarises a set of elapsed times, in days.
+type Durations struct {
+	Count  int     `json:"count"`
+	Median float64 `json:"median_days"`
+	P85    float64 `json:"p85_days"`
+}
+
+// Group holds the metrics of the issues sharing one --by value.
+type Group struct {
+	Key    string    `json:"key"`
+	Closed int       `json:"closed"`
+	Lead   Durations `json:"lead_time"`
+	Cycle  Durations `json:"cycle_time"`
+}
+
+// Week is one seven-day bucket of the window, counted from its start.
+type Week struct {
+	Start   time.Time `json:"start"`
+	Created int       `json:"created"`
+	Closed  int       `json:"closed"` // throughput
+	WIP     int       `json:"wip"`    // issues in progress at the bucket's end
+}
+
+// Point is one sample of a burndown.
+type Point struct {
+	Time time.Time `json:"time"`
+	Open int       `json:"open"`
+}
+
+// Burndown charts the unfinished issues in a subtree over the window.
+type Burndown struct {
+	Root   string  `json:"root"`
+	Points []Point `json:"points"`
+}
+
+// Stats is the result of Build.
+type Stats struct {
+	Window   recap.Window `json:"window"`
+	Created  int          `json:"created"`
+	Closed   int          `json:"closed"`
+	Lead     Durations    `json:"lead_time"`  // created to closed
+	Cycle    Durations    `json:"cycle_time"` // first started to closed
+	By       string       `json:"by,omitempty"`
+	Groups   []Group      `json:"groups,omitempty"`
+	Weeks    []Week       `json:"weeks"`
+	Burndown *Burndown    `json:"burndown,omitempty"`
+}
+
+// change is one status transition.
+type change struct {
+	at     time.Time
+	status string // "" once deleted
+}
+
+// timeline is an issue's reconstructed history.
+type timeline struct {
+	created time.Time
+	started time.Time // first move into claimed work; zero if never
+	changes []change
+}
+
+// statusAt returns the issue's status at t, or "" if it did not exist.
+func (tl *timeline) statusAt(t time.Time) string {
+	status := ""
+	for _, c := range tl.changes {
+		if c.at.After(t) {
+			break
+		}
+		status = c.status
+	}
+	return status
+}
+
+// closeEvent is one move into a done status.
+type closeEvent struct {
+	id string
+	at time.Time
+}
+
+// Build computes the metrics for w from commits (newest first, as
+// TreeFS.AllCommits returns them). All of history is replayed so issues
+// opened before the window are tracked correctly inside it.
+func Build(commits []treefs.CommitInfo, w recap.Window, lookup IssueLookup, opts Options) Stats {
+	done := func(status string) bool {
+		return status == "closed" || lookup.Category(status) == issue.CategoryDone
+	}
+	claimed := func(status string) bool {
+		return status != "open" && status != "" &&
+			(status == "in_review" || lookup.Category(status) == issue.CategoryActive)
+	}
+
+	timelines := make(map[string]*timeline)
+	var closes []closeEvent
+	set := func(id, status string, at time.Time) {
+		tl := timelines[id]
+		if tl == nil {
+			tl = &timeline{}
+			if iss := lookup.Issue(id); iss != nil {
+				tl.created, _ = time.Parse(time.RFC3339, iss.Created)
+			}
+			timelines[id] = tl
+		}
+		if claimed(status) && tl.started.IsZero() {
+			tl.started = at
+		}
+		if done(status) {
+			closes = append(closes, closeEvent{id: id, at: at})
+		}
+		tl.changes = append(tl.changes, change{at: at, status: status})
+	}
+
+	for i := len(commits) - 1; i >= 0; i-- {
+		c := commits[i]
+		for _, line := range strings.Split(c.Message, "\n") {
+			parts := intent.ParseIntent(strings.TrimSpace(line))
+			if len(parts) < 2 {
+				continue
+			}
+			id := parts[1]
+			switch parts[0] {
+			case "create":
+				set(id, "open", c.Time)
+				timelines[id].created = c.Time
+			case "start":
+				set(id, "in_progress", c.Time)
+			case "close":
+				set(id, "closed", c.Time)
+			case "reopen", "undefer":
+				set(id, "open", c.Time)
+			case "defer":
+				set(id, "deferred", c.Time)
+			case "delete":
+				set(id, "", c.Time)
+			case "undelete":
+				status := "open"
+				if iss := lookup.Issue(id); iss != nil {
+					status = iss.Status
+				}
+				set(id, status, c.Time)
+			case "recur":
+				if len(parts) >= 3 {
+					set(parts[2], "deferred", c.Time)
+					timelines[parts[2]].created = c.Time
+				}
+			case "update":
+				for _, p := range parts[2:] {
+					if status, ok := strings.CutPrefix(p, "status="); ok {
+						set(id, status, c.Time)
+					}
+				}
+			}
+		}
+	}
+
+	in := func(t time.Time) bool { return !t.Before(w.Start) && !t.After(w.End) }
+	s := Stats{Window: w, By: opts.By, Weeks: []Week{}}
+
+	// Lead and cycle times, overall and per group.
+	var lead, cycle []time.Duration
+	groupLead := make(map[string][]time.Duration)
+	groupCycle := make(map[string][]time.Duration)
+	groupClosed := make(map[string]int)
+	for _, ce := range closes {
+		if !in(ce.at) {
+			continue
+		}
+		s.Closed++
+		tl := timelines[ce.id]
+		keys := groupKeys(lookup.Issue(ce.id), opts.By)
+		for _, k := range keys {
+			groupClosed[k]++
+		}
+		if !tl.created.IsZero() && !tl.created.After(ce.at) {
+			d := ce.at.Sub(tl.created)
+			lead = append(lead, d)
+			for _, k := range keys {
+				groupLead[k] = append(groupLead[k], d)
+			}
+		}
+		if !tl.started.IsZero() && !tl.started.After(ce.at) {
+			d := ce.at.Sub(tl.started)
+			cycle = append(cycle, d)
+			for _, k := range keys {
+				groupCycle[k] = append(groupCycle[k], d)
+			}
+		}
+	}
+	s.Lead, s.Cycle = summarise(lead), summarise(cycle)
+	if opts.By != "" {
+		for k, n := range groupClosed {
+			s.Groups = append(s.Groups, Group{Key: k, Closed: n, Lead: summarise(groupLead[k]), Cycle: summarise(groupCycle[k])})
+		}
+		sort.Slice(s.Groups, func(i, j int) bool {
+			if s.Groups[i].Closed != s.Groups[j].Closed {
+				return s.Groups[i].Closed > s.Groups[j].Closed
+			}
+			return s.Groups[i].Key < s.Groups[j].Key
+		})
+	}
+
+	// Weekly throughput and WIP.
+	for start := w.Start; start.Before(w.End); start = start.AddDate(0, 0, 7) {
+		end := start.AddDate(0, 0, 7)
+		if end.After(w.End) {
+			end = w.End
+		}
+		// Buckets are half-open, except that the last keeps the window's end.
+		inWeek := func(t time.Time) bool {
+			return !t.Before(start) && (t.Before(end) || t.Equal(w.End))
+		}
+		wk := Week{Start: start}
+		for _, ce := range closes {
+			if inWeek(ce.at) {
+				wk.Closed++
+			}
+		}
+		for _, tl := range timelines {
+			if inWeek(tl.created) {
+				wk.Created++
+			}
+			if claimed(tl.statusAt(end)) {
+				wk.WIP++
+			}
+		}
+		s.Weeks = append(s.Weeks, wk)
+	}
+	for _, tl := range timelines {
+		if in(tl.created) {
+			s.Created++
+		}
+	}
+
+	if opts.Burndown != "" {
+		s.Burndown = burndown(opts.Burndown, w, timelines, lookup, done)
+	}
+	return s
+}
+
+// burndown samples the unfinished issues of root's subtree, root included,
+// at the end of each day of the window, or of each week for windows longer
+// than a month. Membership follows the issues' current parents.
+func burndown(root string, w recap.Window, timelines map[string]*timeline, lookup IssueLookup, done func(string) bool) *Burndown {
+	var members []*timeline
+	for id, tl := range timelines {
+		for cur, hops := id, 0; cur != "" && hops < 100; hops++ {
+			if cur == root {
+				members = append(members, tl)
+				break
+			}
+			iss := lookup.Issue(cur)
+			if iss == nil {
+				break
+			}
+			cur = iss.Parent
+		}
+	}
+
+	step := 1
+	if w.End.Sub(w.Start) > 31*24*time.Hour {
+		step = 7
+	}
+	b := &Burndown{Root: root, Points: []Point{}}
+	for t := w.Start.AddDate(0, 0, step); ; t = t.AddDate(0, 0, step) {
+		if t.After(w.End) {
+			t = w.End
+		}
+		p := Point{Time: t}
+		for _, tl := range members {
+			if st := tl.statusAt(t); st != "" && !done(st) {
+				p.Open++
+			}
+		}
+		b.Points = append(b.Points, p)
+		if !t.Before(w.End) {
+			break
+		}
+	}
+	return b
+}
+
+// groupKeys returns the --by values iss counts under. Issues with no value,
+// or that no longer exist, count under "(none)"; an issue counts under each
+// of its labels.
+func groupKeys(iss *issue.Issue, by string) []string {
+	if by == "" {
+		return nil
+	}
+	var keys []string
+	if iss != nil {
+		switch by {
+		case "type":
+			keys = []string{iss.Type}
+		case "assignee":
+			keys = []string{iss.Assignee}
+		case "parent":
+			keys = []string{iss.Parent}
+		case "label":
+			keys = iss.Labels
+		}
+	}
+	if len(keys) == 0 || keys[0] == "" {
+		return []string{"(none)"}
+	}
+	return keys
+}
+
+// summarise returns the count, median and 85th percentile of ds in days,
+// rounded to a tenth.
+func summarise(ds []time.Duration) Durations {
+	if len(ds) == 0 {
+		return Durations{}
+	}
+	sorted := append([]time.Duration(nil), ds...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	days := func(d time.Duration) float64 {
+		return math.Round(d.Hours()/24*10) / 10
+	}
+	pct := func(p float64) time.Duration {
+		return sorted[int(math.Ceil(p*float64(len(sorted))))-1]
+	}
+	return Durations{Count: len(sorted), Median: days(pct(0.5)), P85: days(pct(0.85))}
+}
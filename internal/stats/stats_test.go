@@ -0,0 +1,151 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jallum/beadwork/internal/issue"
+	"github.com/jallum/beadwork/internal/recap"
+	"github.com/jallum/beadwork/internal/treefs"
+)
+
+type fakeLookup struct {
+	issues map[string]*issue.Issue
+}
+
+func (f *fakeLookup) Issue(id string) *issue.Issue { return f.issues[id] }
+
+func (f *fakeLookup) Category(status string) string {
+	return issue.DefaultWorkflow.Category(status)
+}
+
+var day0 = time.Date(2027, 1, 4, 9, 0, 0, 0, time.UTC) // a Monday
+
+func at(days float64) time.Time {
+	return day0.Add(time.Duration(days * float64(24*time.Hour)))
+}
+
+// history returns commits newest first, as AllCommits does.
+func history(entries ...treefs.CommitInfo) []treefs.CommitInfo {
+	out := make([]treefs.CommitInfo, len(entries))
+	for i, e := range entries {
+		out[len(entries)-1-i] = e
+	}
+	return out
+}
+
+func fixture() ([]treefs.CommitInfo, *fakeLookup) {
+	commits := history(
+		treefs.CommitInfo{Message: `create bw-1 p1 epic "Epic"`, Time: at(0)},
+		treefs.CommitInfo{Message: `create bw-1.1 p2 bug "Crash" parent=bw-1`, Time: at(0)},
+		treefs.CommitInfo{Message: `create bw-1.2 p2 task "Docs" parent=bw-1`, Time: at(1)},
+		treefs.CommitInfo{Message: `start bw-1.1 assignee="alice"`, Time: at(2)},
+		treefs.CommitInfo{Message: `close bw-1.1 reason="fixed"`, Time: at(4)},
+		treefs.CommitInfo{Message: "update bw-1.2 status=in_progress", Time: at(8)},
+		treefs.CommitInfo{Message: "close bw-1.2\nunblocked bw-9", Time: at(9)},
+		treefs.CommitInfo{Message: `create bw-2 p2 task "Gone"`, Time: at(9)},
+		treefs.CommitInfo{Message: "delete bw-2", Time: at(10)},
+	)
+	lookup := &fakeLookup{issues: map[string]*issue.Issue{
+		"bw-1":   {ID: "bw-1", Type: "epic"},
+		"bw-1.1": {ID: "bw-1.1", Type: "bug", Parent: "bw-1", Assignee: "alice", Labels: []string{"auth", "ui"}},
+		"bw-1.2": {ID: "bw-1.2", Type: "task", Parent: "bw-1"},
+	}}
+	return commits, lookup
+}
+
+func TestBuild(t *testing.T) {
+	commits, lookup := fixture()
+	w := recap.Window{Start: day0, End: at(14), Label: "test"}
+
+	s := Build(commits, w, lookup, Options{})
+	if s.Created != 4 || s.Closed != 2 {
+		t.Errorf("created = %d, closed = %d; want 4, 2", s.Created, s.Closed)
+	}
+	if s.Lead != (Durations{Count: 2, Median: 4, P85: 8}) {
+		t.Errorf("lead = %+v", s.Lead)
+	}
+	if s.Cycle != (Durations{Count: 2, Median: 1, P85: 2}) {
+		t.Errorf("cycle = %+v", s.Cycle)
+	}
+	if len(s.Weeks) != 2 {
+		t.Fatalf("weeks = %+v", s.Weeks)
+	}
+	if s.Weeks[0].Created != 3 || s.Weeks[0].Closed != 1 || s.Weeks[0].WIP != 0 {
+		t.Errorf("week 1 = %+v", s.Weeks[0])
+	}
+	if s.Weeks[1].Created != 1 || s.Weeks[1].Closed != 1 {
+		t.Errorf("week 2 = %+v", s.Weeks[1])
+	}
+}
+
+func TestBuildWIP(t *testing.T) {
+	commits, lookup := fixture()
+	// A week ending at day 3 catches bw-1.1 in progress.
+	s := Build(commits, recap.Window{Start: day0, End: at(3)}, lookup, Options{})
+	if len(s.Weeks) != 1 || s.Weeks[0].WIP != 1 {
+		t.Errorf("weeks = %+v, want one with WIP 1", s.Weeks)
+	}
+}
+
+func TestBuildWindowExcludesEarlierCloses(t *testing.T) {
+	commits, lookup := fixture()
+	s := Build(commits, recap.Window{Start: at(5), End: at(14)}, lookup, Options{})
+	if s.Closed != 1 || s.Lead.Count != 1 || s.Lead.Median != 8 {
+		t.Errorf("closed = %d, lead = %+v; want bw-1.2 only", s.Closed, s.Lead)
+	}
+}
+
+func TestBuildBy(t *testing.T) {
+	commits, lookup := fixture()
+	w := recap.Window{Start: day0, End: at(14)}
+
+	s := Build(commits, w, lookup, Options{By: "type"})
+	if len(s.Groups) != 2 || s.Groups[0].Key != "bug" || s.Groups[1].Key != "task" {
+		t.Fatalf("groups = %+v", s.Groups)
+	}
+	if s.Groups[0].Cycle.Median != 2 {
+		t.Errorf("bug cycle = %+v", s.Groups[0].Cycle)
+	}
+
+	s = Build(commits, w, lookup, Options{By: "label"})
+	keys := map[string]int{}
+	for _, g := range s.Groups {
+		keys[g.Key] = g.Closed
+	}
+	if keys["auth"] != 1 || keys["ui"] != 1 || keys["(none)"] != 1 {
+		t.Errorf("label groups = %+v", s.Groups)
+	}
+}
+
+func TestBuildBurndown(t *testing.T) {
+	commits, lookup := fixture()
+	s := Build(commits, recap.Window{Start: day0, End: at(10)}, lookup, Options{Burndown: "bw-1"})
+	if s.Burndown == nil || s.Burndown.Root != "bw-1" {
+		t.Fatalf("burndown = %+v", s.Burndown)
+	}
+	pts := s.Burndown.Points
+	if len(pts) != 10 {
+		t.Fatalf("points = %d, want 10 daily points", len(pts))
+	}
+	// Day 1 end: epic, crash and docs open. Day 4 end: crash closed.
+	// Day 9 end: only the epic is left.
+	for _, c := range []struct{ i, open int }{{0, 3}, {3, 2}, {9, 1}} {
+		if pts[c.i].Open != c.open {
+			t.Errorf("point %d open = %d, want %d", c.i, pts[c.i].Open, c.open)
+		}
+	}
+}
+
+func TestSummarise(t *testing.T) {
+	if got := summarise(nil); got != (Durations{}) {
+		t.Errorf("summarise(nil) = %+v", got)
+	}
+	ds := []time.Duration{}
+	for i := 1; i <= 20; i++ {
+		ds = append(ds, time.Duration(i)*24*time.Hour)
+	}
+	if got := summarise(ds); got != (Durations{Count: 20, Median: 10, P85: 17}) {
+		t.Errorf("summarise = %+v", got)
+	}
+}
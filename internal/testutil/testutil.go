@@ -130,6 +130,17 @@ func (e *Env) IssueFileExists(id string) bool {
 	return err == nil
 }
 
+// CodeCommit commits a change to the file path in the working tree with
+// the given message, as a developer would on a code branch.
+func (e *Env) CodeCommit(path, content, msg string) {
+	e.T.Helper()
+	if err := os.WriteFile(e.Dir+"/"+path, []byte(content), 0644); err != nil {
+		e.T.Fatalf("WriteFile: %v", err)
+	}
+	run(e.T, e.Dir, "git", "add", path)
+	run(e.T, e.Dir, "git", "commit", "-m", msg)
+}
+
 func run(t *testing.T, dir string, name string, args ...string) {
 	t.Helper()
 	cmd := exec.Command(name, args...)
@@ -0,0 +1,381 @@
+package treefs
+
+import (
+	"bytes"
+	"encoding/json"
+	"path"
+	"sort"
+	"strings"
+)
+
+// isIssueJSON reports whether p is an issue document (issues/<id>.json)
+// eligible for field-level merging.
+func isIssueJSON(p string) bool {
+	return path.Dir(p) == "issues" && strings.HasSuffix(p, ".json")
+}
+
+// setFields are issue fields holding sorted, duplicate-free ID or name
+// lists. Concurrent edits merge as set operations: additions from either
+// side are kept and removals from either side are honoured. The value
+// reports whether the store omits the field when it is empty.
+var setFields = map[string]bool{
+	"labels":        false,
+	"blocks":        false,
+	"blocked_by":    false,
+	"relates_to":    true,
+	"duplicates":    true,
+	"duplicated_by": true,
+	"supersedes":    true,
+	"superseded_by": true,
+}
+
+// jsonObject is a decoded top-level JSON object that remembers the order
+// its keys appeared in, so a merged document can be re-encoded with the
+// same field layout the store writes.
+type jsonObject struct {
+	keys   []string
+	fields map[string]json.RawMessage
+}
+
+func decodeObject(data []byte) (*jsonObject, bool) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	tok, err := dec.Token()
+	if err != nil || tok != json.Delim('{') {
+		return nil, false
+	}
+	obj := &jsonObject{fields: make(map[string]json.RawMessage)}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, false
+		}
+		key, ok := tok.(string)
+		if !ok {
+			return nil, false
+		}
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, false
+		}
+		if _, dup := obj.fields[key]; !dup {
+			obj.keys = append(obj.keys, key)
+		}
+		obj.fields[key] = raw
+	}
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('}') {
+		return nil, false
+	}
+	return obj, true
+}
+
+// mergeIssueJSON performs a field-level three-way merge of an issue
+// document. Fields changed on only one side take that side's value.
+// Fields changed identically on both sides are kept. When both sides
+// changed a field differently, set fields (labels, blocks, blocked_by and
+// the relation lists) are merged as sets, custom fields are merged per
+// key, comments are unioned in timestamp order, and updated_at takes the
+// later value; any other field is a conflict and the second return value
+// is false.
+//
+// A missing base (nil) is treated as an empty object, so an issue created
+// independently on both sides still merges when the fields agree.
+func mergeIssueJSON(base, local, remote []byte) ([]byte, bool) {
+	baseObj := &jsonObject{fields: map[string]json.RawMessage{}}
+	if base != nil {
+		var ok bool
+		if baseObj, ok = decodeObject(base); !ok {
+			return nil, false
+		}
+	}
+	localObj, ok := decodeObject(local)
+	if !ok {
+		return nil, false
+	}
+	remoteObj, ok := decodeObject(remote)
+	if !ok {
+		return nil, false
+	}
+
+	// Preserve local's field order, then append fields only remote has.
+	order := append([]string(nil), localObj.keys...)
+	seen := make(map[string]bool, len(order))
+	for _, k := range order {
+		seen[k] = true
+	}
+	for _, k := range remoteObj.keys {
+		if !seen[k] {
+			seen[k] = true
+			order = append(order, k)
+		}
+	}
+	// Fields present only in base were deleted on both sides; nothing to
+	// emit, but still check them so a delete/modify pair is a conflict.
+	for _, k := range baseObj.keys {
+		if !seen[k] {
+			order = append(order, k)
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	first := true
+	for _, key := range order {
+		b, inBase := baseObj.fields[key]
+		l, inLocal := localObj.fields[key]
+		r, inRemote := remoteObj.fields[key]
+
+		val, present, ok := mergeField(key, b, inBase, l, inLocal, r, inRemote)
+		if !ok {
+			return nil, false
+		}
+		if !present {
+			continue
+		}
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+		k, _ := json.Marshal(key)
+		buf.Write(k)
+		buf.WriteByte(':')
+		if err := json.Compact(&buf, val); err != nil {
+			return nil, false
+		}
+	}
+	buf.WriteByte('}')
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, buf.Bytes(), "", "  "); err != nil {
+		return nil, false
+	}
+	if bytes.HasSuffix(local, []byte("\n")) {
+		out.WriteByte('\n')
+	}
+	return out.Bytes(), true
+}
+
+// mergeField resolves a single field. It returns the merged value, whether
+// the field is present in the result, and false on an unresolvable conflict.
+func mergeField(key string, b json.RawMessage, inBase bool, l json.RawMessage, inLocal bool, r json.RawMessage, inRemote bool) (json.RawMessage, bool, bool) {
+	localChanged := inBase != inLocal || !rawEqual(b, l)
+	remoteChanged := inBase != inRemote || !rawEqual(b, r)
+	omitEmpty, isSet := setFields[key]
+
+	switch {
+	case !localChanged:
+		return r, inRemote, true
+	case !remoteChanged:
+		return l, inLocal, true
+	case inLocal == inRemote && rawEqual(l, r):
+		return l, inLocal, true
+	case key == "fields":
+		// Omitted when empty, so absence is just an empty map here.
+		return mergeStringMap(b, l, r)
+	case isSet:
+		// Absence is an empty set. Relation lists are omitted when empty,
+		// matching the store's omitempty encoding.
+		v, ok := mergeStringSet(b, l, r)
+		if ok && omitEmpty && string(v) == "[]" {
+			return nil, false, true
+		}
+		return v, true, ok
+	case !inLocal || !inRemote:
+		// Removed on one side, modified on the other.
+		return nil, false, false
+	}
+
+	switch {
+	case key == "comments":
+		v, ok := mergeComments(b, l, r)
+		return v, true, ok
+	case key == "updated_at":
+		var ls, rs string
+		if json.Unmarshal(l, &ls) != nil || json.Unmarshal(r, &rs) != nil {
+			return nil, false, false
+		}
+		if rs > ls {
+			return r, true, true
+		}
+		return l, true, true
+	}
+	return nil, false, false
+}
+
+// mergeStringSet three-way merges JSON string arrays as sets. The result
+// is sorted and never null.
+func mergeStringSet(b, l, r json.RawMessage) (json.RawMessage, bool) {
+	var base, local, remote []string
+	if len(b) > 0 && json.Unmarshal(b, &base) != nil {
+		return nil, false
+	}
+	if len(l) > 0 && json.Unmarshal(l, &local) != nil {
+		return nil, false
+	}
+	if len(r) > 0 && json.Unmarshal(r, &remote) != nil {
+		return nil, false
+	}
+	inBase := toSet(base)
+	inLocal := toSet(local)
+	inRemote := toSet(remote)
+
+	result := make(map[string]bool)
+	for v := range inBase {
+		// Keep a base element only if neither side removed it.
+		if inLocal[v] && inRemote[v] {
+			result[v] = true
+		}
+	}
+	for v := range inLocal {
+		if !inBase[v] {
+			result[v] = true
+		}
+	}
+	for v := range inRemote {
+		if !inBase[v] {
+			result[v] = true
+		}
+	}
+
+	merged := make([]string, 0, len(result))
+	for v := range result {
+		merged = append(merged, v)
+	}
+	sort.Strings(merged)
+	data, err := json.Marshal(merged)
+	return data, err == nil
+}
+
+// mergeStringMap three-way merges the custom fields object key by key.
+// A key changed differently on both sides is a conflict. An empty result
+// is omitted, matching the store's omitempty encoding.
+func mergeStringMap(b, l, r json.RawMessage) (json.RawMessage, bool, bool) {
+	var base, local, remote map[string]string
+	for _, p := range []struct {
+		raw json.RawMessage
+		dst *map[string]string
+	}{{b, &base}, {l, &local}, {r, &remote}} {
+		if len(p.raw) > 0 && json.Unmarshal(p.raw, p.dst) != nil {
+			return nil, false, false
+		}
+	}
+
+	merged := make(map[string]string)
+	keys := make(map[string]bool)
+	for _, m := range []map[string]string{base, local, remote} {
+		for k := range m {
+			keys[k] = true
+		}
+	}
+	for k := range keys {
+		bv, inB := base[k]
+		lv, inL := local[k]
+		rv, inR := remote[k]
+		var v string
+		var present bool
+		switch {
+		case inL == inB && lv == bv:
+			v, present = rv, inR
+		case inR == inB && rv == bv:
+			v, present = lv, inL
+		case inL == inR && lv == rv:
+			v, present = lv, inL
+		default:
+			return nil, false, false
+		}
+		if present {
+			merged[k] = v
+		}
+	}
+	if len(merged) == 0 {
+		return nil, false, true
+	}
+	data, err := json.Marshal(merged)
+	return data, true, err == nil
+}
+
+func toSet(ss []string) map[string]bool {
+	set := make(map[string]bool, len(ss))
+	for _, s := range ss {
+		set[s] = true
+	}
+	return set
+}
+
+// mergeComments unions two append-only comment lists. Both sides must
+// retain every base comment; the comments each side appended are combined
+// and ordered by timestamp (ties keep local before remote).
+func mergeComments(b, l, r json.RawMessage) (json.RawMessage, bool) {
+	var base, local, remote []json.RawMessage
+	if len(b) > 0 && json.Unmarshal(b, &base) != nil {
+		return nil, false
+	}
+	if json.Unmarshal(l, &local) != nil || json.Unmarshal(r, &remote) != nil {
+		return nil, false
+	}
+	if !hasRawPrefix(local, base) || !hasRawPrefix(remote, base) {
+		return nil, false
+	}
+
+	added := append([]json.RawMessage(nil), local[len(base):]...)
+	for _, c := range remote[len(base):] {
+		if !containsRaw(added, c) {
+			added = append(added, c)
+		}
+	}
+
+	stamps := make([]string, len(added))
+	for i, c := range added {
+		var v struct {
+			Timestamp string `json:"timestamp"`
+		}
+		if json.Unmarshal(c, &v) != nil {
+			return nil, false
+		}
+		stamps[i] = v.Timestamp
+	}
+	idx := make([]int, len(added))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(i, j int) bool { return stamps[idx[i]] < stamps[idx[j]] })
+
+	merged := append([]json.RawMessage(nil), base...)
+	for _, i := range idx {
+		merged = append(merged, added[i])
+	}
+	data, err := json.Marshal(merged)
+	return data, err == nil
+}
+
+func hasRawPrefix(list, prefix []json.RawMessage) bool {
+	if len(list) < len(prefix) {
+		return false
+	}
+	for i := range prefix {
+		if !rawEqual(list[i], prefix[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsRaw(list []json.RawMessage, v json.RawMessage) bool {
+	for _, x := range list {
+		if rawEqual(x, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// rawEqual compares two JSON values ignoring insignificant whitespace.
+func rawEqual(a, b json.RawMessage) bool {
+	if bytes.Equal(a, b) {
+		return true
+	}
+	var ca, cb bytes.Buffer
+	if json.Compact(&ca, a) != nil || json.Compact(&cb, b) != nil {
+		return false
+	}
+	return bytes.Equal(ca.Bytes(), cb.Bytes())
+}
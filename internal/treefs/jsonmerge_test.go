@@ -0,0 +1,312 @@
+package treefs
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const mergeBase = `{
+  "assignee": "",
+  "blocked_by": [],
+  "blocks": [],
+  "description": "",
+  "id": "test-abc",
+  "labels": [
+    "bug"
+  ],
+  "priority": 2,
+  "status": "open",
+  "title": "Fix it",
+  "type": "task",
+  "updated_at": "2027-01-01T00:00:00Z"
+}
+`
+
+// edit decodes doc, applies fn to the field map, and re-encodes it with
+// keys in the same order as mergeBase.
+func edit(t *testing.T, doc string, fn func(m map[string]any)) []byte {
+	t.Helper()
+	obj, ok := decodeObject([]byte(doc))
+	if !ok {
+		t.Fatalf("decode %q", doc)
+	}
+	m := make(map[string]any)
+	for k, v := range obj.fields {
+		var x any
+		json.Unmarshal(v, &x)
+		m[k] = x
+	}
+	fn(m)
+	var sb strings.Builder
+	sb.WriteString("{")
+	keys := append([]string(nil), obj.keys...)
+	for k := range m {
+		if _, ok := obj.fields[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	first := true
+	for _, k := range keys {
+		v, ok := m[k]
+		if !ok {
+			continue
+		}
+		if !first {
+			sb.WriteString(",")
+		}
+		first = false
+		kb, _ := json.Marshal(k)
+		vb, _ := json.Marshal(v)
+		sb.Write(kb)
+		sb.WriteString(":")
+		sb.Write(vb)
+	}
+	sb.WriteString("}")
+	return []byte(sb.String())
+}
+
+func decodeMap(t *testing.T, data []byte) map[string]any {
+	t.Helper()
+	var m map[string]any
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatalf("unmarshal merged: %v\n%s", err, data)
+	}
+	return m
+}
+
+func TestMergeIssueJSONIndependentFields(t *testing.T) {
+	local := edit(t, mergeBase, func(m map[string]any) {
+		m["priority"] = 1
+		m["updated_at"] = "2027-01-02T00:00:00Z"
+	})
+	remote := edit(t, mergeBase, func(m map[string]any) {
+		m["title"] = "Fix it properly"
+		m["updated_at"] = "2027-01-03T00:00:00Z"
+	})
+
+	out, ok := mergeIssueJSON([]byte(mergeBase), local, remote)
+	if !ok {
+		t.Fatal("expected clean merge")
+	}
+	m := decodeMap(t, out)
+	if m["priority"] != float64(1) {
+		t.Errorf("priority = %v, want 1", m["priority"])
+	}
+	if m["title"] != "Fix it properly" {
+		t.Errorf("title = %v", m["title"])
+	}
+	if m["updated_at"] != "2027-01-03T00:00:00Z" {
+		t.Errorf("updated_at = %v, want the later timestamp", m["updated_at"])
+	}
+}
+
+func TestMergeIssueJSONSameScalarConflicts(t *testing.T) {
+	local := edit(t, mergeBase, func(m map[string]any) { m["priority"] = 1 })
+	remote := edit(t, mergeBase, func(m map[string]any) { m["priority"] = 3 })
+	if _, ok := mergeIssueJSON([]byte(mergeBase), local, remote); ok {
+		t.Fatal("expected conflict when both sides change priority")
+	}
+}
+
+func TestMergeIssueJSONSameScalarSameValue(t *testing.T) {
+	local := edit(t, mergeBase, func(m map[string]any) { m["status"] = "closed" })
+	remote := edit(t, mergeBase, func(m map[string]any) { m["status"] = "closed" })
+	out, ok := mergeIssueJSON([]byte(mergeBase), local, remote)
+	if !ok {
+		t.Fatal("identical changes should merge")
+	}
+	if decodeMap(t, out)["status"] != "closed" {
+		t.Errorf("status not preserved: %s", out)
+	}
+}
+
+func TestMergeIssueJSONLabelsSetMerge(t *testing.T) {
+	local := edit(t, mergeBase, func(m map[string]any) { m["labels"] = []string{"bug", "frontend"} })
+	remote := edit(t, mergeBase, func(m map[string]any) { m["labels"] = []string{"auth"} })
+
+	out, ok := mergeIssueJSON([]byte(mergeBase), local, remote)
+	if !ok {
+		t.Fatal("expected clean label merge")
+	}
+	got := decodeMap(t, out)["labels"].([]any)
+	want := []string{"auth", "frontend"}
+	if len(got) != len(want) {
+		t.Fatalf("labels = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("labels[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestMergeIssueJSONEmptiedRelationOmitted(t *testing.T) {
+	base := edit(t, mergeBase, func(m map[string]any) {
+		m["labels"] = []string{"bug", "ui"}
+		m["relates_to"] = []string{"test-x", "test-y"}
+	})
+	local := edit(t, string(base), func(m map[string]any) {
+		m["labels"] = []string{"ui"}
+		m["relates_to"] = []string{"test-y"}
+	})
+	remote := edit(t, string(base), func(m map[string]any) {
+		m["labels"] = []string{"bug"}
+		m["relates_to"] = []string{"test-x"}
+	})
+
+	out, ok := mergeIssueJSON(base, local, remote)
+	if !ok {
+		t.Fatal("expected clean merge")
+	}
+	got := decodeMap(t, out)
+	if _, present := got["relates_to"]; present {
+		t.Errorf("emptied relates_to should be omitted:\n%s", out)
+	}
+	if labels, present := got["labels"]; !present || len(labels.([]any)) != 0 {
+		t.Errorf("emptied labels should stay as []:\n%s", out)
+	}
+}
+
+func TestMergeIssueJSONCustomFieldsPerKey(t *testing.T) {
+	base := edit(t, mergeBase, func(m map[string]any) { m["fields"] = map[string]string{"severity": "low"} })
+	local := edit(t, string(base), func(m map[string]any) {
+		m["fields"] = map[string]string{"severity": "low", "estimate": "3"}
+	})
+	remote := edit(t, string(base), func(m map[string]any) {
+		m["fields"] = map[string]string{"severity": "high"}
+	})
+
+	out, ok := mergeIssueJSON(base, local, remote)
+	if !ok {
+		t.Fatal("expected clean merge of different field keys")
+	}
+	got := decodeMap(t, out)["fields"].(map[string]any)
+	if got["severity"] != "high" || got["estimate"] != "3" || len(got) != 2 {
+		t.Errorf("fields = %v, want severity=high estimate=3", got)
+	}
+
+	clash := edit(t, string(base), func(m map[string]any) {
+		m["fields"] = map[string]string{"severity": "medium"}
+	})
+	if _, ok := mergeIssueJSON(base, clash, remote); ok {
+		t.Error("expected conflict when both sides set the same field differently")
+	}
+
+	// Fields object absent in base: added on both sides.
+	local = edit(t, mergeBase, func(m map[string]any) { m["fields"] = map[string]string{"a": "1"} })
+	remote = edit(t, mergeBase, func(m map[string]any) { m["fields"] = map[string]string{"b": "2"} })
+	out, ok = mergeIssueJSON([]byte(mergeBase), local, remote)
+	if !ok {
+		t.Fatal("expected clean merge of fields added on both sides")
+	}
+	if got := decodeMap(t, out)["fields"].(map[string]any); len(got) != 2 {
+		t.Errorf("fields = %v, want a and b", got)
+	}
+}
+
+func TestMergeIssueJSONCommentsUnion(t *testing.T) {
+	base := edit(t, mergeBase, func(m map[string]any) {
+		m["comments"] = []map[string]string{{"text": "first", "timestamp": "2027-01-01T00:00:00Z"}}
+	})
+	local := edit(t, string(base), func(m map[string]any) {
+		m["comments"] = []map[string]string{
+			{"text": "first", "timestamp": "2027-01-01T00:00:00Z"},
+			{"text": "local", "timestamp": "2027-01-03T00:00:00Z"},
+		}
+	})
+	remote := edit(t, string(base), func(m map[string]any) {
+		m["comments"] = []map[string]string{
+			{"text": "first", "timestamp": "2027-01-01T00:00:00Z"},
+			{"text": "remote", "timestamp": "2027-01-02T00:00:00Z"},
+		}
+	})
+
+	out, ok := mergeIssueJSON(base, local, remote)
+	if !ok {
+		t.Fatal("expected clean comment merge")
+	}
+	comments := decodeMap(t, out)["comments"].([]any)
+	var texts []string
+	for _, c := range comments {
+		texts = append(texts, c.(map[string]any)["text"].(string))
+	}
+	if strings.Join(texts, ",") != "first,remote,local" {
+		t.Errorf("comments = %v, want first,remote,local", texts)
+	}
+}
+
+func TestMergeIssueJSONRemovedVsModifiedConflicts(t *testing.T) {
+	base := edit(t, mergeBase, func(m map[string]any) { m["due"] = "2027-02-01" })
+	local := edit(t, string(base), func(m map[string]any) { delete(m, "due") })
+	remote := edit(t, string(base), func(m map[string]any) { m["due"] = "2027-03-01" })
+	if _, ok := mergeIssueJSON(base, local, remote); ok {
+		t.Fatal("expected conflict when one side clears a field the other changes")
+	}
+}
+
+func TestMergeIssueJSONPreservesLayout(t *testing.T) {
+	local := []byte(strings.Replace(mergeBase, `"priority": 2`, `"priority": 1`, 1))
+	remote := []byte(strings.Replace(mergeBase, `"title": "Fix it"`, `"title": "Fixed"`, 1))
+
+	out, ok := mergeIssueJSON([]byte(mergeBase), local, remote)
+	if !ok {
+		t.Fatal("expected clean merge")
+	}
+	want := strings.Replace(string(local), `"title": "Fix it"`, `"title": "Fixed"`, 1)
+	if string(out) != want {
+		t.Errorf("merged layout differs from store encoding\ngot:\n%s\nwant:\n%s", out, want)
+	}
+}
+
+func TestMergeIssueJSONRejectsInvalid(t *testing.T) {
+	if _, ok := mergeIssueJSON([]byte(mergeBase), []byte("not json"), []byte(mergeBase)); ok {
+		t.Fatal("expected failure for unparseable local document")
+	}
+}
+
+func TestMergeCommitMergesIssueFields(t *testing.T) {
+	dir := initTestRepo(t)
+	tfs, err := Open(dir, "refs/heads/beadwork")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	tfs.WriteFile("issues/test-abc.json", []byte(mergeBase))
+	if err := tfs.Commit("base"); err != nil {
+		t.Fatalf("Commit base: %v", err)
+	}
+	baseHash := tfs.RefHash()
+	if err := tfs.SetRef("refs/remotes/origin/beadwork", baseHash); err != nil {
+		t.Fatalf("SetRef remote: %v", err)
+	}
+
+	remote, err := Open(dir, "refs/remotes/origin/beadwork")
+	if err != nil {
+		t.Fatalf("Open remote: %v", err)
+	}
+	remote.WriteFile("issues/test-abc.json", []byte(strings.Replace(mergeBase, `"title": "Fix it"`, `"title": "Fixed"`, 1)))
+	if err := remote.Commit("update test-abc title=\"Fixed\""); err != nil {
+		t.Fatalf("remote Commit: %v", err)
+	}
+
+	tfs.WriteFile("issues/test-abc.json", []byte(strings.Replace(mergeBase, `"priority": 2`, `"priority": 0`, 1)))
+	if err := tfs.Commit("update test-abc priority=0"); err != nil {
+		t.Fatalf("local Commit: %v", err)
+	}
+
+	merged, err := tfs.MergeCommit(tfs.RefHash(), remote.RefHash(), []string{"update test-abc priority=0"})
+	if err != nil {
+		t.Fatalf("MergeCommit: %v", err)
+	}
+	if !merged {
+		t.Fatal("expected field-level merge to succeed")
+	}
+	data, err := tfs.ReadFile("issues/test-abc.json")
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if !strings.Contains(string(data), `"priority": 0`) || !strings.Contains(string(data), `"title": "Fixed"`) {
+		t.Errorf("merged issue missing one side's change:\n%s", data)
+	}
+}
@@ -110,6 +110,22 @@ func OpenFromRepo(repo *git.Repository, ref string) (*TreeFS, error) {
 	return tfs, nil
 }
 
+// OpenAt creates a read-only TreeFS over the tree of an arbitrary commit.
+// The snapshot tracks no ref, so it never refreshes and Commit refuses
+// with ErrReadOnly. Used for point-in-time queries (bw --at).
+func OpenAt(repo *git.Repository, commitHash plumbing.Hash) (*TreeFS, error) {
+	tfs := &TreeFS{
+		repo:    repo,
+		baseRef: commitHash,
+		overlay: make(map[string][]byte),
+		dirs:    make(map[string]bool),
+	}
+	if err := tfs.reloadBase(); err != nil {
+		return nil, err
+	}
+	return tfs, nil
+}
+
 // Repo returns the underlying go-git repository.
 func (t *TreeFS) Repo() *git.Repository {
 	return t.repo
@@ -120,6 +136,9 @@ func (t *TreeFS) Repo() *git.Repository {
 func (t *TreeFS) Refresh() error {
 	t.overlay = make(map[string][]byte)
 	t.dirs = make(map[string]bool)
+	if t.ref == "" {
+		return nil // snapshot: pinned to its commit
+	}
 
 	r, err := t.repo.Reference(t.ref, true)
 	if err != nil {
@@ -410,6 +429,9 @@ func (t *TreeFS) dirHasContent(p string) bool {
 // Commit materializes all pending changes into a git commit and updates the
 // ref atomically. Returns an error if the ref has moved since Open (CAS).
 func (t *TreeFS) Commit(msg string) error {
+	if t.ref == "" {
+		return ErrReadOnly
+	}
 	if len(t.overlay) == 0 {
 		return nil // nothing to commit
 	}
@@ -760,12 +782,7 @@ func (t *TreeFS) CommitsBetween(localHash, remoteHash plumbing.Hash) ([]CommitIn
 		if remoteSet[c.Hash] {
 			return storer.ErrStop
 		}
-		commits = append(commits, CommitInfo{
-			Hash:    c.Hash.String(),
-			Message: strings.TrimSpace(c.Message),
-			Time:    c.Author.When,
-			Author:  c.Author.Name,
-		})
+		commits = append(commits, commitInfo(c))
 		return nil
 	})
 	if err != nil && err != storer.ErrStop {
@@ -790,12 +807,7 @@ func (t *TreeFS) AllCommits() ([]CommitInfo, error) {
 		return nil, fmt.Errorf("walk commits: %w", err)
 	}
 	iter.ForEach(func(c *object.Commit) error {
-		commits = append(commits, CommitInfo{
-			Hash:    c.Hash.String(),
-			Message: strings.TrimSpace(c.Message),
-			Time:    c.Author.When,
-			Author:  c.Author.Name,
-		})
+		commits = append(commits, commitInfo(c))
 		return nil
 	})
 	return commits, nil
@@ -821,22 +833,71 @@ func (t *TreeFS) CommitsSince(sinceHash string) ([]CommitInfo, error) {
 		if !since.IsZero() && c.Hash == since {
 			return storer.ErrStop
 		}
-		commits = append(commits, CommitInfo{
-			Hash:    c.Hash.String(),
-			Message: strings.TrimSpace(c.Message),
-			Time:    c.Author.When,
-			Author:  c.Author.Name,
-		})
+		commits = append(commits, commitInfo(c))
 		return nil
 	})
 	return commits, nil
 }
 
+// ParentHash returns the first parent of the given commit, or the zero
+// hash for a root commit.
+func (t *TreeFS) ParentHash(commitHash plumbing.Hash) (plumbing.Hash, error) {
+	commit, err := t.repo.CommitObject(commitHash)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("read commit %s: %w", commitHash, err)
+	}
+	if len(commit.ParentHashes) == 0 {
+		return plumbing.ZeroHash, nil
+	}
+	return commit.ParentHashes[0], nil
+}
+
 // RefHash returns the current hash of the tracked ref.
 func (t *TreeFS) RefHash() plumbing.Hash {
 	return t.baseRef
 }
 
+// TreeHash returns the hash of the committed tree reads fall through to,
+// picking up commits made by other processes first. It is the zero hash
+// when the ref does not exist yet.
+func (t *TreeFS) TreeHash() plumbing.Hash {
+	t.maybeRefresh()
+	if t.base == nil {
+		return plumbing.ZeroHash
+	}
+	return t.base.Hash
+}
+
+// Dirty reports whether there are pending mutations not yet committed.
+func (t *TreeFS) Dirty() bool {
+	return len(t.overlay) > 0 || len(t.dirs) > 0
+}
+
+// ChangedPaths returns the paths of files that differ between the tree
+// with hash from and the current base tree: added, modified and removed.
+func (t *TreeFS) ChangedPaths(from plumbing.Hash) ([]string, error) {
+	if t.base == nil {
+		return nil, fmt.Errorf("no base tree")
+	}
+	old, err := t.repo.TreeObject(from)
+	if err != nil {
+		return nil, fmt.Errorf("read tree %s: %w", from, err)
+	}
+	changes, err := object.DiffTree(old, t.base)
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, 0, len(changes))
+	for _, c := range changes {
+		if c.To.Name != "" {
+			paths = append(paths, c.To.Name)
+		} else {
+			paths = append(paths, c.From.Name)
+		}
+	}
+	return paths, nil
+}
+
 // HasRef returns true if the tracked ref exists.
 func (t *TreeFS) HasRef() bool {
 	return !t.baseRef.IsZero()
@@ -906,19 +967,40 @@ func (t *TreeFS) DeleteRef(name string) error {
 // was opened, indicating a CAS conflict. Callers can check for this to retry.
 var ErrRefMoved = fmt.Errorf("ref moved")
 
-// CommitInfo holds a commit hash and message.
+// ErrReadOnly is returned by Commit on a snapshot opened with OpenAt.
+var ErrReadOnly = fmt.Errorf("read-only snapshot")
+
+// CommitInfo holds a commit hash, its first parent and its message.
 type CommitInfo struct {
 	Hash    string
+	Parent  string // first parent's hash; empty for the root commit
 	Message string
 	Time    time.Time
 	Author  string
 }
 
+func commitInfo(c *object.Commit) CommitInfo {
+	info := CommitInfo{
+		Hash:    c.Hash.String(),
+		Message: strings.TrimSpace(c.Message),
+		Time:    c.Author.When,
+		Author:  c.Author.Name,
+	}
+	if len(c.ParentHashes) > 0 {
+		info.Parent = c.ParentHashes[0].String()
+	}
+	return info
+}
+
 // MergeCommit attempts a 3-way merge between the local tree, remote tree,
 // and their common ancestor (base). If all changes are non-conflicting, it
 // creates a commit with the merged tree on top of remoteHash and updates
 // the local ref. Returns true if the merge succeeded, false if there were
 // conflicts.
+//
+// Paths are merged whole, except issues/<id>.json: when both sides edited
+// the same issue, its fields are merged individually (see mergeIssueJSON)
+// and only a genuine same-field conflict fails the merge.
 func (t *TreeFS) MergeCommit(localHash, remoteHash plumbing.Hash, localCommitMsgs []string) (bool, error) {
 	// Find common ancestor by walking both commit histories
 	baseHash, err := t.findMergeBase(localHash, remoteHash)
@@ -993,6 +1075,8 @@ func (t *TreeFS) MergeCommit(localHash, remoteHash plumbing.Hash, localCommitMsg
 				if inLocal {
 					merged[p] = localData
 				}
+			} else if data, ok := t.mergeBothChanged(p, baseData, inBase, localData, inLocal, remoteData, inRemote); ok {
+				merged[p] = data
 			} else {
 				// Conflict
 				return false, nil
@@ -1051,6 +1135,20 @@ func (t *TreeFS) MergeCommit(localHash, remoteHash plumbing.Hash, localCommitMsg
 	return true, nil
 }
 
+// mergeBothChanged resolves a path changed differently on both sides.
+// Issue documents get a field-level JSON merge; every other path (and any
+// delete/modify pair) is reported as a conflict so Sync falls back to
+// intent replay.
+func (t *TreeFS) mergeBothChanged(p string, baseData []byte, inBase bool, localData []byte, inLocal bool, remoteData []byte, inRemote bool) ([]byte, bool) {
+	if !isIssueJSON(p) || !inLocal || !inRemote {
+		return nil, false
+	}
+	if !inBase {
+		baseData = nil
+	}
+	return mergeIssueJSON(baseData, localData, remoteData)
+}
+
 func (t *TreeFS) collectFilesAtCommit(hash plumbing.Hash, out map[string][]byte) error {
 	commit, err := t.repo.CommitObject(hash)
 	if err != nil {
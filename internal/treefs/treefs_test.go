@@ -5,6 +5,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"testing"
 	"time"
 
@@ -503,6 +504,37 @@ func TestCommitRespectsClockField(t *testing.T) {
 	}
 }
 
+func TestAllCommitsRecordsParent(t *testing.T) {
+	dir := initTestRepo(t)
+	tfs, err := Open(dir, "refs/heads/beadwork")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	tfs.WriteFile("a.txt", []byte("a"))
+	if err := tfs.Commit("first"); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+	tfs.WriteFile("b.txt", []byte("b"))
+	if err := tfs.Commit("second"); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+
+	commits, err := tfs.AllCommits()
+	if err != nil {
+		t.Fatalf("AllCommits: %v", err)
+	}
+	if len(commits) < 2 {
+		t.Fatalf("got %d commits, want at least 2", len(commits))
+	}
+	if commits[0].Parent != commits[1].Hash {
+		t.Errorf("Parent = %q, want %q", commits[0].Parent, commits[1].Hash)
+	}
+	if root := commits[len(commits)-1]; root.Parent != "" {
+		t.Errorf("root commit Parent = %q, want empty", root.Parent)
+	}
+}
+
 func TestCommitRespectsBWClockEnv(t *testing.T) {
 	dir := initTestRepo(t)
 	tfs, err := Open(dir, "refs/heads/beadwork")
@@ -722,3 +754,87 @@ func TestSnapshotConsistentAfterSetRefOnTrackedRef(t *testing.T) {
 		t.Fatalf("Commit after SetRef on tracked ref: %v", err)
 	}
 }
+
+func TestOpenAtReadsSnapshot(t *testing.T) {
+	dir := initTestRepo(t)
+	tfs, err := Open(dir, "refs/heads/beadwork")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	tfs.WriteFile("file.txt", []byte("v1"))
+	if err := tfs.Commit("v1"); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+	v1 := tfs.RefHash()
+	tfs.WriteFile("file.txt", []byte("v2"))
+	tfs.WriteFile("later.txt", []byte("x"))
+	if err := tfs.Commit("v2"); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+
+	snap, err := OpenAt(tfs.Repo(), v1)
+	if err != nil {
+		t.Fatalf("OpenAt: %v", err)
+	}
+	data, err := snap.ReadFile("file.txt")
+	if err != nil || string(data) != "v1" {
+		t.Errorf("ReadFile = %q, %v; want v1", data, err)
+	}
+	if _, err := snap.ReadFile("later.txt"); err == nil {
+		t.Error("later.txt should not exist in snapshot")
+	}
+
+	// Snapshots never follow the ref and refuse to commit.
+	if err := snap.Refresh(); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if data, _ := snap.ReadFile("file.txt"); string(data) != "v1" {
+		t.Errorf("after Refresh ReadFile = %q, want v1", data)
+	}
+	snap.WriteFile("file.txt", []byte("v3"))
+	if err := snap.Commit("v3"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Commit err = %v, want ErrReadOnly", err)
+	}
+	if data, _ := tfs.ReadFile("file.txt"); string(data) != "v2" {
+		t.Errorf("live ReadFile = %q, want v2", data)
+	}
+}
+
+func TestTreeHashDirtyAndChangedPaths(t *testing.T) {
+	dir := initTestRepo(t)
+	tfs, err := Open(dir, "refs/heads/beadwork")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	before := tfs.TreeHash()
+	if before.IsZero() || tfs.Dirty() {
+		t.Fatalf("TreeHash = %s, Dirty = %v", before, tfs.Dirty())
+	}
+
+	tfs.WriteFile("issues/test-5678.json", []byte(`{"id":"test-5678"}`))
+	tfs.Remove("status/open/test-1234")
+	if !tfs.Dirty() {
+		t.Error("Dirty should be true with pending writes")
+	}
+	if err := tfs.Commit("change"); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+	if tfs.Dirty() || tfs.TreeHash() == before {
+		t.Errorf("after commit: Dirty = %v, TreeHash unchanged = %v", tfs.Dirty(), tfs.TreeHash() == before)
+	}
+
+	paths, err := tfs.ChangedPaths(before)
+	if err != nil {
+		t.Fatalf("ChangedPaths: %v", err)
+	}
+	sort.Strings(paths)
+	want := []string{"issues/test-5678.json", "status/open/test-1234"}
+	if len(paths) != 2 || paths[0] != want[0] || paths[1] != want[1] {
+		t.Errorf("ChangedPaths = %v, want %v", paths, want)
+	}
+
+	if _, err := tfs.ChangedPaths(plumbing.NewHash("1111111111111111111111111111111111111111")); err == nil {
+		t.Error("ChangedPaths from a missing tree should fail")
+	}
+}